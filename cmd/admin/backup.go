@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+	"github.com/ashtonholgate/url-minifier/services/shortener/repository"
+)
+
+// backedUpCollections are streamed in order; urls last so its consistency
+// marker covers the collections it references.
+var backedUpCollections = []string{"domains", "bundles", "campaigns", "workspaces", "urls"}
+
+// backupLine is one NDJSON record. Marker lines carry metadata instead of
+// a document: "begin" with the start timestamp, per-collection "count"
+// lines, and a final "end" marker whose presence proves the stream is
+// complete — a truncated upload is detectable before any restore starts.
+type backupLine struct {
+	Marker     string          `json:"marker,omitempty"`
+	Collection string          `json:"collection,omitempty"`
+	Count      int64           `json:"count,omitempty"`
+	At         *time.Time      `json:"at,omitempty"`
+	Doc        json.RawMessage `json:"doc,omitempty"`
+}
+
+// runBackup streams the shortener collections to a gzip-compressed NDJSON
+// file (typically on a mounted object-storage path).
+func runBackup(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	out := fs.String("out", "", "destination file (e.g. /mnt/backups/urls.ndjson.gz)")
+	fs.Parse(args)
+	if *out == "" {
+		return fmt.Errorf("-out is required")
+	}
+
+	client, err := connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Disconnect(context.Background())
+	db := client.Database("shortener")
+
+	f, err := os.Create(*out)
+	if err != nil {
+		return fmt.Errorf("creating backup file: %w", err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	defer gz.Close()
+	w := bufio.NewWriter(gz)
+	defer w.Flush()
+	enc := json.NewEncoder(w)
+
+	now := time.Now().UTC()
+	if err := enc.Encode(backupLine{Marker: "begin", At: &now}); err != nil {
+		return err
+	}
+	for _, name := range backedUpCollections {
+		count, err := dumpCollection(ctx, db.Collection(name), enc)
+		if err != nil {
+			return fmt.Errorf("backing up %s: %w", name, err)
+		}
+		if err := enc.Encode(backupLine{Marker: "count", Collection: name, Count: count}); err != nil {
+			return err
+		}
+		log.Printf("backed up %s: %d documents", name, count)
+	}
+	end := time.Now().UTC()
+	if err := enc.Encode(backupLine{Marker: "end", At: &end}); err != nil {
+		return err
+	}
+	log.Printf("backup complete: %s", *out)
+	return nil
+}
+
+func dumpCollection(ctx context.Context, coll *mongo.Collection, enc *json.Encoder) (int64, error) {
+	cur, err := coll.Find(ctx, bson.M{})
+	if err != nil {
+		return 0, err
+	}
+	defer cur.Close(ctx)
+	var count int64
+	for cur.Next(ctx) {
+		raw, err := bson.MarshalExtJSON(cur.Current, true, false)
+		if err != nil {
+			return count, err
+		}
+		if err := enc.Encode(backupLine{Collection: coll.Name(), Doc: raw}); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, cur.Err()
+}
+
+// runRestore replays a backup stream, verifies the consistency markers and
+// per-collection counts, rebuilds indexes and warms the Redis cache for
+// the most recently created links.
+func runRestore(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	in := fs.String("in", "", "backup file to restore")
+	warm := fs.Int("warm", 1000, "number of newest links to pre-load into the cache")
+	fs.Parse(args)
+	if *in == "" {
+		return fmt.Errorf("-in is required")
+	}
+
+	f, err := os.Open(*in)
+	if err != nil {
+		return fmt.Errorf("opening backup: %w", err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("reading backup: %w", err)
+	}
+	defer gz.Close()
+
+	client, err := connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Disconnect(context.Background())
+	db := client.Database("shortener")
+
+	inserted := map[string]int64{}
+	counts := map[string]int64{}
+	sawBegin, sawEnd := false, false
+	dec := json.NewDecoder(bufio.NewReader(gz))
+	for dec.More() {
+		var line backupLine
+		if err := dec.Decode(&line); err != nil {
+			return fmt.Errorf("decoding backup line: %w", err)
+		}
+		switch line.Marker {
+		case "begin":
+			sawBegin = true
+			continue
+		case "count":
+			counts[line.Collection] = line.Count
+			continue
+		case "end":
+			sawEnd = true
+			continue
+		}
+		var doc bson.D
+		if err := bson.UnmarshalExtJSON(line.Doc, true, &doc); err != nil {
+			return fmt.Errorf("decoding %s document: %w", line.Collection, err)
+		}
+		if _, err := db.Collection(line.Collection).InsertOne(ctx, doc); err != nil {
+			return fmt.Errorf("restoring %s document: %w", line.Collection, err)
+		}
+		inserted[line.Collection]++
+	}
+	if !sawBegin || !sawEnd {
+		return fmt.Errorf("backup stream is truncated (begin=%v end=%v); refusing partial restore state", sawBegin, sawEnd)
+	}
+	for name, want := range counts {
+		if inserted[name] != want {
+			return fmt.Errorf("collection %s: restored %d of %d documents", name, inserted[name], want)
+		}
+		log.Printf("restored %s: %d documents", name, inserted[name])
+	}
+
+	if err := ensureIndexes(ctx, client); err != nil {
+		return err
+	}
+	return warmCache(ctx, client, int64(*warm))
+}
+
+// warmCache pre-loads the newest links into Redis so the first minutes
+// after a restore don't hammer MongoDB.
+func warmCache(ctx context.Context, client *mongo.Client, limit int64) error {
+	rdb := redisClient()
+	defer rdb.Close()
+	repo := repository.NewCachedRepository(
+		repository.NewReadOnlyMongoRepository(client.Database("shortener")), rdb)
+	cur, err := client.Database("shortener").Collection("urls").Find(ctx, bson.M{},
+		options.Find().
+			SetSort(bson.D{{Key: "created_at", Value: -1}}).
+			SetLimit(limit).
+			SetProjection(bson.M{"code": 1}))
+	if err != nil {
+		return fmt.Errorf("scanning urls for warmup: %w", err)
+	}
+	defer cur.Close(ctx)
+	var warmed int64
+	for cur.Next(ctx) {
+		var u models.URL
+		if err := cur.Decode(&u); err != nil {
+			continue
+		}
+		// The read-through populates the cache as a side effect.
+		if _, err := repo.GetURLByCode(ctx, u.Code); err == nil {
+			warmed++
+		}
+	}
+	log.Printf("cache warmed with %d links", warmed)
+	return cur.Err()
+}
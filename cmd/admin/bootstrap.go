@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+
+	"github.com/ashtonholgate/url-minifier/pkg/auth"
+	"github.com/ashtonholgate/url-minifier/services/shortener/repository"
+)
+
+// bootstrapConfig is the YAML schema consumed by `admin bootstrap`. Secrets
+// are expected to be injected by the provisioning tool, not committed.
+type bootstrapConfig struct {
+	Superadmin struct {
+		Email    string `yaml:"email"`
+		Password string `yaml:"password"`
+	} `yaml:"superadmin"`
+	APIKey struct {
+		KeyID  string `yaml:"key_id"`
+		Secret string `yaml:"secret"`
+	} `yaml:"api_key"`
+	Domains []string `yaml:"domains"`
+}
+
+// runBootstrap provisions a fresh (or partially provisioned) environment:
+// indexes, the superadmin user, its initial API key and the default serving
+// domains. Every step is an upsert, so reruns converge without clobbering
+// later manual changes such as a rotated password.
+func runBootstrap(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bootstrap", flag.ExitOnError)
+	configPath := fs.String("config", "bootstrap.yaml", "path to the bootstrap YAML file")
+	fs.Parse(args)
+
+	raw, err := os.ReadFile(*configPath)
+	if err != nil {
+		return fmt.Errorf("reading config: %w", err)
+	}
+	var cfg bootstrapConfig
+	if err := yaml.Unmarshal(raw, &cfg); err != nil {
+		return fmt.Errorf("parsing config: %w", err)
+	}
+	if cfg.Superadmin.Email == "" || cfg.Superadmin.Password == "" {
+		return fmt.Errorf("superadmin email and password are required")
+	}
+
+	client, err := connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Disconnect(context.Background())
+
+	if err := ensureIndexes(ctx, client); err != nil {
+		return err
+	}
+	userID, err := seedSuperadmin(ctx, client.Database("auth"), cfg)
+	if err != nil {
+		return err
+	}
+	if cfg.APIKey.KeyID != "" {
+		if err := seedAPIKey(ctx, client.Database("auth"), cfg, userID); err != nil {
+			return err
+		}
+	}
+	return seedDomains(ctx, client.Database("shortener"), cfg.Domains)
+}
+
+// ensureIndexes creates the unique indexes the services rely on. CreateOne
+// is a no-op when the index already exists with the same definition.
+func ensureIndexes(ctx context.Context, client *mongo.Client) error {
+	unique := func(coll *mongo.Collection, field string) error {
+		_, err := coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys:    bson.D{{Key: field, Value: 1}},
+			Options: options.Index().SetUnique(true),
+		})
+		if err != nil {
+			return fmt.Errorf("index %s.%s: %w", coll.Name(), field, err)
+		}
+		log.Printf("index %s.%s ok", coll.Name(), field)
+		return nil
+	}
+	// The urls collection gets the full query-supporting set the index
+	// advisor checks for, shared with the repository constructor.
+	if err := repository.EnsureURLIndexes(ctx, client.Database("shortener")); err != nil {
+		return err
+	}
+	log.Printf("urls indexes ok")
+	if err := unique(client.Database("shortener").Collection("domains"), "domain"); err != nil {
+		return err
+	}
+	if err := unique(client.Database("auth").Collection("users"), "email"); err != nil {
+		return err
+	}
+	return unique(client.Database("auth").Collection("api_keys"), "key_id")
+}
+
+// seedSuperadmin upserts the superadmin user and returns its ID. The
+// password hash is only written on insert so rotations survive reruns.
+func seedSuperadmin(ctx context.Context, db *mongo.Database, cfg bootstrapConfig) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(cfg.Superadmin.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("hashing password: %w", err)
+	}
+	users := db.Collection("users")
+	res := users.FindOneAndUpdate(ctx,
+		bson.M{"email": cfg.Superadmin.Email},
+		bson.M{
+			"$set": bson.M{"role": string(auth.RoleSuperadmin)},
+			"$setOnInsert": bson.M{
+				"_id":           cfg.Superadmin.Email,
+				"password_hash": string(hash),
+				"created_at":    time.Now().UTC(),
+			},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After))
+	var u struct {
+		ID string `bson:"_id"`
+	}
+	if err := res.Decode(&u); err != nil {
+		return "", fmt.Errorf("seeding superadmin: %w", err)
+	}
+	log.Printf("superadmin %s ok", cfg.Superadmin.Email)
+	return u.ID, nil
+}
+
+// seedAPIKey upserts the initial API key bound to the superadmin.
+func seedAPIKey(ctx context.Context, db *mongo.Database, cfg bootstrapConfig, userID string) error {
+	_, err := db.Collection("api_keys").UpdateOne(ctx,
+		bson.M{"key_id": cfg.APIKey.KeyID},
+		bson.M{"$setOnInsert": bson.M{
+			"secret":     cfg.APIKey.Secret,
+			"user_id":    userID,
+			"role":       string(auth.RoleSuperadmin),
+			"created_at": time.Now().UTC(),
+		}},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("seeding api key: %w", err)
+	}
+	log.Printf("api key %s ok", cfg.APIKey.KeyID)
+	return nil
+}
+
+// seedDomains registers the default serving domains as pre-verified.
+func seedDomains(ctx context.Context, db *mongo.Database, domains []string) error {
+	for _, d := range domains {
+		_, err := db.Collection("domains").UpdateOne(ctx,
+			bson.M{"domain": d},
+			bson.M{"$setOnInsert": bson.M{
+				"verified":   true,
+				"created_at": time.Now().UTC(),
+			}},
+			options.Update().SetUpsert(true))
+		if err != nil {
+			return fmt.Errorf("seeding domain %s: %w", d, err)
+		}
+		log.Printf("domain %s ok", d)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ashtonholgate/url-minifier/pkg/fieldcrypt"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+// runEdgeSnapshot writes a compact, code-sorted TSV snapshot of active
+// code→destination mappings for edge resolvers. Sorted order lets edge
+// workers binary-search the file directly; the companion delta feed
+// (GET /api/v1/admin/edge-delta) keeps a loaded snapshot current.
+func runEdgeSnapshot(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("edge-snapshot", flag.ExitOnError)
+	out := fs.String("out", "edge-snapshot.tsv.gz", "snapshot destination file")
+	fs.Parse(args)
+
+	client, err := connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Disconnect(context.Background())
+	urls := client.Database("shortener").Collection("urls")
+	// Raw collection reads bypass the repository's encryption decorator;
+	// edge workers need plaintext destinations.
+	codec, err := fieldcrypt.FromEnv()
+	if err != nil {
+		return fmt.Errorf("initialising field encryption: %w", err)
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		return fmt.Errorf("creating snapshot file: %w", err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	defer gz.Close()
+	w := bufio.NewWriter(gz)
+	defer w.Flush()
+
+	now := time.Now().UTC()
+	// Header carries the watermark the delta feed resumes from.
+	fmt.Fprintf(w, "# url-minifier edge snapshot %s\n", now.Format(time.RFC3339))
+	cur, err := urls.Find(ctx, bson.M{},
+		options.Find().
+			SetSort(bson.D{{Key: "code", Value: 1}}).
+			SetProjection(bson.M{"code": 1, "long_url": 1, "status": 1, "expires_at": 1}))
+	if err != nil {
+		return fmt.Errorf("scanning urls: %w", err)
+	}
+	defer cur.Close(ctx)
+	var written int64
+	for cur.Next(ctx) {
+		var u models.URL
+		if err := cur.Decode(&u); err != nil {
+			return fmt.Errorf("decoding url: %w", err)
+		}
+		if !u.Active() || u.Expired(now) {
+			continue
+		}
+		dest, err := codec.DecryptIfConfigured(u.LongURL)
+		if err != nil {
+			return fmt.Errorf("decrypting %s: %w", u.Code, err)
+		}
+		fmt.Fprintf(w, "%s\t%s\n", u.Code, dest)
+		written++
+	}
+	if err := cur.Err(); err != nil {
+		return fmt.Errorf("iterating urls: %w", err)
+	}
+	log.Printf("edge-snapshot: wrote %d active mappings to %s", written, *out)
+	return nil
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+	"github.com/ashtonholgate/url-minifier/services/shortener/repository"
+	"github.com/ashtonholgate/url-minifier/services/shortener/service"
+)
+
+// importRecord is one link parsed from an export file.
+type importRecord struct {
+	LongURL string
+	Code    string // custom back-half / keyword, "" to generate
+	Tags    []string
+	Created time.Time
+}
+
+// runImport backfills links from a Bitly or TinyURL CSV export, preserving
+// custom back-halves where the code is still free and reporting every
+// conflict instead of stopping on it.
+func runImport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	file := fs.String("file", "", "path to the export CSV")
+	format := fs.String("format", "bitly", "export format: bitly or tinyurl")
+	owner := fs.String("owner", "", "user ID to own the imported links")
+	org := fs.String("org", "", "org ID for the imported links")
+	reportPath := fs.String("report", "import-report.csv", "where to write the migration report")
+	fs.Parse(args)
+	if *file == "" || *owner == "" {
+		return fmt.Errorf("-file and -owner are required")
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		return fmt.Errorf("opening export: %w", err)
+	}
+	defer f.Close()
+	records, err := parseExport(f, *format)
+	if err != nil {
+		return err
+	}
+
+	client, err := connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Disconnect(context.Background())
+	repo, err := repository.NewMongoRepository(ctx, client.Database("shortener"))
+	if err != nil {
+		return err
+	}
+
+	report, err := os.Create(*reportPath)
+	if err != nil {
+		return fmt.Errorf("creating report: %w", err)
+	}
+	defer report.Close()
+	rw := csv.NewWriter(report)
+	rw.Write([]string{"code", "long_url", "outcome"})
+
+	gen := service.NewRandomGenerator(0)
+	var imported, conflicts, failed int
+	for _, rec := range records {
+		if rec.Code == "" {
+			code, err := gen.Generate(ctx)
+			if err != nil {
+				return fmt.Errorf("generating code: %w", err)
+			}
+			rec.Code = code
+		}
+		u := &models.URL{
+			Code:      rec.Code,
+			LongURL:   rec.LongURL,
+			OwnerID:   *owner,
+			OrgID:     *org,
+			Tags:      rec.Tags,
+			CreatedAt: rec.Created,
+			UpdatedAt: time.Now().UTC(),
+			Status:    models.URLStatusActive,
+		}
+		if u.CreatedAt.IsZero() {
+			u.CreatedAt = time.Now().UTC()
+		}
+		outcome := "imported"
+		switch err := repo.StoreURL(ctx, u); {
+		case err == nil:
+			imported++
+		case errors.Is(err, common.ErrConflict):
+			conflicts++
+			outcome = "conflict: code already taken"
+		default:
+			failed++
+			outcome = "error: " + err.Error()
+		}
+		rw.Write([]string{u.Code, u.LongURL, outcome})
+	}
+	rw.Flush()
+	if err := rw.Error(); err != nil {
+		return fmt.Errorf("writing report: %w", err)
+	}
+	log.Printf("import finished: %d imported, %d conflicts, %d errors (report: %s)",
+		imported, conflicts, failed, *reportPath)
+	return nil
+}
+
+// parseExport reads an export CSV into import records. Bitly exports carry
+// long_url, keyword (custom back-half), tags and created_at columns;
+// TinyURL exports carry long_url and alias. Column order varies by export
+// vintage, so we resolve by header name.
+func parseExport(r io.Reader, format string) ([]importRecord, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	col := map[string]int{}
+	for i, name := range header {
+		col[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	field := func(row []string, names ...string) string {
+		for _, name := range names {
+			if i, ok := col[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+		}
+		return ""
+	}
+
+	var out []importRecord
+	for {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading row: %w", err)
+		}
+		rec := importRecord{}
+		switch format {
+		case "bitly":
+			rec.LongURL = field(row, "long_url", "destination")
+			rec.Code = field(row, "keyword", "custom_bitlink", "back-half")
+			if tags := field(row, "tags"); tags != "" {
+				rec.Tags = strings.Split(tags, "|")
+			}
+			if created := field(row, "created_at", "date"); created != "" {
+				if t, err := time.Parse(time.RFC3339, created); err == nil {
+					rec.Created = t
+				}
+			}
+		case "tinyurl":
+			rec.LongURL = field(row, "long_url", "url")
+			rec.Code = field(row, "alias")
+		default:
+			return nil, fmt.Errorf("unknown format %q", format)
+		}
+		if rec.LongURL == "" {
+			continue
+		}
+		out = append(out, rec)
+	}
+	return out, nil
+}
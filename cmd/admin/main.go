@@ -0,0 +1,76 @@
+// Command admin bundles operational subcommands. It is built for
+// provisioning pipelines (Terraform local-exec, CI jobs), so every
+// subcommand is idempotent and exits non-zero on the first failure.
+//
+// Usage:
+//
+//	admin bootstrap -config bootstrap.yaml
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+)
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		log.Fatalf("usage: %s <subcommand> [flags]\n\nsubcommands:\n  bootstrap  provision indexes, superadmin, API key and domains\n  import     backfill links from a Bitly/TinyURL export\n  backup     stream collections to compressed NDJSON\n  restore    replay a backup, rebuild indexes and warm the cache\n  migrate-store  copy links between repository backends\n  rebuild-search rebuild the urls text index with verification\n  edge-snapshot  export active code mappings for edge resolvers", os.Args[0])
+	}
+	// Subcommands like migrate-store, backup and rebuild-search stream
+	// whole collections under their own rate limits and can legitimately
+	// run for hours, so there is no global deadline: the context only
+	// cancels on interrupt, and each subcommand bounds its individual
+	// operations where that matters.
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	var err error
+	switch os.Args[1] {
+	case "bootstrap":
+		err = runBootstrap(ctx, os.Args[2:])
+	case "import":
+		err = runImport(ctx, os.Args[2:])
+	case "backup":
+		err = runBackup(ctx, os.Args[2:])
+	case "restore":
+		err = runRestore(ctx, os.Args[2:])
+	case "migrate-store":
+		err = runMigrateStore(ctx, os.Args[2:])
+	case "rebuild-search":
+		err = runRebuildSearch(ctx, os.Args[2:])
+	case "edge-snapshot":
+		err = runEdgeSnapshot(ctx, os.Args[2:])
+	default:
+		err = fmt.Errorf("unknown subcommand %q", os.Args[1])
+	}
+	if err != nil {
+		log.Fatalf("admin %s: %v", os.Args[1], err)
+	}
+}
+
+// redisClient dials Redis using the same environment configuration as the
+// services.
+func redisClient() *redis.Client {
+	return redis.NewClient(&redis.Options{Addr: common.Getenv("REDIS_ADDR", "localhost:6379")})
+}
+
+// connect dials MongoDB using the same environment configuration as the
+// services.
+func connect(ctx context.Context) (*mongo.Client, error) {
+	uri := common.Getenv("MONGO_URI", "mongodb://localhost:27017")
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		return nil, fmt.Errorf("connecting to mongo: %w", err)
+	}
+	return client, nil
+}
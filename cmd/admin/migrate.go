@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+	"github.com/ashtonholgate/url-minifier/services/shortener/repository"
+)
+
+// runMigrateStore streams every URL from a source repository backend to a
+// target one, with checkpointing for resume, a rate limit to protect the
+// source, and a sampled verification pass feeding the final consistency
+// report.
+func runMigrateStore(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("migrate-store", flag.ExitOnError)
+	sourceURI := fs.String("source", "", "source MongoDB URI")
+	targetURI := fs.String("target", "", "target MongoDB URI")
+	rate := fs.Int("rate", 500, "maximum documents per second")
+	sample := fs.Int("sample", 100, "codes to verify after migration")
+	checkpointPath := fs.String("checkpoint", "migrate-store.checkpoint", "checkpoint file for resume")
+	fs.Parse(args)
+	if *sourceURI == "" || *targetURI == "" {
+		return fmt.Errorf("-source and -target are required")
+	}
+
+	source, err := mongo.Connect(ctx, options.Client().ApplyURI(*sourceURI))
+	if err != nil {
+		return fmt.Errorf("connecting to source: %w", err)
+	}
+	defer source.Disconnect(context.Background())
+	target, err := mongo.Connect(ctx, options.Client().ApplyURI(*targetURI))
+	if err != nil {
+		return fmt.Errorf("connecting to target: %w", err)
+	}
+	defer target.Disconnect(context.Background())
+
+	targetRepo, err := repository.NewMongoRepository(ctx, target.Database("shortener"))
+	if err != nil {
+		return fmt.Errorf("initialising target repository: %w", err)
+	}
+	urls := source.Database("shortener").Collection("urls")
+
+	// Resume from the checkpoint: codes sort stably, so "everything after
+	// the last migrated code" is a clean restart point.
+	after := ""
+	if raw, err := os.ReadFile(*checkpointPath); err == nil {
+		after = strings.TrimSpace(string(raw))
+		log.Printf("resuming after code %q", after)
+	}
+	filter := bson.M{}
+	if after != "" {
+		filter["code"] = bson.M{"$gt": after}
+	}
+	cur, err := urls.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "code", Value: 1}}))
+	if err != nil {
+		return fmt.Errorf("scanning source: %w", err)
+	}
+	defer cur.Close(ctx)
+
+	limiter := time.NewTicker(time.Second / time.Duration(*rate))
+	defer limiter.Stop()
+	var migrated, skipped, failed int64
+	var codes []string
+	for cur.Next(ctx) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-limiter.C:
+		}
+		var u models.URL
+		if err := cur.Decode(&u); err != nil {
+			return fmt.Errorf("decoding source document: %w", err)
+		}
+		switch err := targetRepo.StoreURL(ctx, &u); {
+		case err == nil:
+			migrated++
+		case errors.Is(err, common.ErrConflict):
+			// Already present from a previous partial run.
+			skipped++
+		default:
+			failed++
+			log.Printf("migrate: storing %s: %v", u.Code, err)
+		}
+		codes = append(codes, u.Code)
+		if (migrated+skipped)%1000 == 0 {
+			if err := os.WriteFile(*checkpointPath, []byte(u.Code), 0o644); err != nil {
+				return fmt.Errorf("writing checkpoint: %w", err)
+			}
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return fmt.Errorf("iterating source: %w", err)
+	}
+
+	mismatches := verifySample(ctx, urls, targetRepo, codes, *sample)
+	log.Printf("migration report: migrated=%d skipped=%d failed=%d verified_sample=%d mismatches=%d",
+		migrated, skipped, failed, min(len(codes), *sample), mismatches)
+	if failed == 0 && mismatches == 0 {
+		os.Remove(*checkpointPath)
+		return nil
+	}
+	return fmt.Errorf("migration finished with %d failures and %d verification mismatches", failed, mismatches)
+}
+
+// verifySample compares a random sample of migrated codes between source
+// and target.
+func verifySample(ctx context.Context, source *mongo.Collection, target repository.URLRepository, codes []string, n int) int {
+	rand.Shuffle(len(codes), func(i, j int) { codes[i], codes[j] = codes[j], codes[i] })
+	if len(codes) > n {
+		codes = codes[:n]
+	}
+	mismatches := 0
+	for _, code := range codes {
+		var want models.URL
+		if err := source.FindOne(ctx, bson.M{"code": code}).Decode(&want); err != nil {
+			log.Printf("verify: reading %s from source: %v", code, err)
+			mismatches++
+			continue
+		}
+		got, err := target.GetURLByCode(ctx, code)
+		if err != nil {
+			log.Printf("verify: reading %s from target: %v", code, err)
+			mismatches++
+			continue
+		}
+		if got.LongURL != want.LongURL || got.OwnerID != want.OwnerID {
+			log.Printf("verify: mismatch on %s", code)
+			mismatches++
+		}
+	}
+	return mismatches
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// searchIndexName is the text index backing destination/tag search.
+const searchIndexName = "urls_search_text"
+
+// runRebuildSearch drops and recreates the urls text index, then runs a
+// throttled verification scan so operators see progress and the rebuild
+// never saturates the primary. Needed after schema changes or index
+// corruption.
+func runRebuildSearch(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("rebuild-search", flag.ExitOnError)
+	rate := fs.Int("rate", 2000, "maximum documents scanned per second during verification")
+	fs.Parse(args)
+
+	client, err := connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Disconnect(context.Background())
+	urls := client.Database("shortener").Collection("urls")
+
+	// Dropping a missing index is fine on first run.
+	if _, err := urls.Indexes().DropOne(ctx, searchIndexName); err != nil {
+		log.Printf("rebuild-search: dropping old index (continuing): %v", err)
+	}
+	log.Printf("rebuild-search: building text index on long_url and tags")
+	start := time.Now()
+	_, err = urls.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "long_url", Value: "text"},
+			{Key: "tags", Value: "text"},
+		},
+		Options: options.Index().SetName(searchIndexName),
+	})
+	if err != nil {
+		return fmt.Errorf("creating text index: %w", err)
+	}
+	log.Printf("rebuild-search: index built in %s", time.Since(start).Round(time.Second))
+
+	return verifyScan(ctx, urls, *rate)
+}
+
+// verifyScan walks every document through the new index's collection at a
+// bounded rate, reporting progress, so silent corruption surfaces here
+// rather than in production queries.
+func verifyScan(ctx context.Context, urls *mongo.Collection, rate int) error {
+	total, err := urls.EstimatedDocumentCount(ctx)
+	if err != nil {
+		return fmt.Errorf("counting documents: %w", err)
+	}
+	cur, err := urls.Find(ctx, bson.M{},
+		options.Find().SetProjection(bson.M{"code": 1}).SetBatchSize(1000))
+	if err != nil {
+		return fmt.Errorf("scanning urls: %w", err)
+	}
+	defer cur.Close(ctx)
+
+	limiter := time.NewTicker(time.Second / time.Duration(rate))
+	defer limiter.Stop()
+	var scanned int64
+	lastReport := time.Now()
+	for cur.Next(ctx) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-limiter.C:
+		}
+		scanned++
+		if time.Since(lastReport) >= 10*time.Second {
+			log.Printf("rebuild-search: verified %d/%d documents (%.1f%%)",
+				scanned, total, 100*float64(scanned)/float64(total))
+			lastReport = time.Now()
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return fmt.Errorf("verification scan: %w", err)
+	}
+	log.Printf("rebuild-search: complete, %d documents verified", scanned)
+	return nil
+}
@@ -0,0 +1,207 @@
+// Command loadgen drives realistic mixed traffic at a url-minifier
+// deployment for capacity planning: authenticated creates, hot and cold
+// redirects following a zipfian popularity curve, and 404 scans. It reports
+// latency percentiles per traffic class on exit.
+//
+// Usage:
+//
+//	loadgen -target http://localhost:8080 -token $JWT -rps 200 -duration 60s
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Traffic mix fractions; the remainder after creates and scans is
+// redirects.
+const (
+	createFraction = 0.05
+	scanFraction   = 0.05
+)
+
+func main() {
+	log.SetFlags(0)
+	target := flag.String("target", "http://localhost:8080", "base URL of the deployment under test")
+	token := flag.String("token", "", "bearer token for create requests")
+	rps := flag.Int("rps", 100, "target requests per second")
+	duration := flag.Duration("duration", time.Minute, "how long to run")
+	keyspace := flag.Int("keyspace", 10000, "number of distinct codes to spread redirects over")
+	skew := flag.Float64("skew", 1.1, "zipf skew; higher concentrates traffic on hot codes")
+	flag.Parse()
+	if *token == "" {
+		log.Fatal("loadgen: -token is required (creates must authenticate)")
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	ctx, timeout := context.WithTimeout(ctx, *duration)
+	defer timeout()
+
+	gen := newGenerator(*target, *token, *keyspace, *skew)
+	gen.run(ctx, *rps)
+	gen.report()
+}
+
+// generator issues requests and collects latencies per traffic class.
+type generator struct {
+	target string
+	token  string
+	client *http.Client
+	zipf   *rand.Zipf
+	codes  []string
+
+	mu        sync.Mutex
+	latencies map[string][]time.Duration
+	errors    map[string]int
+}
+
+func newGenerator(target, token string, keyspace int, skew float64) *generator {
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	codes := make([]string, keyspace)
+	for i := range codes {
+		codes[i] = fmt.Sprintf("load%06d", i)
+	}
+	return &generator{
+		target: target,
+		token:  token,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+			// Measure the redirect response itself, not the destination.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		zipf:      rand.NewZipf(rng, skew, 1, uint64(keyspace-1)),
+		codes:     codes,
+		latencies: map[string][]time.Duration{},
+		errors:    map[string]int{},
+	}
+}
+
+// run paces requests at rps until ctx is done.
+func (g *generator) run(ctx context.Context, rps int) {
+	ticker := time.NewTicker(time.Second / time.Duration(rps))
+	defer ticker.Stop()
+	var wg sync.WaitGroup
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case <-ticker.C:
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				g.fire(ctx)
+			}()
+		}
+	}
+}
+
+// fire issues one request according to the traffic mix.
+func (g *generator) fire(ctx context.Context) {
+	r := rand.Float64()
+	switch {
+	case r < createFraction:
+		g.create(ctx)
+	case r < createFraction+scanFraction:
+		g.scan(ctx)
+	default:
+		g.redirect(ctx)
+	}
+}
+
+func (g *generator) observe(class string, start time.Time, err error, status int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err != nil || status >= 500 {
+		g.errors[class]++
+		return
+	}
+	g.latencies[class] = append(g.latencies[class], time.Since(start))
+}
+
+func (g *generator) create(ctx context.Context) {
+	body, _ := json.Marshal(map[string]string{
+		"long_url": fmt.Sprintf("https://example.com/page/%d", rand.Int63()),
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		g.target+"/api/v1/urls", bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	req.Header.Set("Authorization", "Bearer "+g.token)
+	req.Header.Set("Content-Type", "application/json")
+	start := time.Now()
+	resp, err := g.client.Do(req)
+	status := 0
+	if err == nil {
+		status = resp.StatusCode
+		resp.Body.Close()
+	}
+	g.observe("create", start, err, status)
+}
+
+// redirect follows the zipfian popularity curve over the keyspace, so a
+// handful of codes stay hot in cache while the long tail stays cold.
+func (g *generator) redirect(ctx context.Context) {
+	code := g.codes[g.zipf.Uint64()]
+	g.get(ctx, "redirect", "/"+code)
+}
+
+// scan probes random nonexistent codes, exercising the 404 and
+// enumeration-detection paths.
+func (g *generator) scan(ctx context.Context) {
+	g.get(ctx, "scan", fmt.Sprintf("/miss%010d", rand.Int63n(1e10)))
+}
+
+func (g *generator) get(ctx context.Context, class, path string) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.target+path, nil)
+	if err != nil {
+		return
+	}
+	start := time.Now()
+	resp, err := g.client.Do(req)
+	status := 0
+	if err == nil {
+		status = resp.StatusCode
+		resp.Body.Close()
+	}
+	g.observe(class, start, err, status)
+}
+
+// report prints latency percentiles and error counts per traffic class.
+func (g *generator) report() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	classes := make([]string, 0, len(g.latencies))
+	for class := range g.latencies {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	for _, class := range classes {
+		samples := g.latencies[class]
+		sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+		pct := func(p float64) time.Duration {
+			if len(samples) == 0 {
+				return 0
+			}
+			idx := int(p * float64(len(samples)-1))
+			return samples[idx]
+		}
+		log.Printf("%-10s n=%-7d p50=%-10s p95=%-10s p99=%-10s errors=%d",
+			class, len(samples), pct(0.50), pct(0.95), pct(0.99), g.errors[class])
+	}
+}
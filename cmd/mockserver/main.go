@@ -0,0 +1,142 @@
+// Command mockserver implements the link API on the in-memory store for
+// client development: no Mongo, Redis or auth service required. Data is
+// deterministic (seeded codes url0001…url0050) and faults can be injected
+// per request with query parameters:
+//
+//	?mock_status=503   respond with that status instead
+//	?mock_delay=300ms  sleep before responding
+//
+// Any bearer token is accepted; the subject becomes the owner of created
+// links but ownership is not enforced.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/shortener"
+)
+
+func main() {
+	listenAddr := common.Getenv("LISTEN_ADDR", ":8099")
+	store := shortener.NewMemoryStore()
+	s := shortener.New(store)
+	seed(s)
+
+	root := mux.NewRouter()
+	root.Use(faults)
+	root.HandleFunc("/api/v1/urls", createURL(s)).Methods(http.MethodPost)
+	root.HandleFunc("/api/v1/urls/{code}", getURL(s)).Methods(http.MethodGet)
+	root.HandleFunc("/api/v1/urls/{code}", deleteURL(s)).Methods(http.MethodDelete)
+	root.HandleFunc("/{code}", redirect(s)).Methods(http.MethodGet)
+
+	log.Printf("mockserver listening on %s (deterministic data, no auth)", listenAddr)
+	log.Fatal(http.ListenAndServe(listenAddr, root))
+}
+
+// seed creates fifty deterministic links so UIs have data on first load.
+func seed(s *shortener.Shortener) {
+	for i := 1; i <= 50; i++ {
+		code := fmt.Sprintf("url%04d", i)
+		dest := fmt.Sprintf("https://example.com/articles/%d", i)
+		if _, err := s.ShortenAs(context.Background(), code, dest); err != nil {
+			log.Fatalf("seeding %s: %v", code, err)
+		}
+	}
+}
+
+// faults applies the mock_status and mock_delay query parameters before
+// the real handler runs.
+func faults(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if raw := r.URL.Query().Get("mock_delay"); raw != "" {
+			if d, err := time.ParseDuration(raw); err == nil && d <= 30*time.Second {
+				time.Sleep(d)
+			}
+		}
+		if raw := r.URL.Query().Get("mock_status"); raw != "" {
+			if status, err := strconv.Atoi(raw); err == nil && status >= 400 && status < 600 {
+				if status == http.StatusTooManyRequests {
+					w.Header().Set("Retry-After", "1")
+				}
+				http.Error(w, fmt.Sprintf("injected %d", status), status)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func createURL(s *shortener.Shortener) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			LongURL string `json:"long_url"`
+			Alias   string `json:"alias,omitempty"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "malformed body"})
+			return
+		}
+		if req.Alias != "" {
+			u, err := s.ShortenAs(r.Context(), req.Alias, req.LongURL)
+			if err != nil {
+				writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+				return
+			}
+			writeJSON(w, http.StatusCreated, u)
+			return
+		}
+		u, err := s.Shorten(r.Context(), req.LongURL)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusCreated, u)
+	}
+}
+
+func getURL(s *shortener.Shortener) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u, err := s.Resolve(r.Context(), mux.Vars(r)["code"])
+		if err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+			return
+		}
+		writeJSON(w, http.StatusOK, u)
+	}
+}
+
+func deleteURL(s *shortener.Shortener) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := s.Delete(r.Context(), mux.Vars(r)["code"]); err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func redirect(s *shortener.Shortener) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u, err := s.Resolve(r.Context(), mux.Vars(r)["code"])
+		if err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+			return
+		}
+		http.Redirect(w, r, u.LongURL, http.StatusMovedPermanently)
+	}
+}
@@ -0,0 +1,32 @@
+// Command embed demonstrates the golden path for embedding the shortener
+// in another Go program: an in-memory store, a generated code and a custom
+// alias, all without any running services.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/ashtonholgate/url-minifier/pkg/shortener"
+)
+
+func main() {
+	ctx := context.Background()
+	s := shortener.New(shortener.NewMemoryStore(), shortener.WithCodeLength(6))
+
+	u, err := s.Shorten(ctx, "https://example.com/some/very/long/path")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("generated: %s -> %s\n", u.Code, u.LongURL)
+
+	if _, err := s.ShortenAs(ctx, "docs", "https://example.com/documentation"); err != nil {
+		log.Fatal(err)
+	}
+	resolved, err := s.Resolve(ctx, "docs")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("alias:     docs -> %s\n", resolved.LongURL)
+}
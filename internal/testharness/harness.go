@@ -0,0 +1,102 @@
+// Package testharness spins up the service dependencies — MongoDB and
+// Redis — in throwaway Docker containers for integration and end-to-end
+// tests. It replaces the old INTEGRATION_TEST env-skip pattern: tests call
+// StartMongo/StartRedis in TestMain and run anywhere Docker runs, with no
+// pre-provisioned local services.
+//
+// Typical use:
+//
+//	func TestMain(m *testing.M) {
+//		mongo, err := testharness.StartMongo()
+//		if err != nil { log.Fatal(err) }
+//		defer mongo.Close()
+//		os.Setenv("MONGO_URI", mongo.URI)
+//		os.Exit(m.Run())
+//	}
+package testharness
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// containerExpiry hard-kills leaked containers even when a test binary is
+// SIGKILLed before cleanup runs.
+const containerExpiry = 600 // seconds
+
+// Dependency is one running container with its connection string.
+type Dependency struct {
+	URI   string
+	purge func() error
+}
+
+// Close tears the container down.
+func (d *Dependency) Close() error {
+	if d.purge == nil {
+		return nil
+	}
+	return d.purge()
+}
+
+// StartMongo launches a MongoDB container and blocks until it accepts
+// connections.
+func StartMongo() (*Dependency, error) {
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		return nil, fmt.Errorf("connecting to docker: %w", err)
+	}
+	resource, err := pool.Run("mongo", "7", nil)
+	if err != nil {
+		return nil, fmt.Errorf("starting mongo container: %w", err)
+	}
+	resource.Expire(containerExpiry)
+	uri := fmt.Sprintf("mongodb://localhost:%s", resource.GetPort("27017/tcp"))
+	err = pool.Retry(func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+		if err != nil {
+			return err
+		}
+		defer client.Disconnect(ctx)
+		return client.Ping(ctx, nil)
+	})
+	if err != nil {
+		pool.Purge(resource)
+		return nil, fmt.Errorf("waiting for mongo: %w", err)
+	}
+	return &Dependency{URI: uri, purge: func() error { return pool.Purge(resource) }}, nil
+}
+
+// StartRedis launches a Redis container and blocks until it accepts
+// connections.
+func StartRedis() (*Dependency, error) {
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		return nil, fmt.Errorf("connecting to docker: %w", err)
+	}
+	resource, err := pool.Run("redis", "7", nil)
+	if err != nil {
+		return nil, fmt.Errorf("starting redis container: %w", err)
+	}
+	resource.Expire(containerExpiry)
+	addr := "localhost:" + resource.GetPort("6379/tcp")
+	err = pool.Retry(func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		rdb := redis.NewClient(&redis.Options{Addr: addr})
+		defer rdb.Close()
+		return rdb.Ping(ctx).Err()
+	})
+	if err != nil {
+		pool.Purge(resource)
+		return nil, fmt.Errorf("waiting for redis: %w", err)
+	}
+	return &Dependency{URI: addr, purge: func() error { return pool.Purge(resource) }}, nil
+}
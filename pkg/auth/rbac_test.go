@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRoleCan(t *testing.T) {
+	if RoleViewer.Can(PermURLUpdate) {
+		t.Error("viewer can update")
+	}
+	if !RoleEditor.Can(PermURLCreate) {
+		t.Error("editor cannot create")
+	}
+	if RoleEditor.Can(PermURLDelete) {
+		t.Error("editor can delete")
+	}
+	if !RoleSuperadmin.Can(PermAdmin) {
+		t.Error("superadmin missing admin")
+	}
+}
+
+func TestAuthorizeTenancy(t *testing.T) {
+	editorA := Principal{UserID: "alice", OrgID: "orgA", Role: RoleEditor}
+	tests := []struct {
+		name string
+		pr   Principal
+		perm Permission
+		res  Resource
+		want bool
+	}{
+		{
+			name: "editor cannot touch another org's link",
+			pr:   editorA,
+			perm: PermURLUpdate,
+			res:  Resource{OwnerID: "mallory", OrgID: "orgB"},
+			want: false,
+		},
+		{
+			name: "viewer cannot read another org's link",
+			pr:   Principal{UserID: "v", OrgID: "orgA", Role: RoleViewer},
+			perm: PermURLRead,
+			res:  Resource{OwnerID: "mallory", OrgID: "orgB"},
+			want: false,
+		},
+		{
+			name: "editor updates a link in their own org",
+			pr:   editorA,
+			perm: PermURLUpdate,
+			res:  Resource{OwnerID: "bob", OrgID: "orgA"},
+			want: true,
+		},
+		{
+			name: "editor cannot reach an org-less link they don't own",
+			pr:   editorA,
+			perm: PermURLRead,
+			res:  Resource{OwnerID: "someone-else"},
+			want: false,
+		},
+		{
+			name: "unscoped operations fall back to the role",
+			pr:   editorA,
+			perm: PermURLCreate,
+			res:  Resource{},
+			want: true,
+		},
+		{
+			name: "org-admin cannot administer another org",
+			pr:   Principal{UserID: "adm", OrgID: "orgA", Role: RoleOrgAdmin},
+			perm: PermAdmin,
+			res:  Resource{OrgID: "orgB"},
+			want: false,
+		},
+		{
+			name: "org-admin administers their own org",
+			pr:   Principal{UserID: "adm", OrgID: "orgA", Role: RoleOrgAdmin},
+			perm: PermAdmin,
+			res:  Resource{OrgID: "orgA"},
+			want: true,
+		},
+		{
+			name: "superadmin crosses tenants",
+			pr:   Principal{UserID: "root", Role: RoleSuperadmin},
+			perm: PermURLDelete,
+			res:  Resource{OwnerID: "anyone", OrgID: "orgB"},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Authorize(tt.pr, tt.perm, tt.res)
+			if tt.want && err != nil {
+				t.Errorf("Authorize: unexpected denial: %v", err)
+			}
+			if !tt.want && !errors.Is(err, ErrForbidden) {
+				t.Errorf("Authorize: want ErrForbidden, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAuthorizeOwnershipEscalation(t *testing.T) {
+	// A viewer gains owner permissions on resources they own...
+	viewer := Principal{UserID: "v", OrgID: "orgA", Role: RoleViewer}
+	own := Resource{OwnerID: "v", OrgID: "orgA"}
+	if err := Authorize(viewer, PermURLDelete, own); err != nil {
+		t.Errorf("owner escalation on own resource: %v", err)
+	}
+	// ...but never on someone else's, even inside their org.
+	other := Resource{OwnerID: "bob", OrgID: "orgA"}
+	if err := Authorize(viewer, PermURLDelete, other); !errors.Is(err, ErrForbidden) {
+		t.Errorf("viewer deleted a peer's link: %v", err)
+	}
+}
+
+func TestAuthorizeScopes(t *testing.T) {
+	pr := Principal{
+		UserID: "ci", OrgID: "orgA", Role: RoleEditor,
+		Scopes: []Permission{PermURLCreate},
+	}
+	if err := Authorize(pr, PermURLCreate, Resource{}); err != nil {
+		t.Errorf("in-scope permission denied: %v", err)
+	}
+	if err := Authorize(pr, PermURLRead, Resource{OwnerID: "ci"}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("out-of-scope permission allowed: %v", err)
+	}
+}
+
+func TestDomainAndCampaignScopes(t *testing.T) {
+	pr := Principal{DomainScope: []string{"partner.example.link"}, CampaignScope: []string{"cmp1"}}
+	if !pr.DomainAllowed("partner.example.link") || pr.DomainAllowed("other.example") {
+		t.Error("domain scope misapplied")
+	}
+	if !pr.CampaignAllowed("cmp1") || pr.CampaignAllowed("cmp2") {
+		t.Error("campaign scope misapplied")
+	}
+	open := Principal{}
+	if !open.DomainAllowed("anything") || !open.CampaignAllowed("anything") {
+		t.Error("empty scopes must allow everything")
+	}
+}
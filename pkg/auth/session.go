@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrSessionRevoked is returned when a token references a session that no
+// longer exists, either because it expired or because it was revoked.
+var ErrSessionRevoked = errors.New("auth: session revoked")
+
+// SessionTTL is how long a session stays valid without a refresh.
+const SessionTTL = 24 * time.Hour
+
+// SessionStore tracks active sessions in Redis. A token is only valid while
+// its session key exists, so deleting the key kills the token immediately —
+// no token blacklist needed.
+type SessionStore struct {
+	rdb *redis.Client
+}
+
+// NewSessionStore returns a SessionStore backed by rdb.
+func NewSessionStore(rdb *redis.Client) *SessionStore {
+	return &SessionStore{rdb: rdb}
+}
+
+func sessionKey(id string) string       { return "session:" + id }
+func userSessionsKey(uid string) string { return "user_sessions:" + uid }
+
+// Issue creates a new session for userID and returns its ID.
+func (s *SessionStore) Issue(ctx context.Context, userID string) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generating session id: %w", err)
+	}
+	id := hex.EncodeToString(buf)
+	pipe := s.rdb.TxPipeline()
+	pipe.Set(ctx, sessionKey(id), userID, SessionTTL)
+	pipe.SAdd(ctx, userSessionsKey(userID), id)
+	pipe.Expire(ctx, userSessionsKey(userID), SessionTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", fmt.Errorf("storing session: %w", err)
+	}
+	return id, nil
+}
+
+// Refresh extends the session's lifetime by SessionTTL. It fails with
+// ErrSessionRevoked when the session no longer exists.
+func (s *SessionStore) Refresh(ctx context.Context, id string) error {
+	ok, err := s.rdb.Expire(ctx, sessionKey(id), SessionTTL).Result()
+	if err != nil {
+		return fmt.Errorf("refreshing session: %w", err)
+	}
+	if !ok {
+		return ErrSessionRevoked
+	}
+	return nil
+}
+
+// Revoke deletes a single session, invalidating every token bound to it.
+func (s *SessionStore) Revoke(ctx context.Context, userID, id string) error {
+	pipe := s.rdb.TxPipeline()
+	pipe.Del(ctx, sessionKey(id))
+	pipe.SRem(ctx, userSessionsKey(userID), id)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("revoking session: %w", err)
+	}
+	return nil
+}
+
+// RevokeAll implements "log out everywhere": it deletes every session the
+// user holds.
+func (s *SessionStore) RevokeAll(ctx context.Context, userID string) error {
+	ids, err := s.rdb.SMembers(ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return fmt.Errorf("listing sessions: %w", err)
+	}
+	pipe := s.rdb.TxPipeline()
+	for _, id := range ids {
+		pipe.Del(ctx, sessionKey(id))
+	}
+	pipe.Del(ctx, userSessionsKey(userID))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("revoking sessions: %w", err)
+	}
+	return nil
+}
+
+// Check reports whether the session is still live. It satisfies
+// middleware.RevocationChecker.
+func (s *SessionStore) Check(ctx context.Context, id string) error {
+	if id == "" {
+		return ErrSessionRevoked
+	}
+	_, err := s.rdb.Get(ctx, sessionKey(id)).Result()
+	if errors.Is(err, redis.Nil) {
+		return ErrSessionRevoked
+	}
+	if err != nil {
+		return fmt.Errorf("checking session: %w", err)
+	}
+	return nil
+}
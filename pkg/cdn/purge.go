@@ -0,0 +1,108 @@
+// Package cdn purges edge-cached URLs when links mutate, so aggressive
+// edge caching never serves a stale destination for long. One Purger
+// interface, Cloudflare and Fastly implementations, and a noop for
+// deployments without a CDN.
+package cdn
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Purger invalidates edge caches for the given absolute URLs.
+type Purger interface {
+	Purge(ctx context.Context, urls []string) error
+}
+
+// purgeBatchSize bounds one provider API call; bulk operations chunk
+// through it.
+const purgeBatchSize = 30
+
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+// NoopPurger is used when no CDN fronts the deployment.
+type NoopPurger struct{}
+
+// Purge implements Purger.
+func (NoopPurger) Purge(context.Context, []string) error { return nil }
+
+// CloudflarePurger purges through the zone purge_cache API.
+type CloudflarePurger struct {
+	ZoneID   string
+	APIToken string
+}
+
+// Purge implements Purger.
+func (c CloudflarePurger) Purge(ctx context.Context, urls []string) error {
+	for start := 0; start < len(urls); start += purgeBatchSize {
+		end := start + purgeBatchSize
+		if end > len(urls) {
+			end = len(urls)
+		}
+		body, err := json.Marshal(map[string][]string{"files": urls[start:end]})
+		if err != nil {
+			return fmt.Errorf("cdn: encoding purge: %w", err)
+		}
+		endpoint := fmt.Sprintf("https://api.cloudflare.com/client/v4/zones/%s/purge_cache", c.ZoneID)
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("cdn: building purge request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+c.APIToken)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("cdn: cloudflare purge: %w", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("cdn: cloudflare purge returned %d", resp.StatusCode)
+		}
+	}
+	return nil
+}
+
+// FastlyPurger purges individual URLs through Fastly's purge-by-URL API.
+type FastlyPurger struct {
+	APIToken string
+}
+
+// Purge implements Purger.
+func (f FastlyPurger) Purge(ctx context.Context, urls []string) error {
+	for _, raw := range urls {
+		req, err := http.NewRequestWithContext(ctx, "PURGE", raw, nil)
+		if err != nil {
+			return fmt.Errorf("cdn: building purge request: %w", err)
+		}
+		req.Header.Set("Fastly-Key", f.APIToken)
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("cdn: fastly purge: %w", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("cdn: fastly purge of %s returned %d", raw, resp.StatusCode)
+		}
+	}
+	return nil
+}
+
+// AsyncPurge runs a purge in the background with its own timeout; link
+// mutations never block on the CDN.
+func AsyncPurge(p Purger, urls []string) {
+	if p == nil || len(urls) == 0 {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		if err := p.Purge(ctx, urls); err != nil {
+			log.Printf("cdn: purge failed: %v", err)
+		}
+	}()
+}
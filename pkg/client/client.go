@@ -0,0 +1,234 @@
+// Package client is the first-party Go client for the url-minifier HTTP
+// API: typed methods, context support, automatic retry on 429/5xx
+// honouring Retry-After, and iterators for paginated lists. Internal Go
+// services use this instead of hand-rolling HTTP calls.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+// maxRetries bounds retry attempts per request.
+const maxRetries = 3
+
+// Client talks to one url-minifier deployment.
+type Client struct {
+	baseURL string
+	token   string
+	httpc   *http.Client
+}
+
+// Option customises a Client.
+type Option func(*Client)
+
+// WithHTTPClient replaces the underlying HTTP client.
+func WithHTTPClient(httpc *http.Client) Option {
+	return func(c *Client) { c.httpc = httpc }
+}
+
+// New returns a Client for baseURL authenticating with the bearer token.
+func New(baseURL, token string, opts ...Option) *Client {
+	c := &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		httpc:   &http.Client{Timeout: 30 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// APIError is a non-2xx response from the server.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("client: server returned %d: %s", e.StatusCode, e.Message)
+}
+
+// retryable reports whether the status merits a retry.
+func retryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// retryDelay honours Retry-After when present, falling back to capped
+// exponential backoff.
+func retryDelay(resp *http.Response, attempt int) time.Duration {
+	if resp != nil {
+		if ra := resp.Header.Get("Retry-After"); ra != "" {
+			if secs, err := strconv.Atoi(ra); err == nil && secs > 0 {
+				return time.Duration(secs) * time.Second
+			}
+		}
+	}
+	return time.Duration(math.Pow(2, float64(attempt))) * 250 * time.Millisecond
+}
+
+// do issues a request with retries and decodes the response into out (when
+// non-nil).
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("client: encoding request: %w", err)
+		}
+	}
+	var lastErr error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(payload))
+		if err != nil {
+			return fmt.Errorf("client: building request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+c.token)
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		resp, err := c.httpc.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("client: %w", err)
+		} else {
+			if !retryable(resp.StatusCode) {
+				defer resp.Body.Close()
+				return decode(resp, out)
+			}
+			raw, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+			lastErr = &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(raw))}
+		}
+		if attempt == maxRetries {
+			break
+		}
+		var delayResp *http.Response
+		if apiErr, ok := lastErr.(*APIError); ok && resp != nil && apiErr.StatusCode == resp.StatusCode {
+			delayResp = resp
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retryDelay(delayResp, attempt)):
+		}
+	}
+	return lastErr
+}
+
+func decode(resp *http.Response, out interface{}) error {
+	if resp.StatusCode >= 400 {
+		var payload struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&payload)
+		return &APIError{StatusCode: resp.StatusCode, Message: payload.Error}
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("client: decoding response: %w", err)
+	}
+	return nil
+}
+
+// CreateURL shortens longURL.
+func (c *Client) CreateURL(ctx context.Context, req CreateURLRequest) (*models.URL, error) {
+	var u models.URL
+	if err := c.do(ctx, http.MethodPost, "/api/v1/urls", req, &u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// CreateURLRequest mirrors the server's create payload.
+type CreateURLRequest struct {
+	LongURL    string     `json:"long_url"`
+	Alias      string     `json:"alias,omitempty"`
+	Tags       []string   `json:"tags,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	CampaignID string     `json:"campaign_id,omitempty"`
+}
+
+// GetURL fetches one link.
+func (c *Client) GetURL(ctx context.Context, code string) (*models.URL, error) {
+	var u models.URL
+	if err := c.do(ctx, http.MethodGet, "/api/v1/urls/"+code, nil, &u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// DeleteURL removes one link.
+func (c *Client) DeleteURL(ctx context.Context, code string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/urls/"+code, nil, nil)
+}
+
+// ListURLs fetches one page of the caller's links.
+func (c *Client) ListURLs(ctx context.Context, limit, offset int64) ([]*models.URL, error) {
+	var urls []*models.URL
+	path := fmt.Sprintf("/api/v1/urls?limit=%d&offset=%d", limit, offset)
+	if err := c.do(ctx, http.MethodGet, path, nil, &urls); err != nil {
+		return nil, err
+	}
+	return urls, nil
+}
+
+// URLPager iterates the caller's links page by page.
+type URLPager struct {
+	client   *Client
+	pageSize int64
+	offset   int64
+	buf      []*models.URL
+	done     bool
+	err      error
+}
+
+// URLs returns a pager over the caller's links.
+func (c *Client) URLs(pageSize int64) *URLPager {
+	if pageSize <= 0 {
+		pageSize = 100
+	}
+	return &URLPager{client: c, pageSize: pageSize}
+}
+
+// Next returns the next link, or nil when the listing is exhausted; check
+// Err afterwards.
+func (p *URLPager) Next(ctx context.Context) (*models.URL, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	if len(p.buf) == 0 {
+		if p.done {
+			return nil, nil
+		}
+		page, err := p.client.ListURLs(ctx, p.pageSize, p.offset)
+		if err != nil {
+			p.err = err
+			return nil, err
+		}
+		p.offset += int64(len(page))
+		if int64(len(page)) < p.pageSize {
+			p.done = true
+		}
+		if len(page) == 0 {
+			return nil, nil
+		}
+		p.buf = page
+	}
+	u := p.buf[0]
+	p.buf = p.buf[1:]
+	return u, nil
+}
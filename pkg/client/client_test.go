@@ -0,0 +1,196 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+// Contract tests: the stub handlers reproduce the server's documented
+// request/response shapes (api/shortener.yaml), so a client change that
+// breaks the wire contract fails here before it ships.
+
+func TestCreateURLContract(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/urls" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer tok123" {
+			t.Errorf("auth header: got %q", got)
+		}
+		if got := r.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("content type: got %q", got)
+		}
+		var req CreateURLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decoding request: %v", err)
+		}
+		if req.LongURL != "https://example.com/page" || req.Alias != "my-alias" {
+			t.Errorf("request payload: %+v", req)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(models.URL{Code: "my-alias", LongURL: req.LongURL})
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, "tok123")
+	u, err := c.CreateURL(context.Background(), CreateURLRequest{
+		LongURL: "https://example.com/page",
+		Alias:   "my-alias",
+	})
+	if err != nil {
+		t.Fatalf("CreateURL: %v", err)
+	}
+	if u.Code != "my-alias" {
+		t.Errorf("decoded code: got %q", u.Code)
+	}
+}
+
+func TestErrorPayloadBecomesAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "not found"})
+	}))
+	defer srv.Close()
+
+	_, err := New(srv.URL, "tok").GetURL(context.Background(), "missing")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("want *APIError, got %T: %v", err, err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound || apiErr.Message != "not found" {
+		t.Errorf("APIError: %+v", apiErr)
+	}
+}
+
+func TestClientDoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid"})
+	}))
+	defer srv.Close()
+
+	if _, err := New(srv.URL, "tok").GetURL(context.Background(), "x"); err == nil {
+		t.Fatal("expected error")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("4xx was retried: %d calls", calls.Load())
+	}
+}
+
+func TestClientRetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(models.URL{Code: "x", LongURL: "https://example.com/"})
+	}))
+	defer srv.Close()
+
+	u, err := New(srv.URL, "tok").GetURL(context.Background(), "x")
+	if err != nil {
+		t.Fatalf("GetURL after retries: %v", err)
+	}
+	if u.Code != "x" || calls.Load() != 3 {
+		t.Errorf("calls=%d, code=%q", calls.Load(), u.Code)
+	}
+}
+
+func TestClientHonoursRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		json.NewEncoder(w).Encode(models.URL{Code: "x", LongURL: "https://example.com/"})
+	}))
+	defer srv.Close()
+
+	start := time.Now()
+	if _, err := New(srv.URL, "tok").GetURL(context.Background(), "x"); err != nil {
+		t.Fatalf("GetURL: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+		t.Errorf("Retry-After not honoured: retried after %s", elapsed)
+	}
+}
+
+func TestRetryStopsWhenContextCancelled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := New(srv.URL, "tok").GetURL(ctx, "x")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want context deadline, got %v", err)
+	}
+}
+
+func TestURLPagerWalksPages(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit, offset := r.URL.Query().Get("limit"), r.URL.Query().Get("offset")
+		if limit != "2" {
+			t.Errorf("limit: got %q", limit)
+		}
+		var page []*models.URL
+		switch offset {
+		case "0":
+			page = []*models.URL{{Code: "a"}, {Code: "b"}}
+		case "2":
+			page = []*models.URL{{Code: "c"}}
+		default:
+			t.Errorf("unexpected offset %q", offset)
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer srv.Close()
+
+	pager := New(srv.URL, "tok").URLs(2)
+	var codes []string
+	for {
+		u, err := pager.Next(context.Background())
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		if u == nil {
+			break
+		}
+		codes = append(codes, u.Code)
+	}
+	if fmt.Sprint(codes) != "[a b c]" {
+		t.Errorf("pager codes: %v", codes)
+	}
+}
+
+func TestDeleteURLNoContent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			t.Errorf("method: got %s", r.Method)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	if err := New(srv.URL, "tok").DeleteURL(context.Background(), "gone"); err != nil {
+		t.Errorf("DeleteURL: %v", err)
+	}
+}
@@ -0,0 +1,13 @@
+package common
+
+import "os"
+
+// Getenv returns the value of the environment variable key, or fallback when
+// it is unset or empty. Services read all configuration through this helper
+// so defaults live next to the lookup.
+func Getenv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,57 @@
+package common
+
+import "sync"
+
+// ErrorInfo is one entry of the machine-readable error catalog served at
+// /api/errors, so client developers and support tooling can map errors
+// programmatically.
+type ErrorInfo struct {
+	// Code is the stable machine-readable identifier.
+	Code string `json:"code"`
+	// HTTPStatus is the status this error surfaces as.
+	HTTPStatus int `json:"http_status"`
+	// Description says what went wrong.
+	Description string `json:"description"`
+	// Remediation tells the caller how to proceed.
+	Remediation string `json:"remediation"`
+}
+
+var (
+	catalogMu sync.Mutex
+	catalog   []ErrorInfo
+)
+
+// RegisterError adds an entry to the error catalog; packages register
+// their typed errors at init time.
+func RegisterError(info ErrorInfo) {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	catalog = append(catalog, info)
+}
+
+// ErrorCatalog returns a copy of the registered entries.
+func ErrorCatalog() []ErrorInfo {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	out := make([]ErrorInfo, len(catalog))
+	copy(out, catalog)
+	return out
+}
+
+func init() {
+	RegisterError(ErrorInfo{
+		Code: "not_found", HTTPStatus: 404,
+		Description: "the requested resource does not exist or is not visible to you",
+		Remediation: "check the identifier; deleted and pending links are indistinguishable from missing ones",
+	})
+	RegisterError(ErrorInfo{
+		Code: "conflict", HTTPStatus: 409,
+		Description: "the operation conflicts with current state (taken alias, concurrent edit, reservation)",
+		Remediation: "re-read the resource and retry with fresh state, or pick a different alias",
+	})
+	RegisterError(ErrorInfo{
+		Code: "invalid_input", HTTPStatus: 400,
+		Description: "the request payload failed validation",
+		Remediation: "fix the field named in the error message and resend",
+	})
+}
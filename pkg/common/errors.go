@@ -0,0 +1,17 @@
+// Package common holds small utilities shared by every service: error
+// helpers, configuration loading and logging.
+package common
+
+import "errors"
+
+// Sentinel errors shared across service boundaries. Repositories and
+// services wrap these with context; handlers unwrap them with errors.Is to
+// pick a status code.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrInvalidInput = errors.New("invalid input")
+)
+
+// IsNotFound reports whether err wraps ErrNotFound.
+func IsNotFound(err error) bool { return errors.Is(err, ErrNotFound) }
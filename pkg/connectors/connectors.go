@@ -0,0 +1,168 @@
+// Package connectors delivers events to external systems: Slack channels,
+// Google Sheets rows and generic webhooks. Connectors share one interface
+// plus common retry handling, and are configured per org through the
+// integrations API; the framework is transport-only and knows nothing
+// about what produced an event.
+package connectors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Event is the unit of delivery.
+type Event struct {
+	Type    string                 `json:"type"`
+	OrgID   string                 `json:"org_id"`
+	At      time.Time              `json:"at"`
+	Payload map[string]interface{} `json:"payload"`
+}
+
+// Connector delivers one event to one destination.
+type Connector interface {
+	// Name identifies the connector type ("slack", "sheets", "webhook").
+	Name() string
+	// Deliver sends the event; transient failures should return an error
+	// so the dispatcher retries.
+	Deliver(ctx context.Context, e Event) error
+}
+
+// deliveryAttempts bounds retries per event per connector.
+const deliveryAttempts = 3
+
+// httpClient is shared by all HTTP-based connectors.
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+// postJSON is the shared delivery primitive.
+func postJSON(ctx context.Context, url string, headers map[string]string, body interface{}) error {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("connectors: encoding payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(raw))
+	if err != nil {
+		return fmt.Errorf("connectors: building request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("connectors: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("connectors: destination returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SlackConnector posts events as messages to an incoming-webhook URL.
+type SlackConnector struct {
+	WebhookURL string
+}
+
+// Name implements Connector.
+func (SlackConnector) Name() string { return "slack" }
+
+// Deliver implements Connector.
+func (c SlackConnector) Deliver(ctx context.Context, e Event) error {
+	text := fmt.Sprintf("*%s*", e.Type)
+	if code, ok := e.Payload["code"].(string); ok {
+		text += " " + code
+	}
+	if msg, ok := e.Payload["message"].(string); ok {
+		text += ": " + msg
+	}
+	return postJSON(ctx, c.WebhookURL, nil, map[string]string{"text": text})
+}
+
+// SheetsConnector appends events as rows through the Google Sheets append
+// endpoint using a bearer token managed by the org.
+type SheetsConnector struct {
+	SpreadsheetID string
+	Range         string
+	AccessToken   string
+}
+
+// Name implements Connector.
+func (SheetsConnector) Name() string { return "sheets" }
+
+// Deliver implements Connector.
+func (c SheetsConnector) Deliver(ctx context.Context, e Event) error {
+	url := fmt.Sprintf(
+		"https://sheets.googleapis.com/v4/spreadsheets/%s/values/%s:append?valueInputOption=RAW",
+		c.SpreadsheetID, c.Range)
+	payload, _ := json.Marshal(e.Payload)
+	row := []interface{}{e.At.UTC().Format(time.RFC3339), e.Type, string(payload)}
+	return postJSON(ctx, url,
+		map[string]string{"Authorization": "Bearer " + c.AccessToken},
+		map[string]interface{}{"values": []interface{}{row}})
+}
+
+// WebhookConnector posts the raw event to an arbitrary URL, optionally
+// signing it with a shared secret header.
+type WebhookConnector struct {
+	URL    string
+	Secret string
+}
+
+// Name implements Connector.
+func (WebhookConnector) Name() string { return "webhook" }
+
+// Deliver implements Connector.
+func (c WebhookConnector) Deliver(ctx context.Context, e Event) error {
+	headers := map[string]string{}
+	if c.Secret != "" {
+		headers["X-Webhook-Secret"] = c.Secret
+	}
+	return postJSON(ctx, c.URL, headers, e)
+}
+
+// Dispatcher fans events out to an org's configured connectors with
+// bounded retries, asynchronously: producers never block on delivery.
+type Dispatcher struct {
+	// Resolve returns the connectors configured for an org.
+	Resolve func(ctx context.Context, orgID string) ([]Connector, error)
+}
+
+// Dispatch delivers e to every connector of its org in the background.
+func (d *Dispatcher) Dispatch(e Event) {
+	if d == nil || d.Resolve == nil {
+		return
+	}
+	if e.At.IsZero() {
+		e.At = time.Now().UTC()
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		conns, err := d.Resolve(ctx, e.OrgID)
+		if err != nil {
+			log.Printf("connectors: resolving for org %s: %v", e.OrgID, err)
+			return
+		}
+		for _, conn := range conns {
+			var err error
+			for attempt := 0; attempt < deliveryAttempts; attempt++ {
+				if err = conn.Deliver(ctx, e); err == nil {
+					break
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(time.Duration(attempt+1) * time.Second):
+				}
+			}
+			if err != nil {
+				log.Printf("connectors: %s delivery for org %s failed: %v", conn.Name(), e.OrgID, err)
+			}
+		}
+	}()
+}
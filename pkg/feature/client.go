@@ -0,0 +1,49 @@
+// Package feature wraps the Unleash client behind a tiny interface so
+// services can gate behaviour on flags without binding to the provider,
+// and tests can substitute a static implementation.
+package feature
+
+import (
+	"fmt"
+
+	"github.com/Unleash/unleash-client-go/v4"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+)
+
+// Flags answers flag queries. Unleash evaluates percentage rollouts
+// server-side, so a "50% of requests" canary is just repeated IsEnabled
+// calls.
+type Flags interface {
+	IsEnabled(name string) bool
+}
+
+// Client is the Unleash-backed Flags implementation.
+type Client struct {
+	unleash *unleash.Client
+}
+
+// NewClient initialises the Unleash client for serviceName using the
+// UNLEASH_URL environment setting.
+func NewClient(serviceName string) (*Client, error) {
+	client, err := unleash.NewClient(
+		unleash.WithUrl(common.Getenv("UNLEASH_URL", "http://unleash-service/api/")),
+		unleash.WithAppName(serviceName),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("initialising unleash: %w", err)
+	}
+	return &Client{unleash: client}, nil
+}
+
+// IsEnabled implements Flags.
+func (c *Client) IsEnabled(name string) bool {
+	return c.unleash.IsEnabled(name)
+}
+
+// Static is a fixed Flags implementation for tests and deployments
+// without an Unleash server.
+type Static map[string]bool
+
+// IsEnabled implements Flags.
+func (s Static) IsEnabled(name string) bool { return s[name] }
@@ -0,0 +1,135 @@
+// Package fieldcrypt provides field-level encryption for sensitive values
+// stored in MongoDB and Redis. Ciphertexts are self-describing
+// ("enc:<key id>:<base64>") so multiple keys coexist: new writes use the
+// active key while reads decrypt with whichever key produced the value,
+// which is all rotation needs.
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+)
+
+// prefix marks encrypted values; anything without it is treated as legacy
+// plaintext and passed through by Decrypt.
+const prefix = "enc:"
+
+// Codec encrypts and decrypts field values with AES-GCM.
+type Codec struct {
+	keys   map[string]cipher.AEAD
+	active string
+}
+
+// New builds a Codec from key material: keys maps key IDs to 32-byte AES
+// keys, activeID names the key used for new writes.
+func New(keys map[string][]byte, activeID string) (*Codec, error) {
+	if _, ok := keys[activeID]; !ok {
+		return nil, fmt.Errorf("fieldcrypt: active key %q not in key set", activeID)
+	}
+	c := &Codec{keys: make(map[string]cipher.AEAD, len(keys)), active: activeID}
+	for id, raw := range keys {
+		if len(raw) != 32 {
+			return nil, fmt.Errorf("fieldcrypt: key %q must be 32 bytes, got %d", id, len(raw))
+		}
+		block, err := aes.NewCipher(raw)
+		if err != nil {
+			return nil, fmt.Errorf("fieldcrypt: key %q: %w", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("fieldcrypt: key %q: %w", id, err)
+		}
+		c.keys[id] = aead
+	}
+	return c, nil
+}
+
+// FromEnv builds the deployment's codec from URL_ENCRYPTION_KEYS and
+// URL_ENCRYPTION_ACTIVE, returning (nil, nil) when encryption is not
+// configured. Every binary and tool that reads stored destinations — not
+// just the services — resolves the codec through this, so none of them
+// can ship ciphertext when encryption is on.
+func FromEnv() (*Codec, error) {
+	raw := common.Getenv("URL_ENCRYPTION_KEYS", "")
+	if raw == "" {
+		return nil, nil
+	}
+	keys, err := ParseKeys(raw)
+	if err != nil {
+		return nil, err
+	}
+	return New(keys, common.Getenv("URL_ENCRYPTION_ACTIVE", "v1"))
+}
+
+// DecryptIfConfigured opens value with c, passing it through when c is nil
+// (encryption off).
+func (c *Codec) DecryptIfConfigured(value string) (string, error) {
+	if c == nil {
+		return value, nil
+	}
+	return c.Decrypt(value)
+}
+
+// ParseKeys parses the configuration syntax "id1:base64key,id2:base64key".
+func ParseKeys(raw string) (map[string][]byte, error) {
+	keys := map[string][]byte{}
+	for _, entry := range strings.Split(raw, ",") {
+		id, b64, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok {
+			return nil, fmt.Errorf("fieldcrypt: malformed key entry %q", entry)
+		}
+		key, err := base64.StdEncoding.DecodeString(b64)
+		if err != nil {
+			return nil, fmt.Errorf("fieldcrypt: decoding key %q: %w", id, err)
+		}
+		keys[id] = key
+	}
+	return keys, nil
+}
+
+// Encrypt seals plain under the active key.
+func (c *Codec) Encrypt(plain string) (string, error) {
+	aead := c.keys[c.active]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("fieldcrypt: generating nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plain), nil)
+	return prefix + c.active + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens value with the key that produced it. Values without the
+// encryption prefix (written before encryption was enabled) pass through
+// unchanged.
+func (c *Codec) Decrypt(value string) (string, error) {
+	if !strings.HasPrefix(value, prefix) {
+		return value, nil
+	}
+	keyID, b64, ok := strings.Cut(strings.TrimPrefix(value, prefix), ":")
+	if !ok {
+		return "", errors.New("fieldcrypt: malformed ciphertext")
+	}
+	aead, ok := c.keys[keyID]
+	if !ok {
+		return "", fmt.Errorf("fieldcrypt: unknown key %q", keyID)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		return "", fmt.Errorf("fieldcrypt: decoding ciphertext: %w", err)
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("fieldcrypt: ciphertext too short")
+	}
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("fieldcrypt: opening ciphertext: %w", err)
+	}
+	return string(plain), nil
+}
@@ -0,0 +1,108 @@
+package fieldcrypt
+
+import (
+	"strings"
+	"testing"
+)
+
+func testCodec(t *testing.T, active string, ids ...string) *Codec {
+	t.Helper()
+	keys := map[string][]byte{}
+	for _, id := range ids {
+		key := make([]byte, 32)
+		copy(key, id)
+		keys[id] = key
+	}
+	c, err := New(keys, active)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return c
+}
+
+func TestEncryptDecryptRoundTrip(t *testing.T) {
+	c := testCodec(t, "v1", "v1")
+	sealed, err := c.Encrypt("https://example.com/secret")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if !strings.HasPrefix(sealed, "enc:v1:") {
+		t.Fatalf("ciphertext not self-describing: %q", sealed)
+	}
+	plain, err := c.Decrypt(sealed)
+	if err != nil {
+		t.Fatalf("Decrypt: %v", err)
+	}
+	if plain != "https://example.com/secret" {
+		t.Errorf("round trip: got %q", plain)
+	}
+}
+
+func TestDecryptLegacyPlaintextPassesThrough(t *testing.T) {
+	c := testCodec(t, "v1", "v1")
+	plain, err := c.Decrypt("https://example.com/unencrypted")
+	if err != nil {
+		t.Fatalf("Decrypt: %v", err)
+	}
+	if plain != "https://example.com/unencrypted" {
+		t.Errorf("plaintext passthrough: got %q", plain)
+	}
+}
+
+func TestKeyRotationDecryptsOldKey(t *testing.T) {
+	old := testCodec(t, "v1", "v1")
+	sealed, err := old.Encrypt("rotate me")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	// After rotation v2 is active but v1 stays in the key set.
+	rotated := testCodec(t, "v2", "v1", "v2")
+	plain, err := rotated.Decrypt(sealed)
+	if err != nil {
+		t.Fatalf("Decrypt with rotated codec: %v", err)
+	}
+	if plain != "rotate me" {
+		t.Errorf("rotation: got %q", plain)
+	}
+	resealed, err := rotated.Encrypt("rotate me")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if !strings.HasPrefix(resealed, "enc:v2:") {
+		t.Errorf("new writes should use the active key: %q", resealed)
+	}
+}
+
+func TestDecryptRejectsTamperedCiphertext(t *testing.T) {
+	c := testCodec(t, "v1", "v1")
+	sealed, err := c.Encrypt("payload")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	tampered := sealed[:len(sealed)-2] + "AA"
+	if _, err := c.Decrypt(tampered); err == nil {
+		t.Error("tampered ciphertext decrypted without error")
+	}
+}
+
+func TestNewRejectsBadKeys(t *testing.T) {
+	if _, err := New(map[string][]byte{"v1": make([]byte, 16)}, "v1"); err == nil {
+		t.Error("16-byte key accepted")
+	}
+	if _, err := New(map[string][]byte{"v1": make([]byte, 32)}, "v2"); err == nil {
+		t.Error("missing active key accepted")
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	keys, err := ParseKeys("v1:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=, v2:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=")
+	if err != nil {
+		t.Fatalf("ParseKeys: %v", err)
+	}
+	if len(keys) != 2 || len(keys["v1"]) != 32 {
+		t.Errorf("parsed %d keys, v1 len %d", len(keys), len(keys["v1"]))
+	}
+	if _, err := ParseKeys("malformed"); err == nil {
+		t.Error("malformed entry accepted")
+	}
+}
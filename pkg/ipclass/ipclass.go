@@ -0,0 +1,88 @@
+// Package ipclass classifies client IPs by network type — residential,
+// datacenter, mobile — from a CIDR database file, powering analytics
+// breakdowns and abuse heuristics. The database is a simple CSV
+// (cidr,type,asn) refreshed by the deployment pipeline; lookups are pure
+// in-memory prefix matches.
+package ipclass
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Network types.
+const (
+	NetworkResidential = "residential"
+	NetworkDatacenter  = "datacenter"
+	NetworkMobile      = "mobile"
+	NetworkUnknown     = "unknown"
+)
+
+// Class is one lookup result.
+type Class struct {
+	Network string
+	ASN     int
+}
+
+type entry struct {
+	network *net.IPNet
+	class   Class
+}
+
+// Classifier answers IP classification queries.
+type Classifier struct {
+	entries []entry
+}
+
+// Load reads the database file. Malformed lines are skipped so a partial
+// refresh never takes the service down.
+func Load(path string) (*Classifier, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ipclass: opening database: %w", err)
+	}
+	defer f.Close()
+	c := &Classifier{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.Split(line, ",")
+		if len(parts) < 2 {
+			continue
+		}
+		_, network, err := net.ParseCIDR(strings.TrimSpace(parts[0]))
+		if err != nil {
+			continue
+		}
+		class := Class{Network: strings.TrimSpace(parts[1])}
+		if len(parts) >= 3 {
+			class.ASN, _ = strconv.Atoi(strings.TrimSpace(parts[2]))
+		}
+		c.entries = append(c.entries, entry{network: network, class: class})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ipclass: reading database: %w", err)
+	}
+	return c, nil
+}
+
+// Classify returns the class for ip; unknown addresses and a nil
+// Classifier classify as NetworkUnknown.
+func (c *Classifier) Classify(ip net.IP) Class {
+	if c == nil || ip == nil {
+		return Class{Network: NetworkUnknown}
+	}
+	for _, e := range c.entries {
+		if e.network.Contains(ip) {
+			return e.class
+		}
+	}
+	return Class{Network: NetworkUnknown}
+}
@@ -0,0 +1,157 @@
+// Package metering counts billable operations per organisation and API key.
+// Counters accumulate in a Redis hash per UTC day; a periodic rollup moves
+// closed days into MongoDB, which the usage API and CSV export read.
+package metering
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Billable operation names. These are the field values persisted in rollups,
+// so renaming one is a data migration.
+const (
+	OpCreate         = "create"
+	OpRedirect       = "redirect"
+	OpAnalyticsQuery = "analytics_query"
+)
+
+// dayFormat keys the per-day Redis hashes and rollup documents.
+const dayFormat = "2006-01-02"
+
+// Record is one rolled-up counter: the number of times an org/key pair
+// performed an operation on a day.
+type Record struct {
+	Day   string `bson:"day" json:"day"`
+	OrgID string `bson:"org_id" json:"org_id"`
+	KeyID string `bson:"key_id" json:"key_id"`
+	Op    string `bson:"op" json:"op"`
+	Count int64  `bson:"count" json:"count"`
+}
+
+// Meter records operations and rolls them up.
+type Meter struct {
+	rdb     *redis.Client
+	rollups *mongo.Collection
+}
+
+// New returns a Meter writing live counters to rdb and rollups to db's
+// usage_rollups collection.
+func New(rdb *redis.Client, db *mongo.Database) *Meter {
+	return &Meter{rdb: rdb, rollups: db.Collection("usage_rollups")}
+}
+
+func dayKey(day string) string { return "usage:" + day }
+
+// Record bumps the counter for the given operation. Metering failures are
+// logged and swallowed: billing must never fail a customer request.
+func (m *Meter) Record(ctx context.Context, orgID, keyID, op string) {
+	field := strings.Join([]string{orgID, keyID, op}, "|")
+	day := time.Now().UTC().Format(dayFormat)
+	pipe := m.rdb.Pipeline()
+	pipe.HIncrBy(ctx, dayKey(day), field, 1)
+	pipe.Expire(ctx, dayKey(day), 72*time.Hour)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("metering: recording %s: %v", op, err)
+	}
+}
+
+// RollupDay moves the counters for day from Redis into MongoDB and deletes
+// the hash. It is idempotent: rerunning for an already-rolled-up day
+// overwrites the same documents.
+func (m *Meter) RollupDay(ctx context.Context, day string) error {
+	fields, err := m.rdb.HGetAll(ctx, dayKey(day)).Result()
+	if err != nil {
+		return fmt.Errorf("reading counters for %s: %w", day, err)
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	for field, raw := range fields {
+		parts := strings.SplitN(field, "|", 3)
+		if len(parts) != 3 {
+			log.Printf("metering: skipping malformed field %q", field)
+			continue
+		}
+		count, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			log.Printf("metering: skipping malformed count %q: %v", raw, err)
+			continue
+		}
+		rec := Record{Day: day, OrgID: parts[0], KeyID: parts[1], Op: parts[2], Count: count}
+		filter := bson.M{"day": day, "org_id": rec.OrgID, "key_id": rec.KeyID, "op": rec.Op}
+		_, err = m.rollups.ReplaceOne(ctx, filter, rec, options.Replace().SetUpsert(true))
+		if err != nil {
+			return fmt.Errorf("writing rollup for %s: %w", field, err)
+		}
+	}
+	if err := m.rdb.Del(ctx, dayKey(day)).Err(); err != nil {
+		return fmt.Errorf("deleting counters for %s: %w", day, err)
+	}
+	return nil
+}
+
+// Start rolls up the previous UTC day every hour until ctx is cancelled.
+// Hourly reruns are cheap because RollupDay is idempotent and the hash is
+// deleted after the first success.
+func (m *Meter) Start(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				yesterday := time.Now().UTC().AddDate(0, 0, -1).Format(dayFormat)
+				if err := m.RollupDay(ctx, yesterday); err != nil {
+					log.Printf("metering: rollup: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Usage returns the rolled-up records for an org between from and to
+// (inclusive, dayFormat strings).
+func (m *Meter) Usage(ctx context.Context, orgID, from, to string) ([]Record, error) {
+	cur, err := m.rollups.Find(ctx, bson.M{
+		"org_id": orgID,
+		"day":    bson.M{"$gte": from, "$lte": to},
+	}, options.Find().SetSort(bson.D{{Key: "day", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("querying usage: %w", err)
+	}
+	defer cur.Close(ctx)
+	var out []Record
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, fmt.Errorf("decoding usage: %w", err)
+	}
+	return out, nil
+}
+
+// WriteCSV writes records in the billing-export format.
+func WriteCSV(w io.Writer, records []Record) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"day", "org_id", "key_id", "op", "count"}); err != nil {
+		return err
+	}
+	for _, r := range records {
+		if err := cw.Write([]string{r.Day, r.OrgID, r.KeyID, r.Op, strconv.FormatInt(r.Count, 10)}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
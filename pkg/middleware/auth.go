@@ -0,0 +1,90 @@
+// Package middleware holds the HTTP middleware shared by the url-minifier
+// services: authentication, logging and recovery.
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/ashtonholgate/url-minifier/pkg/auth"
+)
+
+type contextKey string
+
+// principalKey is where Authenticate stores the caller's principal.
+const principalKey contextKey = "principal"
+
+// PrincipalFrom returns the authenticated principal stored in ctx by
+// Authenticate, and whether one was present.
+func PrincipalFrom(ctx context.Context) (auth.Principal, bool) {
+	pr, ok := ctx.Value(principalKey).(auth.Principal)
+	return pr, ok
+}
+
+// WithPrincipal returns ctx carrying pr; exported for tests and for services
+// that authenticate through other means (e.g. HMAC signing).
+func WithPrincipal(ctx context.Context, pr auth.Principal) context.Context {
+	return context.WithValue(ctx, principalKey, pr)
+}
+
+// Claims are the JWT claims issued by the auth service.
+type Claims struct {
+	OrgID     string `json:"org_id,omitempty"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// RevocationChecker reports whether the session a token is bound to is still
+// live. auth.SessionStore implements it.
+type RevocationChecker interface {
+	Check(ctx context.Context, sessionID string) error
+}
+
+// Authenticate verifies the Bearer token with secret and stores the
+// resulting principal in the request context. When sessions is non-nil the
+// token's session must still be live, so revoked sessions die immediately.
+// Requests without a valid token are rejected with 401.
+func Authenticate(secret []byte, sessions RevocationChecker) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// An earlier middleware (e.g. HMACAuth) may already have
+			// authenticated the request.
+			if _, ok := PrincipalFrom(r.Context()); ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			raw := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if raw == "" || raw == r.Header.Get("Authorization") {
+				http.Error(w, "missing bearer token", http.StatusUnauthorized)
+				return
+			}
+			var claims Claims
+			_, err := jwt.ParseWithClaims(raw, &claims, func(t *jwt.Token) (interface{}, error) {
+				return secret, nil
+			}, jwt.WithValidMethods([]string{"HS256"}))
+			if err != nil {
+				http.Error(w, "invalid token", http.StatusUnauthorized)
+				return
+			}
+			if sessions != nil {
+				if err := sessions.Check(r.Context(), claims.SessionID); err != nil {
+					http.Error(w, "session revoked", http.StatusUnauthorized)
+					return
+				}
+			}
+			pr := auth.Principal{
+				UserID: claims.Subject,
+				OrgID:  claims.OrgID,
+				Role:   auth.Role(claims.Role),
+			}
+			if !pr.Role.Valid() {
+				pr.Role = auth.RoleViewer
+			}
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), pr)))
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+// ClientIP extracts the originating client address. Only the LAST
+// X-Forwarded-For hop is trusted: it is the one appended by our own load
+// balancer, i.e. the address the client actually connected from. Earlier
+// hops are attacker-supplied — trusting the leftmost would let a stolen
+// CIDR-restricted key spoof an allowed address and let scanners dodge
+// per-IP bans with a forged header.
+func ClientIP(r *http.Request) net.IP {
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		hops := strings.Split(xff, ",")
+		if ip := net.ParseIP(strings.TrimSpace(hops[len(hops)-1])); ip != nil {
+			return ip
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return net.ParseIP(r.RemoteAddr)
+	}
+	return net.ParseIP(host)
+}
+
+// checkKeyRestrictions enforces the key's optional origin and IP
+// restrictions against the request. A nil error means the request may
+// proceed.
+func checkKeyRestrictions(key *models.APIKey, r *http.Request) error {
+	if len(key.AllowedOrigins) > 0 {
+		origin := r.Header.Get("Origin")
+		ok := false
+		for _, allowed := range key.AllowedOrigins {
+			if strings.EqualFold(origin, allowed) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return fmt.Errorf("origin %q not allowed for key %s", origin, key.KeyID)
+		}
+	}
+	if len(key.AllowedCIDRs) > 0 {
+		ip := ClientIP(r)
+		ok := false
+		for _, cidr := range key.AllowedCIDRs {
+			_, network, err := net.ParseCIDR(cidr)
+			if err != nil {
+				// A malformed entry must fail closed, not open the key
+				// to the world.
+				continue
+			}
+			if ip != nil && network.Contains(ip) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return fmt.Errorf("client ip %s not allowed for key %s", ip, key.KeyID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIPUsesLastForwardedHop(t *testing.T) {
+	r := httptest.NewRequest("GET", "/x", nil)
+	r.RemoteAddr = "10.0.0.5:1234"
+	// The attacker controls the leading hops; only the trailing one was
+	// appended by our load balancer.
+	r.Header.Set("X-Forwarded-For", "203.0.113.7, 198.51.100.9")
+	if got := ClientIP(r).String(); got != "198.51.100.9" {
+		t.Errorf("ClientIP: got %s, want the last hop", got)
+	}
+}
+
+func TestClientIPFallsBackToRemoteAddr(t *testing.T) {
+	r := httptest.NewRequest("GET", "/x", nil)
+	r.RemoteAddr = "192.0.2.4:5678"
+	if got := ClientIP(r).String(); got != "192.0.2.4" {
+		t.Errorf("ClientIP: got %s", got)
+	}
+	r.Header.Set("X-Forwarded-For", "garbage")
+	if got := ClientIP(r).String(); got != "192.0.2.4" {
+		t.Errorf("ClientIP with malformed XFF: got %s", got)
+	}
+}
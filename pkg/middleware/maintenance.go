@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// maintenanceKey is the shared Redis flag; every instance sees the same
+// switch, so maintenance is toggled once through the admin API rather than
+// by restarting pods.
+const maintenanceKey = "maintenance:state"
+
+// MaintenanceState is the banner served to blocked requests.
+type MaintenanceState struct {
+	Message string     `json:"message"`
+	ETA     *time.Time `json:"eta,omitempty"`
+	SetBy   string     `json:"set_by,omitempty"`
+	SetAt   time.Time  `json:"set_at"`
+}
+
+// MaintenanceStore reads and writes the shared maintenance flag.
+type MaintenanceStore struct {
+	rdb *redis.Client
+}
+
+// NewMaintenanceStore returns a store over rdb.
+func NewMaintenanceStore(rdb *redis.Client) *MaintenanceStore {
+	return &MaintenanceStore{rdb: rdb}
+}
+
+// Get returns the active state, or nil when maintenance is off. Redis
+// errors fail open: an outage must not lock the API.
+func (s *MaintenanceStore) Get(ctx context.Context) *MaintenanceState {
+	raw, err := s.rdb.Get(ctx, maintenanceKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	if err != nil {
+		log.Printf("maintenance: reading flag: %v", err)
+		return nil
+	}
+	var state MaintenanceState
+	if err := json.Unmarshal(raw, &state); err != nil {
+		log.Printf("maintenance: decoding flag: %v", err)
+		return nil
+	}
+	return &state
+}
+
+// Set enables maintenance mode with the given banner.
+func (s *MaintenanceStore) Set(ctx context.Context, state MaintenanceState) error {
+	state.SetAt = time.Now().UTC()
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return s.rdb.Set(ctx, maintenanceKey, raw, 0).Err()
+}
+
+// Clear disables maintenance mode.
+func (s *MaintenanceStore) Clear(ctx context.Context) error {
+	return s.rdb.Del(ctx, maintenanceKey).Err()
+}
+
+// Maintenance blocks mutating requests with the banner payload while
+// maintenance is on; reads and redirects continue.
+func Maintenance(store *MaintenanceStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				next.ServeHTTP(w, r)
+				return
+			}
+			state := store.Get(r.Context())
+			if state == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			if state.ETA != nil && state.ETA.After(time.Now()) {
+				w.Header().Set("Retry-After", state.ETA.UTC().Format(http.TimeFormat))
+			}
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":       "maintenance in progress",
+				"maintenance": state,
+			})
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Per-route latency histogram, tagged by status class so SLO burn can be
+// computed per route without high-cardinality status labels.
+var requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "urlminifier",
+	Name:      "http_request_duration_seconds",
+	Help:      "HTTP request latency by route and status class.",
+	Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+}, []string{"route", "status_class"})
+
+// Redirect availability counters back the redirect SLO: availability is
+// redirect_requests_total{outcome="success"} / sum over outcomes.
+var redirectRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "urlminifier",
+	Name:      "redirect_requests_total",
+	Help:      "Redirect requests by outcome (success, not_found, error).",
+}, []string{"outcome"})
+
+// statusRecorder captures the response code for metric labels.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// traceID extracts a trace identifier for exemplars, preferring the W3C
+// traceparent set by our ingress over the legacy X-Trace-Id.
+func traceID(r *http.Request) string {
+	if tp := r.Header.Get("Traceparent"); tp != "" {
+		parts := strings.Split(tp, "-")
+		if len(parts) >= 2 {
+			return parts[1]
+		}
+	}
+	return r.Header.Get("X-Trace-Id")
+}
+
+// traceIDKey stores the request's trace ID in the context so lower layers
+// (e.g. the cache) can attach it to their own exemplars.
+const traceIDKey contextKey = "trace_id"
+
+// TraceIDFrom returns the trace ID stored by the Metrics middleware, or "".
+func TraceIDFrom(ctx context.Context) string {
+	tid, _ := ctx.Value(traceIDKey).(string)
+	return tid
+}
+
+// Metrics observes every request's latency under its mux route template,
+// attaching the request's trace ID as an exemplar so slow buckets link to
+// traces. Redirect outcomes additionally feed the availability SLO
+// counters.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		if tid := traceID(r); tid != "" {
+			r = r.WithContext(context.WithValue(r.Context(), traceIDKey, tid))
+		}
+		start := time.Now()
+		next.ServeHTTP(rec, r)
+		elapsed := time.Since(start).Seconds()
+
+		route := "unmatched"
+		if current := mux.CurrentRoute(r); current != nil {
+			if tmpl, err := current.GetPathTemplate(); err == nil {
+				route = tmpl
+			}
+		}
+		statusClass := fmt.Sprintf("%dxx", rec.status/100)
+		obs := requestDuration.WithLabelValues(route, statusClass)
+		if tid := traceID(r); tid != "" {
+			if eo, ok := obs.(prometheus.ExemplarObserver); ok {
+				eo.ObserveWithExemplar(elapsed, prometheus.Labels{"trace_id": tid})
+				return
+			}
+		}
+		obs.Observe(elapsed)
+	})
+}
+
+// ObserveRedirect feeds the redirect availability SLO.
+func ObserveRedirect(status int) {
+	switch {
+	case status < 400:
+		redirectRequests.WithLabelValues("success").Inc()
+	case status == http.StatusNotFound:
+		redirectRequests.WithLabelValues("not_found").Inc()
+	default:
+		redirectRequests.WithLabelValues("error").Inc()
+	}
+}
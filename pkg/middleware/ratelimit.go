@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/ashtonholgate/url-minifier/pkg/plans"
+)
+
+// RateLimitState is what the limiter knew about the request's window;
+// exposed so the limits discovery endpoint can report the same numbers.
+type RateLimitState struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"reset_at"`
+}
+
+// rateLimiterKey scopes counters per principal per minute window.
+func rateLimiterKey(userID string, window int64) string {
+	return fmt.Sprintf("ratelimit:%s:%d", userID, window)
+}
+
+// RateLimiter enforces per-plan API rate limits with fixed one-minute
+// windows in Redis.
+type RateLimiter struct {
+	rdb *redis.Client
+	ent plans.Entitlements
+}
+
+// NewRateLimiter returns a limiter resolving limits through ent.
+func NewRateLimiter(rdb *redis.Client, ent plans.Entitlements) *RateLimiter {
+	return &RateLimiter{rdb: rdb, ent: ent}
+}
+
+// State returns the current window state for a principal without
+// consuming quota.
+func (l *RateLimiter) State(ctx context.Context, userID, orgID string) (*RateLimitState, error) {
+	plan, err := l.ent.PlanFor(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	window := time.Now().Unix() / 60
+	used, err := l.rdb.Get(ctx, rateLimiterKey(userID, window)).Int()
+	if err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("reading rate counter: %w", err)
+	}
+	state := &RateLimitState{
+		Limit:   plan.APIRateLimitPerMin,
+		ResetAt: time.Unix((window+1)*60, 0).UTC(),
+	}
+	state.Remaining = state.Limit - used
+	if state.Remaining < 0 {
+		state.Remaining = 0
+	}
+	return state, nil
+}
+
+// bankKey holds the principal's accrued burst credits.
+func bankKey(userID string) string { return "ratebank:" + userID }
+
+// allow consumes one request from the principal's window, returning the
+// post-consumption state and whether the request may proceed. Unused
+// quota from the previous minute accrues as burst credits (capped per
+// plan) that soak up spikes beyond the sustained limit, so well-behaved
+// batch clients aren't punished for occasional bursts. Redis failures
+// fail open.
+func (l *RateLimiter) allow(ctx context.Context, userID, orgID string) (*RateLimitState, bool) {
+	plan, err := l.ent.PlanFor(ctx, orgID)
+	if err != nil {
+		log.Printf("ratelimit: resolving plan: %v", err)
+		return nil, true
+	}
+	window := time.Now().Unix() / 60
+	key := rateLimiterKey(userID, window)
+	pipe := l.rdb.Pipeline()
+	count := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, 2*time.Minute)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("ratelimit: counting: %v", err)
+		return nil, true
+	}
+	state := &RateLimitState{
+		Limit:   plan.APIRateLimitPerMin,
+		ResetAt: time.Unix((window+1)*60, 0).UTC(),
+	}
+	used := int(count.Val())
+
+	// On the window's first request, bank whatever the previous window
+	// left unused; the previous counter is still live under its 2m TTL.
+	if used == 1 && plan.APIBurstCredits > 0 {
+		prev, err := l.rdb.Get(ctx, rateLimiterKey(userID, window-1)).Int()
+		if err != nil && err != redis.Nil {
+			log.Printf("ratelimit: reading previous window: %v", err)
+		} else if unused := state.Limit - prev; unused > 0 {
+			banked, err := l.rdb.IncrBy(ctx, bankKey(userID), int64(unused)).Result()
+			if err == nil && banked > int64(plan.APIBurstCredits) {
+				l.rdb.Set(ctx, bankKey(userID), plan.APIBurstCredits, 0)
+			}
+		}
+	}
+
+	state.Remaining = state.Limit - used
+	if state.Remaining < 0 {
+		state.Remaining = 0
+	}
+	if used <= state.Limit {
+		return state, true
+	}
+	// Over the sustained limit: spend a banked burst credit if one is
+	// available.
+	banked, err := l.rdb.Decr(ctx, bankKey(userID)).Result()
+	if err != nil {
+		log.Printf("ratelimit: spending burst credit: %v", err)
+		return state, true
+	}
+	if banked < 0 {
+		l.rdb.Incr(ctx, bankKey(userID))
+		return state, false
+	}
+	return state, true
+}
+
+// setHeaders mirrors the window state onto the standard X-RateLimit
+// response headers.
+func (s *RateLimitState) setHeaders(h http.Header) {
+	h.Set("X-RateLimit-Limit", strconv.Itoa(s.Limit))
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(s.Remaining))
+	h.Set("X-RateLimit-Reset", strconv.FormatInt(s.ResetAt.Unix(), 10))
+}
+
+// Middleware enforces the limit on authenticated requests, mirroring the
+// state in X-RateLimit-* headers on every response so SDKs can
+// self-throttle.
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pr, ok := PrincipalFrom(r.Context())
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		state, allowed := l.allow(r.Context(), pr.UserID, pr.OrgID)
+		if state != nil {
+			state.setHeaders(w.Header())
+		}
+		if !allowed {
+			w.Header().Set("Retry-After", strconv.FormatInt(int64(time.Until(state.ResetAt).Seconds())+1, 10))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(map[string]string{"error": "rate limit exceeded"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ReadOnly rejects mutating requests with a clear 503 while reads pass
+// through, for disaster-recovery windows (primary database failover)
+// where redirects must keep flowing from cache and secondaries. enabled is
+// consulted per request so modes toggled at runtime take effect without a
+// restart.
+func ReadOnly(enabled func() bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !enabled() {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Retry-After", "300")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "service is in read-only mode during database failover; writes will resume shortly",
+			})
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// APIKey is a server-to-server credential. The secret is stored alongside
+// the key because HMAC verification needs it; access to the keys collection
+// is itself restricted.
+type APIKey struct {
+	ID        string     `bson:"_id,omitempty" json:"id"`
+	KeyID     string     `bson:"key_id" json:"key_id"`
+	Secret    string     `bson:"secret" json:"-"`
+	UserID    string     `bson:"user_id" json:"user_id"`
+	OrgID     string     `bson:"org_id,omitempty" json:"org_id,omitempty"`
+	Role      string     `bson:"role" json:"role"`
+	CreatedAt time.Time  `bson:"created_at" json:"created_at"`
+	RevokedAt *time.Time `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	// AllowedOrigins restricts which Origin header values may use this
+	// key; empty means any. Intended for keys embedded in partner
+	// frontends.
+	AllowedOrigins []string `bson:"allowed_origins,omitempty" json:"allowed_origins,omitempty"`
+	// AllowedCIDRs restricts the client networks that may use this key;
+	// empty means any.
+	AllowedCIDRs []string `bson:"allowed_cidrs,omitempty" json:"allowed_cidrs,omitempty"`
+	// Scopes restricts the key to the listed permissions (e.g. only
+	// "url:create" for a CI pipeline); empty means the role's full set.
+	Scopes []string `bson:"scopes,omitempty" json:"scopes,omitempty"`
+	// ServiceAccountID marks keys belonging to a machine principal
+	// rather than a person.
+	ServiceAccountID string `bson:"service_account_id,omitempty" json:"service_account_id,omitempty"`
+	// AllowedDomains and AllowedCampaigns pin the key to specific short
+	// domains or campaigns; empty means unrestricted.
+	AllowedDomains   []string `bson:"allowed_domains,omitempty" json:"allowed_domains,omitempty"`
+	AllowedCampaigns []string `bson:"allowed_campaigns,omitempty" json:"allowed_campaigns,omitempty"`
+}
+
+// Revoked reports whether the key has been revoked.
+func (k *APIKey) Revoked() bool { return k.RevokedAt != nil }
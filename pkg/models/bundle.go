@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Bundle is a short code that renders a landing page listing several
+// destinations instead of redirecting to one.
+type Bundle struct {
+	ID          string       `bson:"_id,omitempty" json:"id"`
+	Code        string       `bson:"code" json:"code"`
+	Title       string       `bson:"title" json:"title"`
+	Description string       `bson:"description,omitempty" json:"description,omitempty"`
+	OwnerID     string       `bson:"owner_id" json:"owner_id"`
+	OrgID       string       `bson:"org_id,omitempty" json:"org_id,omitempty"`
+	Items       []BundleItem `bson:"items" json:"items"`
+	CreatedAt   time.Time    `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time    `bson:"updated_at" json:"updated_at"`
+}
+
+// BundleItem is one destination on a bundle's landing page. Items render in
+// slice order; reordering is a full-slice update.
+type BundleItem struct {
+	Title   string `bson:"title" json:"title"`
+	LongURL string `bson:"long_url" json:"long_url"`
+	Clicks  int64  `bson:"clicks" json:"clicks"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// Campaign groups links for roll-up analytics and bulk management.
+type Campaign struct {
+	ID          string    `bson:"_id,omitempty" json:"id"`
+	Name        string    `bson:"name" json:"name"`
+	Description string    `bson:"description,omitempty" json:"description,omitempty"`
+	OwnerID     string    `bson:"owner_id" json:"owner_id"`
+	OrgID       string    `bson:"org_id,omitempty" json:"org_id,omitempty"`
+	CreatedAt   time.Time `bson:"created_at" json:"created_at"`
+}
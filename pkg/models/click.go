@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// ClickEventImpression marks pixel-load events, e.g. email opens.
+const ClickEventImpression = "impression"
+
+// Click is one recorded redirect event, the raw material for analytics.
+// Click documents are written best-effort by the redirect path and only ever
+// read by the analytics service.
+type Click struct {
+	ID string `bson:"_id,omitempty" json:"id"`
+	// ClickID is the public identifier appended to destinations for
+	// conversion tracking; empty unless the link opted in.
+	ClickID string `bson:"click_id,omitempty" json:"click_id,omitempty"`
+	Code    string `bson:"code" json:"code"`
+	// Event distinguishes impressions (pixel loads) from clicks; empty
+	// means click.
+	Event   string `bson:"event,omitempty" json:"event,omitempty"`
+	OwnerID string `bson:"owner_id" json:"owner_id"`
+	OrgID   string `bson:"org_id,omitempty" json:"org_id,omitempty"`
+	// CampaignID is copied from the link so campaign roll-ups need no
+	// join.
+	CampaignID string `bson:"campaign_id,omitempty" json:"campaign_id,omitempty"`
+	// Domain is the serving domain the click arrived on, for per-domain
+	// roll-ups.
+	Domain    string    `bson:"domain,omitempty" json:"domain,omitempty"`
+	At        time.Time `bson:"at" json:"at"`
+	IP        string    `bson:"ip,omitempty" json:"ip,omitempty"`
+	UserAgent string    `bson:"user_agent,omitempty" json:"user_agent,omitempty"`
+	Referrer  string    `bson:"referrer,omitempty" json:"referrer,omitempty"`
+	// Variant is the experiment arm this click was exposed to, for links
+	// running A/B destinations.
+	Variant string `bson:"variant,omitempty" json:"variant,omitempty"`
+	// Network classifies the client IP (residential/datacenter/mobile)
+	// and ASN identifies its network operator.
+	Network string `bson:"network,omitempty" json:"network,omitempty"`
+	ASN     int    `bson:"asn,omitempty" json:"asn,omitempty"`
+	// Browser, OS and Device are coarse classifications of the user
+	// agent, filled in at record time so analytics never re-parse UAs.
+	Browser string `bson:"browser,omitempty" json:"browser,omitempty"`
+	OS      string `bson:"os,omitempty" json:"os,omitempty"`
+	Device  string `bson:"device,omitempty" json:"device,omitempty"`
+	// Fingerprint is a stable hash of the visitor (IP + user agent) used
+	// for attribution dedup; Unique marks the first click from that
+	// fingerprint within the attribution window.
+	Fingerprint string `bson:"fingerprint,omitempty" json:"fingerprint,omitempty"`
+	Unique      bool   `bson:"unique" json:"unique"`
+}
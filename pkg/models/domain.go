@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Domain lifecycle statuses: pending until DNS verification passes,
+// verified until the certificate is provisioned, then active.
+const (
+	DomainStatusPending  = "pending"
+	DomainStatusVerified = "verified"
+	DomainStatusActive   = "active"
+)
+
+// DomainSettings are per-domain defaults and branding.
+type DomainSettings struct {
+	// RedirectStatus overrides the redirect code (301 default; 302 for
+	// domains whose links change destination).
+	RedirectStatus int `bson:"redirect_status,omitempty" json:"redirect_status,omitempty"`
+	// NotFoundHTML replaces the JSON 404 with branded HTML.
+	NotFoundHTML string `bson:"not_found_html,omitempty" json:"not_found_html,omitempty"`
+	// AnalyticsDisabled turns off click recording for the domain.
+	AnalyticsDisabled bool `bson:"analytics_disabled,omitempty" json:"analytics_disabled,omitempty"`
+	// ReservedPrefixes are path prefixes aliases may not claim on this
+	// domain (e.g. "api", "admin").
+	ReservedPrefixes []string `bson:"reserved_prefixes,omitempty" json:"reserved_prefixes,omitempty"`
+}
+
+// Domain is a short-link domain registered with the deployment, either a
+// default serving domain or a customer's custom domain.
+type Domain struct {
+	ID       string `bson:"_id,omitempty" json:"id"`
+	Domain   string `bson:"domain" json:"domain"`
+	OrgID    string `bson:"org_id,omitempty" json:"org_id,omitempty"`
+	Verified bool   `bson:"verified" json:"verified"`
+	// Status tracks the lifecycle; see the DomainStatus constants.
+	Status string `bson:"status,omitempty" json:"status,omitempty"`
+	// VerifyToken is the challenge the owner must publish in a DNS TXT
+	// record or the well-known file before verification succeeds.
+	VerifyToken string     `bson:"verify_token,omitempty" json:"verify_token,omitempty"`
+	VerifiedAt  *time.Time `bson:"verified_at,omitempty" json:"verified_at,omitempty"`
+	CreatedAt   time.Time  `bson:"created_at" json:"created_at"`
+	// Settings carries per-domain defaults and branding applied by the
+	// redirect and create flows.
+	Settings DomainSettings `bson:"settings,omitempty" json:"settings,omitempty"`
+	// AppleAppSiteAssociation and AndroidAssetLinks hold the raw JSON
+	// documents served at the domain's well-known paths for universal and
+	// app links.
+	AppleAppSiteAssociation string `bson:"apple_app_site_association,omitempty" json:"apple_app_site_association,omitempty"`
+	AndroidAssetLinks       string `bson:"android_asset_links,omitempty" json:"android_asset_links,omitempty"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// LinkFilter describes a server-side query over a user's links. Zero-valued
+// fields are ignored, so an empty filter matches everything the caller owns.
+type LinkFilter struct {
+	// Tags requires every listed tag to be present.
+	Tags []string `bson:"tags,omitempty" json:"tags,omitempty"`
+	// Status matches the moderation status exactly.
+	Status string `bson:"status,omitempty" json:"status,omitempty"`
+	// MinClicks/MaxClicks bound the click counter (inclusive; MaxClicks
+	// zero means unbounded).
+	MinClicks int64 `bson:"min_clicks,omitempty" json:"min_clicks,omitempty"`
+	MaxClicks int64 `bson:"max_clicks,omitempty" json:"max_clicks,omitempty"`
+	// CreatedAfter/CreatedBefore bound the creation time.
+	CreatedAfter  *time.Time `bson:"created_after,omitempty" json:"created_after,omitempty"`
+	CreatedBefore *time.Time `bson:"created_before,omitempty" json:"created_before,omitempty"`
+	// ExpiringBefore matches links whose expiry is set and earlier than
+	// the given instant.
+	ExpiringBefore *time.Time `bson:"expiring_before,omitempty" json:"expiring_before,omitempty"`
+	// DestinationContains substring-matches the destination URL.
+	DestinationContains string `bson:"destination_contains,omitempty" json:"destination_contains,omitempty"`
+	// DestinationBroken, when set, matches links by rot-checker verdict.
+	DestinationBroken *bool `bson:"destination_broken,omitempty" json:"destination_broken,omitempty"`
+}
+
+// SavedFilter is a user-named LinkFilter for smart lists.
+type SavedFilter struct {
+	ID        string     `bson:"_id,omitempty" json:"id"`
+	OwnerID   string     `bson:"owner_id" json:"owner_id"`
+	Name      string     `bson:"name" json:"name"`
+	Filter    LinkFilter `bson:"filter" json:"filter"`
+	CreatedAt time.Time  `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// Org is a customer organisation; its plan drives entitlement checks.
+type Org struct {
+	ID        string    `bson:"_id,omitempty" json:"id"`
+	Name      string    `bson:"name" json:"name"`
+	Plan      string    `bson:"plan" json:"plan"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
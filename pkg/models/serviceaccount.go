@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// ServiceAccount is a non-human principal owned by an org. It
+// authenticates only through its API keys, which carry restricted scopes,
+// so CI pipelines never borrow a person's credentials.
+type ServiceAccount struct {
+	ID        string    `bson:"_id,omitempty" json:"id"`
+	Name      string    `bson:"name" json:"name"`
+	OrgID     string    `bson:"org_id" json:"org_id"`
+	CreatedBy string    `bson:"created_by" json:"created_by"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
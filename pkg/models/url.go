@@ -0,0 +1,169 @@
+// Package models defines the data structures persisted and exchanged by the
+// url-minifier services. Structs here carry both bson and json tags so the
+// same types back the MongoDB collections and the public API payloads.
+package models
+
+import "time"
+
+// Link moderation statuses. An empty status on records predating moderation
+// is treated as active.
+const (
+	URLStatusActive   = "active"
+	URLStatusPending  = "pending"
+	URLStatusRejected = "rejected"
+	// URLStatusDraft marks fully configured links awaiting their
+	// scheduled publish time; they do not redirect.
+	URLStatusDraft = "draft"
+)
+
+// URL is the canonical record for a shortened link.
+type URL struct {
+	ID      string   `bson:"_id,omitempty" json:"id"`
+	Code    string   `bson:"code" json:"code"`
+	LongURL string   `bson:"long_url" json:"long_url"`
+	OwnerID string   `bson:"owner_id" json:"owner_id"`
+	OrgID   string   `bson:"org_id,omitempty" json:"org_id,omitempty"`
+	Tags    []string `bson:"tags,omitempty" json:"tags,omitempty"`
+	// CampaignID groups the link under a campaign for roll-up analytics.
+	CampaignID string `bson:"campaign_id,omitempty" json:"campaign_id,omitempty"`
+	// WorkspaceID shares the link with a workspace; members access it
+	// according to their workspace role.
+	WorkspaceID string `bson:"workspace_id,omitempty" json:"workspace_id,omitempty"`
+	// Domain is the short domain the link is served under; empty means
+	// the deployment default.
+	Domain    string     `bson:"domain,omitempty" json:"domain,omitempty"`
+	CreatedAt time.Time  `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time  `bson:"updated_at" json:"updated_at"`
+	ExpiresAt *time.Time `bson:"expires_at,omitempty" json:"expires_at,omitempty"`
+	Clicks    int64      `bson:"clicks" json:"clicks"`
+	// Verified marks links whose destination domain has been verified by
+	// its owner; surfaced as a badge on previews.
+	Verified bool `bson:"verified" json:"verified"`
+	// Status is the moderation status; see the URLStatus constants.
+	Status string `bson:"status,omitempty" json:"status,omitempty"`
+	// Redirect carries per-link redirect behaviour overrides.
+	Redirect RedirectOptions `bson:"redirect,omitempty" json:"redirect,omitempty"`
+	// DeepLink, when set, serves an app-opening interstitial instead of a
+	// plain redirect; LongURL remains the universal HTTP fallback.
+	DeepLink *DeepLinkOptions `bson:"deep_link,omitempty" json:"deep_link,omitempty"`
+	// Rotation distributes clicks across several destinations; LongURL
+	// serves as the overflow once sequential caps are exhausted.
+	Rotation *RotationOptions `bson:"rotation,omitempty" json:"rotation,omitempty"`
+	// Experiment splits traffic between destination variants with sticky
+	// weighted assignment and exposure logging.
+	Experiment *ExperimentOptions `bson:"experiment,omitempty" json:"experiment,omitempty"`
+	// LanguageTargets overrides the destination per language: keys are
+	// lowercase BCP 47 primary tags or tag-region pairs ("de", "pt-br"),
+	// matched against the visitor's Accept-Language. LongURL remains the
+	// fallback.
+	LanguageTargets map[string]string `bson:"language_targets,omitempty" json:"language_targets,omitempty"`
+	// ExternalIDs maps source systems to their identifiers for this link
+	// (e.g. "crm" -> opportunity ID), enabling round-trip CRM sync.
+	ExternalIDs map[string]string `bson:"external_ids,omitempty" json:"external_ids,omitempty"`
+	// Meta is the destination's Open Graph metadata, fetched
+	// asynchronously after creation and served to unfurl bots.
+	Meta *LinkMeta `bson:"meta,omitempty" json:"meta,omitempty"`
+	// PublishAt schedules a draft's automatic activation.
+	PublishAt *time.Time `bson:"publish_at,omitempty" json:"publish_at,omitempty"`
+	// ResolvedURL is the final landing page after following the
+	// destination's redirect chain, so analytics and dedup operate on
+	// the true destination; ResolvedAt is when the chain was last
+	// walked.
+	ResolvedURL string     `bson:"resolved_url,omitempty" json:"resolved_url,omitempty"`
+	ResolvedAt  *time.Time `bson:"resolved_at,omitempty" json:"resolved_at,omitempty"`
+	// DestinationBroken is set by the background rot checker when the
+	// destination stopped responding; LastCheckedAt is the checker's
+	// watermark.
+	DestinationBroken bool       `bson:"destination_broken,omitempty" json:"destination_broken,omitempty"`
+	LastCheckedAt     *time.Time `bson:"last_checked_at,omitempty" json:"last_checked_at,omitempty"`
+	// Version increments on every update and backs optimistic
+	// concurrency control: writers state the version they read and lose
+	// if someone else got there first.
+	Version int64 `bson:"version" json:"version"`
+}
+
+// ExperimentOptions configure A/B destination testing on a link.
+type ExperimentOptions struct {
+	Name     string              `bson:"name" json:"name"`
+	Variants []ExperimentVariant `bson:"variants" json:"variants"`
+}
+
+// ExperimentVariant is one arm of the experiment; weights are relative.
+type ExperimentVariant struct {
+	Name   string `bson:"name" json:"name"`
+	URL    string `bson:"url" json:"url"`
+	Weight int    `bson:"weight" json:"weight"`
+}
+
+// Rotation modes.
+const (
+	RotationRoundRobin = "round_robin"
+	RotationSequential = "sequential"
+)
+
+// RotationOptions configure multi-destination links.
+type RotationOptions struct {
+	// Mode is round_robin (cycle forever) or sequential (fill each
+	// destination to its cap in order).
+	Mode         string                `bson:"mode" json:"mode"`
+	Destinations []RotationDestination `bson:"destinations" json:"destinations"`
+}
+
+// RotationDestination is one rotation target; Cap only applies in
+// sequential mode (0 = unlimited, which stops the sequence advancing).
+type RotationDestination struct {
+	URL string `bson:"url" json:"url"`
+	Cap int64  `bson:"cap,omitempty" json:"cap,omitempty"`
+}
+
+// DeepLinkOptions configure app URI scheme handling for a link. The
+// platform-specific URIs win over AppURI when the visitor's OS is detected.
+type DeepLinkOptions struct {
+	AppURI        string `bson:"app_uri,omitempty" json:"app_uri,omitempty"`
+	IOSAppURI     string `bson:"ios_app_uri,omitempty" json:"ios_app_uri,omitempty"`
+	AndroidAppURI string `bson:"android_app_uri,omitempty" json:"android_app_uri,omitempty"`
+}
+
+// LinkMeta is the destination page metadata used for chat-platform
+// previews.
+type LinkMeta struct {
+	Title       string `bson:"title,omitempty" json:"title,omitempty"`
+	Description string `bson:"description,omitempty" json:"description,omitempty"`
+	Image       string `bson:"image,omitempty" json:"image,omitempty"`
+}
+
+// RedirectOptions control how a link's redirect response is served.
+type RedirectOptions struct {
+	// StripReferrer serves an interstitial meta-refresh page instead of a
+	// 30x so the destination never sees a Referer header.
+	StripReferrer bool `bson:"strip_referrer,omitempty" json:"strip_referrer,omitempty"`
+	// ReferrerPolicy, when set, is sent as the Referrer-Policy header on
+	// the redirect response (e.g. "no-referrer", "origin").
+	ReferrerPolicy string `bson:"referrer_policy,omitempty" json:"referrer_policy,omitempty"`
+	// AppendClickID appends a unique click identifier to the destination
+	// (um_cid query parameter) so conversions can be tied back to clicks.
+	AppendClickID bool `bson:"append_click_id,omitempty" json:"append_click_id,omitempty"`
+	// FrequencyCap limits how many times one visitor is redirected per
+	// UTC day (0 = uncapped), tracked with a signed first-party cookie;
+	// over-cap visitors go to FrequencyFallbackURL instead.
+	FrequencyCap         int    `bson:"frequency_cap,omitempty" json:"frequency_cap,omitempty"`
+	FrequencyFallbackURL string `bson:"frequency_fallback_url,omitempty" json:"frequency_fallback_url,omitempty"`
+	// CustomHeaders are extra response headers sent on the redirect,
+	// validated against the service allowlist (ad-verification workflows
+	// need e.g. X-Campaign or Cache-Control overrides).
+	CustomHeaders map[string]string `bson:"custom_headers,omitempty" json:"custom_headers,omitempty"`
+	// HTMLFallback serves a meta-refresh + JS page instead of a 30x, for
+	// links opened mostly in email clients and webviews that mishandle
+	// redirects.
+	HTMLFallback bool `bson:"html_fallback,omitempty" json:"html_fallback,omitempty"`
+}
+
+// Active reports whether the link may redirect.
+func (u *URL) Active() bool {
+	return u.Status == "" || u.Status == URLStatusActive
+}
+
+// Expired reports whether the link's expiry, if set, has passed.
+func (u *URL) Expired(now time.Time) bool {
+	return u.ExpiresAt != nil && now.After(*u.ExpiresAt)
+}
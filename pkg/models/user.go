@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// User is an account that owns links and API keys.
+type User struct {
+	ID    string `bson:"_id,omitempty" json:"id"`
+	Email string `bson:"email" json:"email"`
+	OrgID string `bson:"org_id,omitempty" json:"org_id,omitempty"`
+	Role  string `bson:"role" json:"role"`
+	// PasswordHash is the bcrypt hash of the user's password; never
+	// serialised into API responses.
+	PasswordHash string    `bson:"password_hash" json:"-"`
+	CreatedAt    time.Time `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Workspace is a shared link-ownership group sitting between users and the
+// org: members collaborate on the same links with per-member roles and
+// workspace-level defaults.
+type Workspace struct {
+	ID    string `bson:"_id,omitempty" json:"id"`
+	Name  string `bson:"name" json:"name"`
+	OrgID string `bson:"org_id,omitempty" json:"org_id,omitempty"`
+	// DefaultDomain is the short domain new links in this workspace are
+	// presented under.
+	DefaultDomain string            `bson:"default_domain,omitempty" json:"default_domain,omitempty"`
+	Members       []WorkspaceMember `bson:"members" json:"members"`
+	CreatedAt     time.Time         `bson:"created_at" json:"created_at"`
+}
+
+// WorkspaceMember is one user's membership with their workspace role.
+type WorkspaceMember struct {
+	UserID string `bson:"user_id" json:"user_id"`
+	Role   string `bson:"role" json:"role"`
+}
+
+// MemberRole returns the member's role, or "" for non-members.
+func (ws *Workspace) MemberRole(userID string) string {
+	for _, m := range ws.Members {
+		if m.UserID == userID {
+			return m.Role
+		}
+	}
+	return ""
+}
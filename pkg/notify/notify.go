@@ -0,0 +1,22 @@
+// Package notify delivers user-facing notifications. The only transport
+// today is the log, but the interface keeps callers ignorant of delivery so
+// email or webhooks can slot in behind it.
+package notify
+
+import (
+	"context"
+	"log"
+)
+
+// Notifier delivers a message to a user.
+type Notifier interface {
+	Notify(ctx context.Context, userID, subject, message string)
+}
+
+// LogNotifier writes notifications to the process log.
+type LogNotifier struct{}
+
+// Notify implements Notifier.
+func (LogNotifier) Notify(_ context.Context, userID, subject, message string) {
+	log.Printf("notify %s: %s — %s", userID, subject, message)
+}
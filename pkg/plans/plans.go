@@ -0,0 +1,170 @@
+// Package plans defines the billing tiers and the entitlement checks the
+// services use to gate paid features. Services never compare plan names;
+// they ask the Entitlements interface, so billing changes (trials, add-ons,
+// per-org overrides) only touch the implementation.
+package plans
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+)
+
+// ErrUpgradeRequired is wrapped by every entitlement failure; handlers map
+// it to 402 with an upgrade hint.
+var ErrUpgradeRequired = errors.New("plans: upgrade required")
+
+func init() {
+	common.RegisterError(common.ErrorInfo{
+		Code: "upgrade_required", HTTPStatus: 402,
+		Description: "the org's plan does not include the requested feature or quota",
+		Remediation: "upgrade the plan named in the error, or stay within its quotas",
+	})
+	common.RegisterError(common.ErrorInfo{
+		Code: "rate_limited", HTTPStatus: 429,
+		Description: "the plan's API rate limit was exceeded",
+		Remediation: "honour Retry-After and the X-RateLimit-* headers before retrying",
+	})
+}
+
+// Feature names a gated capability.
+type Feature string
+
+const (
+	FeatureCustomAlias   Feature = "custom_alias"
+	FeatureCustomDomains Feature = "custom_domains"
+	FeatureABTesting     Feature = "ab_testing"
+	FeatureAnalyticsAPI  Feature = "analytics_api"
+)
+
+// Plan bundles the quotas and features of a billing tier.
+type Plan struct {
+	Name               string `json:"name"`
+	MaxLinks           int64  `json:"max_links"`
+	APIRateLimitPerMin int    `json:"api_rate_limit_per_min"`
+	// APIBurstCredits caps the banked quota a client can accrue from
+	// quiet minutes and spend during spikes.
+	APIBurstCredits int `json:"api_burst_credits"`
+	// MaxAnalyticsBytes caps the org's raw analytics storage so one
+	// tenant cannot consume the cluster.
+	MaxAnalyticsBytes int64            `json:"max_analytics_bytes"`
+	Features          map[Feature]bool `json:"features"`
+}
+
+// Has reports whether the plan includes the feature.
+func (p Plan) Has(f Feature) bool { return p.Features[f] }
+
+// The built-in tiers. An org with no stored plan is on Free.
+var (
+	Free = Plan{
+		Name:               "free",
+		MaxLinks:           500,
+		APIRateLimitPerMin: 60,
+		APIBurstCredits:    60,
+		MaxAnalyticsBytes:  256 << 20, // 256 MiB
+		Features:           map[Feature]bool{},
+	}
+	Pro = Plan{
+		Name:               "pro",
+		MaxLinks:           50_000,
+		APIRateLimitPerMin: 600,
+		APIBurstCredits:    1200,
+		MaxAnalyticsBytes:  8 << 30, // 8 GiB
+		Features: map[Feature]bool{
+			FeatureCustomAlias:  true,
+			FeatureAnalyticsAPI: true,
+		},
+	}
+	Enterprise = Plan{
+		Name:               "enterprise",
+		MaxLinks:           5_000_000,
+		APIRateLimitPerMin: 6000,
+		APIBurstCredits:    18_000,
+		MaxAnalyticsBytes:  256 << 30, // 256 GiB
+		Features: map[Feature]bool{
+			FeatureCustomAlias:   true,
+			FeatureCustomDomains: true,
+			FeatureABTesting:     true,
+			FeatureAnalyticsAPI:  true,
+		},
+	}
+)
+
+// ByName maps a stored plan name to its definition, defaulting to Free.
+func ByName(name string) Plan {
+	switch name {
+	case Pro.Name:
+		return Pro
+	case Enterprise.Name:
+		return Enterprise
+	default:
+		return Free
+	}
+}
+
+// UpgradeError reports which feature was denied and on which plan, so the
+// API can tell the customer exactly what to upgrade for.
+type UpgradeError struct {
+	Feature Feature
+	Plan    string
+}
+
+func (e *UpgradeError) Error() string {
+	return fmt.Sprintf("feature %s not included in plan %s: %v", e.Feature, e.Plan, ErrUpgradeRequired)
+}
+
+// Unwrap lets errors.Is(err, ErrUpgradeRequired) match.
+func (e *UpgradeError) Unwrap() error { return ErrUpgradeRequired }
+
+// Entitlements is the single check interface the services call.
+type Entitlements interface {
+	// Check returns nil when the org's plan includes the feature, or an
+	// *UpgradeError wrapping ErrUpgradeRequired.
+	Check(ctx context.Context, orgID string, f Feature) error
+	// PlanFor returns the org's current plan definition.
+	PlanFor(ctx context.Context, orgID string) (Plan, error)
+}
+
+// mongoEntitlements resolves plans from the orgs collection.
+type mongoEntitlements struct {
+	orgs *mongo.Collection
+}
+
+// NewEntitlements returns an Entitlements implementation reading org plans
+// from db's orgs collection.
+func NewEntitlements(db *mongo.Database) Entitlements {
+	return &mongoEntitlements{orgs: db.Collection("orgs")}
+}
+
+func (e *mongoEntitlements) PlanFor(ctx context.Context, orgID string) (Plan, error) {
+	if orgID == "" {
+		return Free, nil
+	}
+	var org struct {
+		Plan string `bson:"plan"`
+	}
+	err := e.orgs.FindOne(ctx, bson.M{"_id": orgID}).Decode(&org)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return Free, nil
+	}
+	if err != nil {
+		return Plan{}, fmt.Errorf("resolving plan for org %s: %w", orgID, err)
+	}
+	return ByName(org.Plan), nil
+}
+
+func (e *mongoEntitlements) Check(ctx context.Context, orgID string, f Feature) error {
+	plan, err := e.PlanFor(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if !plan.Has(f) {
+		return &UpgradeError{Feature: f, Plan: plan.Name}
+	}
+	return nil
+}
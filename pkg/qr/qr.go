@@ -0,0 +1,33 @@
+// Package qr renders QR codes for short links. It wraps the encoder
+// behind our own options type so callers never import the underlying
+// library directly.
+package qr
+
+import (
+	"fmt"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// defaultSize is the rendered PNG edge length in pixels.
+const defaultSize = 512
+
+// Options control rendering; the zero value is a plain black-on-white
+// code.
+type Options struct {
+	// Size is the PNG edge length in pixels (defaultSize when zero).
+	Size int
+}
+
+// PNG renders content as a QR code PNG.
+func PNG(content string, opts Options) ([]byte, error) {
+	size := opts.Size
+	if size <= 0 {
+		size = defaultSize
+	}
+	png, err := qrcode.Encode(content, qrcode.Medium, size)
+	if err != nil {
+		return nil, fmt.Errorf("qr: encoding %q: %w", content, err)
+	}
+	return png, nil
+}
@@ -0,0 +1,150 @@
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// Output formats.
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+	FormatEPS = "eps"
+)
+
+// maxLogoFraction is the widest a centre logo may be relative to the code.
+// High error correction recovers up to 30% damage; capping the logo at a
+// fifth of the edge (4% of the area) leaves ample margin.
+const maxLogoFraction = 0.2
+
+// RenderOptions extend Options with branding for customised codes.
+type RenderOptions struct {
+	Options
+	// Foreground and Background override the default black on white.
+	Foreground color.Color
+	Background color.Color
+	// Logo, when non-nil, is composited over the centre; requires PNG
+	// output and forces high error correction.
+	Logo image.Image
+	// Format is one of the Format constants (FormatPNG when empty).
+	Format string
+}
+
+// Render produces a QR code for content in the requested format.
+func Render(content string, opts RenderOptions) ([]byte, string, error) {
+	level := qrcode.Medium
+	if opts.Logo != nil {
+		// The logo destroys modules; only high error correction can
+		// absorb that.
+		level = qrcode.Highest
+	}
+	q, err := qrcode.New(content, level)
+	if err != nil {
+		return nil, "", fmt.Errorf("qr: encoding %q: %w", content, err)
+	}
+	if opts.Foreground != nil {
+		q.ForegroundColor = opts.Foreground
+	}
+	if opts.Background != nil {
+		q.BackgroundColor = opts.Background
+	}
+	size := opts.Size
+	if size <= 0 {
+		size = defaultSize
+	}
+
+	switch opts.Format {
+	case "", FormatPNG:
+		out, err := renderPNG(q, size, opts.Logo)
+		return out, "image/png", err
+	case FormatSVG:
+		if opts.Logo != nil {
+			return nil, "", fmt.Errorf("qr: logo overlay requires png output")
+		}
+		return renderVector(q, svgTemplate), "image/svg+xml", nil
+	case FormatEPS:
+		if opts.Logo != nil {
+			return nil, "", fmt.Errorf("qr: logo overlay requires png output")
+		}
+		return renderVector(q, epsTemplate), "application/postscript", nil
+	default:
+		return nil, "", fmt.Errorf("qr: unknown format %q", opts.Format)
+	}
+}
+
+func renderPNG(q *qrcode.QRCode, size int, logo image.Image) ([]byte, error) {
+	img := q.Image(size)
+	if logo != nil {
+		bounds := img.Bounds()
+		maxEdge := int(float64(bounds.Dx()) * maxLogoFraction)
+		lb := logo.Bounds()
+		if lb.Dx() > maxEdge || lb.Dy() > maxEdge {
+			return nil, fmt.Errorf("qr: logo %dx%d exceeds %dpx limit for a %dpx code; error correction cannot absorb it",
+				lb.Dx(), lb.Dy(), maxEdge, bounds.Dx())
+		}
+		canvas := image.NewRGBA(bounds)
+		draw.Draw(canvas, bounds, img, bounds.Min, draw.Src)
+		offset := image.Pt(
+			bounds.Min.X+(bounds.Dx()-lb.Dx())/2,
+			bounds.Min.Y+(bounds.Dy()-lb.Dy())/2,
+		)
+		draw.Draw(canvas, image.Rectangle{Min: offset, Max: offset.Add(lb.Size())},
+			logo, lb.Min, draw.Over)
+		img = canvas
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("qr: encoding png: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// vectorTemplate holds the per-format boilerplate for module-grid output.
+type vectorTemplate struct {
+	header func(modules int) string
+	module func(x, y int) string
+	footer string
+}
+
+var svgTemplate = vectorTemplate{
+	header: func(n int) string {
+		return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+"\n", n, n)
+	},
+	module: func(x, y int) string {
+		return fmt.Sprintf(`<rect x="%d" y="%d" width="1" height="1"/>`+"\n", x, y)
+	},
+	footer: "</svg>\n",
+}
+
+var epsTemplate = vectorTemplate{
+	header: func(n int) string {
+		return fmt.Sprintf("%%!PS-Adobe-3.0 EPSF-3.0\n%%%%BoundingBox: 0 0 %d %d\n", n, n)
+	},
+	module: func(x, y int) string {
+		return fmt.Sprintf("newpath %d %d moveto 1 0 rlineto 0 1 rlineto -1 0 rlineto closepath fill\n", x, y)
+	},
+	footer: "showpage\n",
+}
+
+// renderVector emits the module grid through a format template; one unit
+// per module keeps print output resolution-independent.
+func renderVector(q *qrcode.QRCode, tmpl vectorTemplate) []byte {
+	bitmap := q.Bitmap()
+	var buf bytes.Buffer
+	buf.WriteString(tmpl.header(len(bitmap)))
+	for y, row := range bitmap {
+		for x, set := range row {
+			if set {
+				buf.WriteString(tmpl.module(x, y))
+			}
+		}
+	}
+	buf.WriteString(tmpl.footer)
+	return buf.Bytes()
+}
@@ -0,0 +1,40 @@
+package redirect
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+// bundlePage renders the public landing page for a bundle. Item links
+// route back through the item endpoint so clicks are counted. Shared by
+// the management API and the standalone redirect binary, which both serve
+// bundle codes.
+var bundlePage = template.Must(template.New("bundle").Parse(`<!doctype html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Bundle.Title}}</title>
+</head>
+<body>
+<h1>{{.Bundle.Title}}</h1>
+{{if .Bundle.Description}}<p>{{.Bundle.Description}}</p>{{end}}
+<ul>
+{{range $i, $item := .Bundle.Items}}
+<li><a href="/{{$.Bundle.Code}}/items/{{$i}}">{{$item.Title}}</a></li>
+{{end}}
+</ul>
+</body>
+</html>
+`))
+
+// ServeBundlePage renders the landing page for b.
+func ServeBundlePage(w http.ResponseWriter, b *models.Bundle) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := bundlePage.Execute(w, struct{ Bundle *models.Bundle }{b}); err != nil {
+		log.Printf("redirect: rendering bundle page: %v", err)
+	}
+}
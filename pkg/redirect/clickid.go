@@ -0,0 +1,30 @@
+package redirect
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+)
+
+// NewClickID mints the public click identifier appended to destinations
+// for conversion tracking; shared by every binary that serves redirects.
+func NewClickID() string {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
+
+// AppendQueryParam adds key=value to raw's query string, leaving raw
+// untouched on parse failure.
+func AppendQueryParam(raw, key, value string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	q := parsed.Query()
+	q.Set(key, value)
+	parsed.RawQuery = q.Encode()
+	return parsed.String()
+}
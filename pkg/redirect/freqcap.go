@@ -0,0 +1,78 @@
+package redirect
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+// Frequency capping is opt-in per link and entirely cookie-based: the
+// count lives in a signed first-party cookie, so there is no server-side
+// state and clearing cookies simply resets the visitor — acceptable for
+// promotion budgets, which only need to stop casual repeat redemption.
+
+func freqCookieName(code string) string { return "umfc_" + code }
+
+func freqSign(code, day string, count int) string {
+	mac := hmac.New(sha256.New, fallbackSecret)
+	fmt.Fprintf(mac, "freq:%s:%s:%d", code, day, count)
+	return hex.EncodeToString(mac.Sum(nil)[:12])
+}
+
+// readFreqCookie returns today's verified redirect count for the link,
+// zero for missing, tampered or stale-day cookies.
+func readFreqCookie(r *http.Request, code, day string) int {
+	cookie, err := r.Cookie(freqCookieName(code))
+	if err != nil {
+		return 0
+	}
+	parts := strings.Split(cookie.Value, ".")
+	if len(parts) != 3 || parts[1] != day {
+		return 0
+	}
+	var count int
+	if _, err := fmt.Sscanf(parts[0], "%d", &count); err != nil || count < 0 {
+		return 0
+	}
+	if !hmac.Equal([]byte(parts[2]), []byte(freqSign(code, day, count))) {
+		return 0
+	}
+	return count
+}
+
+// ApplyFrequencyCap enforces the link's per-visitor daily cap. It returns
+// false when the visitor is over the cap and the response has already
+// been written (fallback redirect, or 404 when none is configured);
+// otherwise it bumps the signed cookie and returns true.
+func ApplyFrequencyCap(w http.ResponseWriter, r *http.Request, u *models.URL) bool {
+	cap := u.Redirect.FrequencyCap
+	if cap <= 0 || len(fallbackSecret) == 0 {
+		return true
+	}
+	day := time.Now().UTC().Format("2006-01-02")
+	count := readFreqCookie(r, u.Code, day)
+	if count >= cap {
+		if u.Redirect.FrequencyFallbackURL != "" {
+			http.Redirect(w, r, u.Redirect.FrequencyFallbackURL, http.StatusFound)
+		} else {
+			http.Error(w, "link limit reached", http.StatusNotFound)
+		}
+		return false
+	}
+	count++
+	http.SetCookie(w, &http.Cookie{
+		Name:     freqCookieName(u.Code),
+		Value:    fmt.Sprintf("%d.%s.%s", count, day, freqSign(u.Code, day, count)),
+		Path:     "/" + u.Code,
+		Expires:  time.Now().UTC().AddDate(0, 0, 1),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return true
+}
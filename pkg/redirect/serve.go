@@ -0,0 +1,267 @@
+// Package redirect renders redirect responses — plain 30x, dereferrer
+// interstitials and app deep-link pages — shared by the shortener's
+// management binary and the standalone redirect service.
+package redirect
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+	"github.com/ashtonholgate/url-minifier/pkg/useragent"
+)
+
+// dereferrerPage is the interstitial served for strip-referrer links: the
+// meta refresh plus no-referrer policy means the destination sees neither
+// the shortener nor the page that embedded the link.
+var dereferrerPage = template.Must(template.New("dereferrer").Parse(`<!doctype html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="referrer" content="no-referrer">
+<meta http-equiv="refresh" content="0;url={{.}}">
+<title>Redirecting…</title>
+</head>
+<body>
+<p>Redirecting to <a href="{{.}}" rel="noreferrer noopener">your destination</a>…</p>
+</body>
+</html>
+`))
+
+// deepLinkPage attempts to open the app and falls back to the HTTP
+// destination after a short grace period if the app did not take over.
+var deepLinkPage = template.Must(template.New("deeplink").Parse(`<!doctype html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Opening app…</title>
+</head>
+<body>
+<p>Opening the app… <a href="{{.Fallback}}">Continue in browser</a></p>
+<script>
+window.location = {{.AppURI}};
+setTimeout(function () { window.location = {{.Fallback}}; }, 1500);
+</script>
+</body>
+</html>
+`))
+
+// fallbackPage is the HTML alternative to a 30x: meta refresh for plain
+// renderers, JS navigation for webviews that ignore the refresh, and a
+// plain link as the last resort.
+var fallbackPage = template.Must(template.New("fallback").Parse(`<!doctype html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="0;url={{.}}">
+<title>Redirecting…</title>
+<script>window.location.replace({{.}});</script>
+</head>
+<body>
+<p><a href="{{.}}">Continue to your destination</a></p>
+</body>
+</html>
+`))
+
+// fallbackSecret signs the no30x query parameter; set once at startup via
+// SetFallbackSecret.
+var fallbackSecret []byte
+
+// SetFallbackSecret enables the signed per-request HTML fallback
+// parameter.
+func SetFallbackSecret(secret []byte) { fallbackSecret = secret }
+
+// FallbackToken returns the value callers append as ?no30x= to force the
+// HTML fallback for one link; signing stops third parties from switching
+// arbitrary links away from cacheable 30x responses.
+func FallbackToken(code string) string {
+	mac := hmac.New(sha256.New, fallbackSecret)
+	mac.Write([]byte("no30x:" + code))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// wantsHTMLFallback reports whether this response should be the HTML page
+// instead of a 30x: either the link opted in, or the request carries a
+// valid signed no30x parameter.
+func wantsHTMLFallback(r *http.Request, u *models.URL) bool {
+	if u.Redirect.HTMLFallback {
+		return true
+	}
+	token := r.URL.Query().Get("no30x")
+	if token == "" || len(fallbackSecret) == 0 {
+		return false
+	}
+	return hmac.Equal([]byte(token), []byte(FallbackToken(u.Code)))
+}
+
+// visitor OS values detected from the User-Agent.
+const (
+	osIOS     = "ios"
+	osAndroid = "android"
+	osOther   = "other"
+)
+
+// detectOS classifies the visitor's platform from the User-Agent header.
+// Deliberately coarse: we only pick between app URIs, nothing more.
+func detectOS(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	switch {
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"), strings.Contains(ua, "ipod"):
+		return osIOS
+	case strings.Contains(ua, "android"):
+		return osAndroid
+	}
+	return osOther
+}
+
+// appURIFor picks the app URI for the visitor's platform, or "" when the
+// link has nothing suitable and the plain redirect should be used.
+func appURIFor(d *models.DeepLinkOptions, visitorOS string) string {
+	switch visitorOS {
+	case osIOS:
+		if d.IOSAppURI != "" {
+			return d.IOSAppURI
+		}
+	case osAndroid:
+		if d.AndroidAppURI != "" {
+			return d.AndroidAppURI
+		}
+	default:
+		// Desktop browsers get the HTTP fallback directly.
+		return ""
+	}
+	return d.AppURI
+}
+
+// pickLanguageTarget matches the Accept-Language header against the link's
+// language targets, trying exact tag-region matches ("pt-br") before
+// primary tags ("pt"), in the order the visitor listed them. Returns "" to
+// use the default destination.
+func pickLanguageTarget(targets map[string]string, acceptLanguage string) string {
+	if len(targets) == 0 || acceptLanguage == "" {
+		return ""
+	}
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag := strings.ToLower(strings.TrimSpace(strings.SplitN(part, ";", 2)[0]))
+		if tag == "" || tag == "*" {
+			continue
+		}
+		if dest, ok := targets[tag]; ok {
+			return dest
+		}
+		if primary := strings.SplitN(tag, "-", 2)[0]; primary != tag {
+			if dest, ok := targets[primary]; ok {
+				return dest
+			}
+		}
+	}
+	return ""
+}
+
+// previewPage serves Open Graph and Twitter Card tags to unfurl bots so
+// chat platforms show the destination's real title and image. The body
+// links onward for bots that follow through.
+var previewPage = template.Must(template.New("preview").Parse(`<!doctype html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<meta property="og:title" content="{{.Title}}">
+{{if .Description}}<meta property="og:description" content="{{.Description}}">{{end}}
+{{if .Image}}<meta property="og:image" content="{{.Image}}">{{end}}
+<meta property="og:url" content="{{.URL}}">
+<meta name="twitter:card" content="summary{{if .Image}}_large_image{{end}}">
+</head>
+<body>
+<p><a href="{{.URL}}">{{.Title}}</a></p>
+</body>
+</html>
+`))
+
+// serveUnfurlPreview renders the stored destination metadata for a
+// preview bot; returns false when the link has none and the normal
+// redirect should proceed (most platforms follow it and unfurl the
+// destination themselves).
+func serveUnfurlPreview(w http.ResponseWriter, u *models.URL) bool {
+	if u.Meta == nil {
+		return false
+	}
+	title := u.Meta.Title
+	if title == "" {
+		title = u.LongURL
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err := previewPage.Execute(w, struct {
+		Title, Description, Image, URL string
+	}{title, u.Meta.Description, u.Meta.Image, u.LongURL})
+	if err != nil {
+		log.Printf("redirect: rendering preview page: %v", err)
+	}
+	return true
+}
+
+// Serve sends the visitor to u's destination honouring the link's redirect
+// options, using 301 as the final status.
+func Serve(w http.ResponseWriter, r *http.Request, u *models.URL) {
+	ServeWithStatus(w, r, u, http.StatusMovedPermanently)
+}
+
+// ServeWithStatus is Serve with the final redirect status chosen by the
+// caller, for domains configured to use 302 instead of 301. Interstitial
+// modes are unaffected by the status.
+func ServeWithStatus(w http.ResponseWriter, r *http.Request, u *models.URL, status int) {
+	if status != http.StatusFound {
+		status = http.StatusMovedPermanently
+	}
+	for name, value := range u.Redirect.CustomHeaders {
+		w.Header().Set(name, value)
+	}
+	if useragent.IsUnfurlBot(r.UserAgent()) && serveUnfurlPreview(w, u) {
+		return
+	}
+	if dest := pickLanguageTarget(u.LanguageTargets, r.Header.Get("Accept-Language")); dest != "" {
+		localized := *u
+		localized.LongURL = dest
+		u = &localized
+		// Localised responses must not be cached across languages.
+		w.Header().Add("Vary", "Accept-Language")
+	}
+	if u.Redirect.ReferrerPolicy != "" {
+		w.Header().Set("Referrer-Policy", u.Redirect.ReferrerPolicy)
+	}
+	if u.DeepLink != nil {
+		if appURI := appURIFor(u.DeepLink, detectOS(r.UserAgent())); appURI != "" {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Header().Set("Cache-Control", "no-store")
+			err := deepLinkPage.Execute(w, struct{ AppURI, Fallback string }{appURI, u.LongURL})
+			if err != nil {
+				log.Printf("redirect: rendering deep link page: %v", err)
+			}
+			return
+		}
+		http.Redirect(w, r, u.LongURL, http.StatusFound)
+		return
+	}
+	if wantsHTMLFallback(r, u) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		if err := fallbackPage.Execute(w, u.LongURL); err != nil {
+			log.Printf("redirect: rendering fallback page: %v", err)
+		}
+		return
+	}
+	if u.Redirect.StripReferrer {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		if err := dereferrerPage.Execute(w, u.LongURL); err != nil {
+			log.Printf("redirect: rendering dereferrer page: %v", err)
+		}
+		return
+	}
+	http.Redirect(w, r, u.LongURL, status)
+}
@@ -0,0 +1,136 @@
+package redirect
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+func TestPickLanguageTarget(t *testing.T) {
+	targets := map[string]string{
+		"de":    "https://example.de/",
+		"pt-br": "https://example.com.br/",
+	}
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"de-DE,de;q=0.9,en;q=0.8", "https://example.de/"},
+		{"pt-BR,pt;q=0.9", "https://example.com.br/"},
+		{"en-US,en;q=0.9", ""},
+		{"", ""},
+		{"*", ""},
+	}
+	for _, tt := range tests {
+		if got := pickLanguageTarget(targets, tt.accept); got != tt.want {
+			t.Errorf("pickLanguageTarget(%q) = %q, want %q", tt.accept, got, tt.want)
+		}
+	}
+}
+
+func TestServeRedirectStatuses(t *testing.T) {
+	u := &models.URL{Code: "abc", LongURL: "https://example.com/dest"}
+
+	rec := httptest.NewRecorder()
+	Serve(rec, httptest.NewRequest(http.MethodGet, "/abc", nil), u)
+	if rec.Code != http.StatusMovedPermanently {
+		t.Errorf("Serve: got %d", rec.Code)
+	}
+	if loc := rec.Header().Get("Location"); loc != u.LongURL {
+		t.Errorf("Location: got %q", loc)
+	}
+
+	rec = httptest.NewRecorder()
+	ServeWithStatus(rec, httptest.NewRequest(http.MethodGet, "/abc", nil), u, http.StatusFound)
+	if rec.Code != http.StatusFound {
+		t.Errorf("ServeWithStatus(302): got %d", rec.Code)
+	}
+}
+
+func TestServeUnfurlBotGetsPreview(t *testing.T) {
+	u := &models.URL{
+		Code:    "abc",
+		LongURL: "https://example.com/dest",
+		Meta:    &models.LinkMeta{Title: "Real Title", Description: "desc"},
+	}
+	req := httptest.NewRequest(http.MethodGet, "/abc", nil)
+	req.Header.Set("User-Agent", "Slackbot-LinkExpanding 1.0")
+	rec := httptest.NewRecorder()
+	Serve(rec, req, u)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("preview status: got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	if !containsAll(body, `og:title`, "Real Title") {
+		t.Errorf("preview body missing metadata: %s", body)
+	}
+}
+
+func TestFrequencyCap(t *testing.T) {
+	SetFallbackSecret([]byte("test-secret"))
+	u := &models.URL{
+		Code:    "promo",
+		LongURL: "https://example.com/offer",
+		Redirect: models.RedirectOptions{
+			FrequencyCap:         2,
+			FrequencyFallbackURL: "https://example.com/sorry",
+		},
+	}
+	var cookies []*http.Cookie
+	serve := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/promo", nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		rec := httptest.NewRecorder()
+		if ApplyFrequencyCap(rec, req, u) {
+			Serve(rec, req, u)
+		}
+		cookies = append(cookies[:0], rec.Result().Cookies()...)
+		return rec
+	}
+
+	for i := 0; i < 2; i++ {
+		rec := serve()
+		if rec.Header().Get("Location") != u.LongURL {
+			t.Fatalf("visit %d: got %q", i+1, rec.Header().Get("Location"))
+		}
+	}
+	rec := serve()
+	if rec.Header().Get("Location") != "https://example.com/sorry" {
+		t.Errorf("over cap: got %q", rec.Header().Get("Location"))
+	}
+}
+
+func TestFrequencyCapIgnoresTamperedCookie(t *testing.T) {
+	SetFallbackSecret([]byte("test-secret"))
+	u := &models.URL{
+		Code:     "promo2",
+		LongURL:  "https://example.com/offer",
+		Redirect: models.RedirectOptions{FrequencyCap: 1},
+	}
+	req := httptest.NewRequest(http.MethodGet, "/promo2", nil)
+	req.AddCookie(&http.Cookie{Name: "umfc_promo2", Value: "99.2026-01-01.deadbeef"})
+	rec := httptest.NewRecorder()
+	if !ApplyFrequencyCap(rec, req, u) {
+		t.Error("tampered cookie enforced the cap")
+	}
+}
+
+func containsAll(s string, subs ...string) bool {
+	for _, sub := range subs {
+		found := false
+		for i := 0; i+len(sub) <= len(s); i++ {
+			if s[i:i+len(sub)] == sub {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
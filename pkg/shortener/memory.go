@@ -0,0 +1,53 @@
+package shortener
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+// MemoryStore is an in-memory Store for embedding and tests. Safe for
+// concurrent use.
+type MemoryStore struct {
+	mu   sync.RWMutex
+	urls map[string]models.URL
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{urls: make(map[string]models.URL)}
+}
+
+// Put implements Store.
+func (m *MemoryStore) Put(_ context.Context, u *models.URL) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.urls[u.Code]; ok {
+		return ErrTaken
+	}
+	m.urls[u.Code] = *u
+	return nil
+}
+
+// Get implements Store.
+func (m *MemoryStore) Get(_ context.Context, code string) (*models.URL, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	u, ok := m.urls[code]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &u, nil
+}
+
+// Delete implements Store.
+func (m *MemoryStore) Delete(_ context.Context, code string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.urls[code]; !ok {
+		return ErrNotFound
+	}
+	delete(m.urls, code)
+	return nil
+}
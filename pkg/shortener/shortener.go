@@ -0,0 +1,119 @@
+// Package shortener is the embeddable core of url-minifier: code
+// generation and resolution with pluggable storage, none of the service
+// scaffolding (auth, plans, HTTP). Internal tools that just need to mint
+// and resolve short links in-process use this instead of running the
+// microservice.
+package shortener
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+// Storage errors. MemoryStore and any custom Store implementation return
+// these so Shortener can retry collisions.
+var (
+	ErrNotFound = errors.New("shortener: code not found")
+	ErrTaken    = errors.New("shortener: code already taken")
+)
+
+// Store is the minimal storage contract for embedding.
+type Store interface {
+	Put(ctx context.Context, u *models.URL) error
+	Get(ctx context.Context, code string) (*models.URL, error)
+	Delete(ctx context.Context, code string) error
+}
+
+// Shortener mints and resolves short links against a Store.
+type Shortener struct {
+	store      Store
+	codeLength int
+}
+
+// Option customises a Shortener.
+type Option func(*Shortener)
+
+// WithCodeLength overrides the generated code length (default 7).
+func WithCodeLength(n int) Option {
+	return func(s *Shortener) {
+		if n > 0 {
+			s.codeLength = n
+		}
+	}
+}
+
+// New returns a Shortener over store.
+func New(store Store, opts ...Option) *Shortener {
+	s := &Shortener{store: store, codeLength: 7}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+const alphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// maxAttempts bounds collision retries.
+const maxAttempts = 5
+
+// Shorten stores longURL under a fresh code and returns the record.
+func (s *Shortener) Shorten(ctx context.Context, longURL string) (*models.URL, error) {
+	parsed, err := url.Parse(longURL)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, fmt.Errorf("shortener: %q is not an absolute http(s) URL", longURL)
+	}
+	now := time.Now().UTC()
+	u := &models.URL{LongURL: longURL, CreatedAt: now, UpdatedAt: now}
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		code, err := s.generate()
+		if err != nil {
+			return nil, err
+		}
+		u.Code = code
+		err = s.store.Put(ctx, u)
+		if err == nil {
+			return u, nil
+		}
+		if !errors.Is(err, ErrTaken) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("shortener: exhausted %d attempts to find a free code", maxAttempts)
+}
+
+// ShortenAs stores longURL under a caller-chosen code.
+func (s *Shortener) ShortenAs(ctx context.Context, code, longURL string) (*models.URL, error) {
+	now := time.Now().UTC()
+	u := &models.URL{Code: code, LongURL: longURL, CreatedAt: now, UpdatedAt: now}
+	if err := s.store.Put(ctx, u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// Resolve returns the record for code.
+func (s *Shortener) Resolve(ctx context.Context, code string) (*models.URL, error) {
+	return s.store.Get(ctx, code)
+}
+
+// Delete removes the record for code.
+func (s *Shortener) Delete(ctx context.Context, code string) error {
+	return s.store.Delete(ctx, code)
+}
+
+func (s *Shortener) generate() (string, error) {
+	buf := make([]byte, s.codeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("shortener: reading random bytes: %w", err)
+	}
+	for i, b := range buf {
+		buf[i] = alphabet[int(b)%len(alphabet)]
+	}
+	return string(buf), nil
+}
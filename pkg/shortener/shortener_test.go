@@ -0,0 +1,75 @@
+package shortener
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestShortenAndResolve(t *testing.T) {
+	s := New(NewMemoryStore())
+	ctx := context.Background()
+
+	u, err := s.Shorten(ctx, "https://example.com/page")
+	if err != nil {
+		t.Fatalf("Shorten: %v", err)
+	}
+	if len(u.Code) != 7 {
+		t.Errorf("default code length: got %d", len(u.Code))
+	}
+	got, err := s.Resolve(ctx, u.Code)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if got.LongURL != "https://example.com/page" {
+		t.Errorf("resolve: got %q", got.LongURL)
+	}
+}
+
+func TestShortenRejectsBadURLs(t *testing.T) {
+	s := New(NewMemoryStore())
+	for _, bad := range []string{"", "not-a-url", "ftp://example.com/x", "/relative"} {
+		if _, err := s.Shorten(context.Background(), bad); err == nil {
+			t.Errorf("Shorten(%q) accepted", bad)
+		}
+	}
+}
+
+func TestShortenAsConflict(t *testing.T) {
+	s := New(NewMemoryStore())
+	ctx := context.Background()
+	if _, err := s.ShortenAs(ctx, "docs", "https://example.com/docs"); err != nil {
+		t.Fatalf("ShortenAs: %v", err)
+	}
+	if _, err := s.ShortenAs(ctx, "docs", "https://example.com/other"); !errors.Is(err, ErrTaken) {
+		t.Errorf("duplicate alias: want ErrTaken, got %v", err)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	s := New(NewMemoryStore())
+	ctx := context.Background()
+	if _, err := s.ShortenAs(ctx, "bye", "https://example.com/bye"); err != nil {
+		t.Fatalf("ShortenAs: %v", err)
+	}
+	if err := s.Delete(ctx, "bye"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Resolve(ctx, "bye"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("after delete: want ErrNotFound, got %v", err)
+	}
+	if err := s.Delete(ctx, "bye"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("double delete: want ErrNotFound, got %v", err)
+	}
+}
+
+func TestWithCodeLength(t *testing.T) {
+	s := New(NewMemoryStore(), WithCodeLength(12))
+	u, err := s.Shorten(context.Background(), "https://example.com/long")
+	if err != nil {
+		t.Fatalf("Shorten: %v", err)
+	}
+	if len(u.Code) != 12 {
+		t.Errorf("code length: got %d, want 12", len(u.Code))
+	}
+}
@@ -0,0 +1,66 @@
+// Package uniques estimates unique visitors per link with Redis
+// HyperLogLogs: one HLL per code per UTC day, merged at query time. The
+// writer (redirect path) and reader (analytics service) share this package
+// so the key scheme has a single owner.
+package uniques
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// retention bounds how far back unique-visitor estimates reach; older
+// daily HLLs expire.
+const retention = 90 * 24 * time.Hour
+
+func key(code, day string) string { return "uniq:" + code + ":" + day }
+
+func day(t time.Time) string { return t.UTC().Format("2006-01-02") }
+
+// Add registers a visitor (identified by IP) against the code's HLL for
+// t's day.
+func Add(ctx context.Context, rdb *redis.Client, code, visitor string, t time.Time) error {
+	if visitor == "" {
+		return nil
+	}
+	k := key(code, day(t))
+	pipe := rdb.Pipeline()
+	pipe.PFAdd(ctx, k, visitor)
+	pipe.Expire(ctx, k, retention)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("adding visitor to hll: %w", err)
+	}
+	return nil
+}
+
+// Count estimates the unique visitors for code between from and to
+// (inclusive), merging the daily HLLs into a scratch key.
+func Count(ctx context.Context, rdb *redis.Client, code string, from, to time.Time) (int64, error) {
+	var keys []string
+	for d := from.UTC().Truncate(24 * time.Hour); !d.After(to.UTC()); d = d.AddDate(0, 0, 1) {
+		keys = append(keys, key(code, day(d)))
+	}
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	if len(keys) == 1 {
+		n, err := rdb.PFCount(ctx, keys[0]).Result()
+		if err != nil {
+			return 0, fmt.Errorf("counting hll: %w", err)
+		}
+		return n, nil
+	}
+	scratch := fmt.Sprintf("uniq:merge:%s:%d", code, time.Now().UnixNano())
+	if err := rdb.PFMerge(ctx, scratch, keys...).Err(); err != nil {
+		return 0, fmt.Errorf("merging hlls: %w", err)
+	}
+	defer rdb.Del(ctx, scratch)
+	n, err := rdb.PFCount(ctx, scratch).Result()
+	if err != nil {
+		return 0, fmt.Errorf("counting merged hll: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,92 @@
+// Package useragent classifies User-Agent strings for device analytics.
+// Heuristic string matching, not a full parser: analytics needs stable
+// coarse buckets (browser family, OS, device class), not version-exact
+// identification.
+package useragent
+
+import "strings"
+
+// Device classes.
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+)
+
+// Info is the parsed classification of a User-Agent.
+type Info struct {
+	Browser string `json:"browser"`
+	OS      string `json:"os"`
+	Device  string `json:"device"`
+}
+
+// botMarkers identify crawlers and link checkers. Checked first because
+// bots impersonate browsers in the rest of the string.
+var botMarkers = []string{"bot", "crawler", "spider", "slurp", "curl/", "wget/", "python-requests", "facebookexternalhit", "whatsapp", "telegrambot", "preview"}
+
+// unfurlMarkers identify the link-preview bots of chat and social
+// platforms specifically, a subset of botMarkers that deserves metadata
+// rather than a redirect.
+var unfurlMarkers = []string{"slackbot", "twitterbot", "facebookexternalhit", "discordbot", "telegrambot", "linkedinbot", "whatsapp", "skypeuripreview"}
+
+// IsUnfurlBot reports whether ua belongs to a chat-platform preview bot.
+func IsUnfurlBot(ua string) bool {
+	lower := strings.ToLower(ua)
+	for _, marker := range unfurlMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse classifies ua. Unknown fields come back as "other".
+func Parse(ua string) Info {
+	lower := strings.ToLower(ua)
+	info := Info{Browser: "other", OS: "other", Device: DeviceDesktop}
+
+	for _, marker := range botMarkers {
+		if strings.Contains(lower, marker) {
+			info.Device = DeviceBot
+			info.Browser = "bot"
+			return info
+		}
+	}
+
+	switch {
+	case strings.Contains(lower, "ipad"):
+		info.OS, info.Device = "ios", DeviceTablet
+	case strings.Contains(lower, "iphone"), strings.Contains(lower, "ipod"):
+		info.OS, info.Device = "ios", DeviceMobile
+	case strings.Contains(lower, "android"):
+		info.OS = "android"
+		if strings.Contains(lower, "mobile") {
+			info.Device = DeviceMobile
+		} else {
+			info.Device = DeviceTablet
+		}
+	case strings.Contains(lower, "windows"):
+		info.OS = "windows"
+	case strings.Contains(lower, "mac os x"), strings.Contains(lower, "macintosh"):
+		info.OS = "macos"
+	case strings.Contains(lower, "linux"):
+		info.OS = "linux"
+	}
+
+	// Order matters: Chrome UAs contain "safari", Edge UAs contain
+	// "chrome", Opera UAs contain both.
+	switch {
+	case strings.Contains(lower, "edg/"), strings.Contains(lower, "edge/"):
+		info.Browser = "edge"
+	case strings.Contains(lower, "opr/"), strings.Contains(lower, "opera"):
+		info.Browser = "opera"
+	case strings.Contains(lower, "firefox/"):
+		info.Browser = "firefox"
+	case strings.Contains(lower, "chrome/"), strings.Contains(lower, "crios/"):
+		info.Browser = "chrome"
+	case strings.Contains(lower, "safari/"):
+		info.Browser = "safari"
+	}
+	return info
+}
@@ -0,0 +1,71 @@
+package useragent
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		ua   string
+		want Info
+	}{
+		{
+			name: "chrome on windows",
+			ua:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			want: Info{Browser: "chrome", OS: "windows", Device: DeviceDesktop},
+		},
+		{
+			name: "safari on iphone",
+			ua:   "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1",
+			want: Info{Browser: "safari", OS: "ios", Device: DeviceMobile},
+		},
+		{
+			name: "chrome on android phone",
+			ua:   "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36",
+			want: Info{Browser: "chrome", OS: "android", Device: DeviceMobile},
+		},
+		{
+			name: "android tablet",
+			ua:   "Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			want: Info{Browser: "chrome", OS: "android", Device: DeviceTablet},
+		},
+		{
+			name: "edge claims chrome and safari",
+			ua:   "Mozilla/5.0 (Windows NT 10.0) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.0.0",
+			want: Info{Browser: "edge", OS: "windows", Device: DeviceDesktop},
+		},
+		{
+			name: "googlebot impersonating a browser",
+			ua:   "Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko; compatible; Googlebot/2.1; +http://www.google.com/bot.html) Chrome/120.0.0.0 Safari/537.36",
+			want: Info{Browser: "bot", OS: "other", Device: DeviceBot},
+		},
+		{
+			name: "curl",
+			ua:   "curl/8.4.0",
+			want: Info{Browser: "bot", OS: "other", Device: DeviceBot},
+		},
+		{
+			name: "empty",
+			ua:   "",
+			want: Info{Browser: "other", OS: "other", Device: DeviceDesktop},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.ua); got != tt.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.ua, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsUnfurlBot(t *testing.T) {
+	if !IsUnfurlBot("Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)") {
+		t.Error("slackbot not detected")
+	}
+	if !IsUnfurlBot("Twitterbot/1.0") {
+		t.Error("twitterbot not detected")
+	}
+	if IsUnfurlBot("Mozilla/5.0 (Windows NT 10.0) Chrome/120.0") {
+		t.Error("regular browser classified as unfurl bot")
+	}
+}
@@ -0,0 +1,188 @@
+// Package anomaly flags suspicious click patterns — sudden spikes,
+// single-IP concentration, datacenter dominance — so the stats API can
+// warn about inflated ranges instead of silently reporting them.
+package anomaly
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Detection thresholds. Deliberately conservative: a false "fraud"
+// warning on a customer dashboard is worse than a missed one.
+const (
+	// minClicksForDetection skips links with too little traffic to
+	// reason about.
+	minClicksForDetection = 100
+	// spikeFactor flags an hour exceeding this multiple of the trailing
+	// daily average for the same hour length.
+	spikeFactor = 10
+	// concentrationShare flags a window where one IP (or datacenter
+	// traffic) produced more than this share of clicks.
+	concentrationShare = 0.6
+)
+
+// Flag annotates a suspicious analytics range on a link.
+type Flag struct {
+	Code      string    `bson:"code" json:"code"`
+	OrgID     string    `bson:"org_id" json:"org_id"`
+	Type      string    `bson:"type" json:"type"`
+	From      time.Time `bson:"from" json:"from"`
+	To        time.Time `bson:"to" json:"to"`
+	Details   string    `bson:"details" json:"details"`
+	FlaggedAt time.Time `bson:"flagged_at" json:"flagged_at"`
+}
+
+// Detector runs the hourly sweep.
+type Detector struct {
+	clicks *mongo.Collection
+	flags  *mongo.Collection
+}
+
+// NewDetector returns a Detector over db's clicks and anomaly_flags
+// collections.
+func NewDetector(db *mongo.Database) *Detector {
+	return &Detector{
+		clicks: db.Collection("clicks"),
+		flags:  db.Collection("anomaly_flags"),
+	}
+}
+
+// Start sweeps the previous hour every hour until ctx is cancelled.
+func (d *Detector) Start(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := d.SweepHour(ctx, time.Now().UTC().Truncate(time.Hour).Add(-time.Hour)); err != nil {
+					log.Printf("anomaly: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// hourStats is one link's aggregate for the swept hour.
+type hourStats struct {
+	Code       string `bson:"_id"`
+	OrgID      string `bson:"org_id"`
+	Clicks     int64  `bson:"clicks"`
+	TopIP      int64  `bson:"top_ip"`
+	Datacenter int64  `bson:"datacenter"`
+}
+
+// SweepHour analyses the hour starting at from.
+func (d *Detector) SweepHour(ctx context.Context, from time.Time) error {
+	to := from.Add(time.Hour)
+	cur, err := d.clicks.Aggregate(ctx, bson.A{
+		bson.M{"$match": bson.M{
+			"at":    bson.M{"$gte": from, "$lt": to},
+			"event": bson.M{"$ne": "impression"},
+		}},
+		bson.M{"$group": bson.M{
+			"_id":    bson.M{"code": "$code", "ip": "$ip"},
+			"org_id": bson.M{"$first": "$org_id"},
+			"clicks": bson.M{"$sum": 1},
+			"datacenter": bson.M{"$sum": bson.M{"$cond": bson.A{
+				bson.M{"$eq": bson.A{"$network", "datacenter"}}, 1, 0}}},
+		}},
+		bson.M{"$group": bson.M{
+			"_id":        "$_id.code",
+			"org_id":     bson.M{"$first": "$org_id"},
+			"clicks":     bson.M{"$sum": "$clicks"},
+			"top_ip":     bson.M{"$max": "$clicks"},
+			"datacenter": bson.M{"$sum": "$datacenter"},
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("aggregating hour: %w", err)
+	}
+	defer cur.Close(ctx)
+	for cur.Next(ctx) {
+		var stats hourStats
+		if err := cur.Decode(&stats); err != nil {
+			return fmt.Errorf("decoding hour stats: %w", err)
+		}
+		if stats.Clicks < minClicksForDetection {
+			continue
+		}
+		d.evaluate(ctx, from, to, stats)
+	}
+	return cur.Err()
+}
+
+func (d *Detector) evaluate(ctx context.Context, from, to time.Time, stats hourStats) {
+	if avg := d.trailingHourlyAverage(ctx, stats.Code, from); avg > 0 &&
+		float64(stats.Clicks) > spikeFactor*avg {
+		d.flag(ctx, stats, from, to, "spike",
+			fmt.Sprintf("%d clicks vs trailing hourly average %.1f", stats.Clicks, avg))
+	}
+	if float64(stats.TopIP)/float64(stats.Clicks) > concentrationShare {
+		d.flag(ctx, stats, from, to, "single_ip_concentration",
+			fmt.Sprintf("top IP produced %d of %d clicks", stats.TopIP, stats.Clicks))
+	}
+	if float64(stats.Datacenter)/float64(stats.Clicks) > concentrationShare {
+		d.flag(ctx, stats, from, to, "datacenter_dominance",
+			fmt.Sprintf("%d of %d clicks from datacenter networks", stats.Datacenter, stats.Clicks))
+	}
+}
+
+// trailingHourlyAverage computes the link's mean hourly clicks over the
+// preceding day.
+func (d *Detector) trailingHourlyAverage(ctx context.Context, code string, before time.Time) float64 {
+	n, err := d.clicks.CountDocuments(ctx, bson.M{
+		"code":  code,
+		"event": bson.M{"$ne": "impression"},
+		"at":    bson.M{"$gte": before.Add(-24 * time.Hour), "$lt": before},
+	})
+	if err != nil {
+		log.Printf("anomaly: trailing average for %s: %v", code, err)
+		return 0
+	}
+	return float64(n) / 24
+}
+
+func (d *Detector) flag(ctx context.Context, stats hourStats, from, to time.Time, kind, details string) {
+	_, err := d.flags.InsertOne(ctx, Flag{
+		Code: stats.Code, OrgID: stats.OrgID, Type: kind,
+		From: from, To: to, Details: details,
+		FlaggedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		log.Printf("anomaly: flagging %s: %v", stats.Code, err)
+		return
+	}
+	log.Printf("anomaly: flagged %s: %s (%s)", stats.Code, kind, details)
+}
+
+// FlagsOverlapping returns flags for the org intersecting [from, to],
+// optionally narrowed to codes.
+func (d *Detector) FlagsOverlapping(ctx context.Context, orgID string, codes []string, from, to time.Time) ([]Flag, error) {
+	filter := bson.M{
+		"org_id": orgID,
+		"from":   bson.M{"$lte": to},
+		"to":     bson.M{"$gte": from},
+	}
+	if len(codes) > 0 {
+		filter["code"] = bson.M{"$in": codes}
+	}
+	cur, err := d.flags.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("listing anomaly flags: %w", err)
+	}
+	defer cur.Close(ctx)
+	var out []Flag
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, fmt.Errorf("decoding anomaly flags: %w", err)
+	}
+	return out, nil
+}
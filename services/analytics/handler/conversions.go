@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ashtonholgate/url-minifier/pkg/auth"
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/middleware"
+)
+
+// transparentGIF is the classic 1x1 tracking pixel.
+var transparentGIF = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00,
+	0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x01, 0x00,
+	0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+// RegisterConversionRoutes mounts the public conversion endpoints on root
+// (pixel and server postback are unauthenticated by nature — the click ID
+// is the credential) and the stats endpoint on the authenticated api
+// router.
+func (h *Handler) RegisterConversionRoutes(root *mux.Router, api *mux.Router) {
+	root.HandleFunc("/px/{click_id}.gif", h.conversionPixel).Methods(http.MethodGet)
+	root.HandleFunc("/api/v1/conversions", h.conversionPostback).Methods(http.MethodPost)
+	api.HandleFunc("/analytics/urls/{code}/conversions", h.conversionStats).Methods(http.MethodGet)
+	api.HandleFunc("/analytics/urls/{code}/variants", h.variantStats).Methods(http.MethodGet)
+}
+
+// variantStats reports per-arm exposures, conversions and rates for an
+// experiment link.
+func (h *Handler) variantStats(w http.ResponseWriter, r *http.Request) {
+	pr, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "unauthenticated"})
+		return
+	}
+	if err := auth.Authorize(pr, auth.PermAnalyticsRead, auth.Resource{OrgID: pr.OrgID}); err != nil {
+		writeJSON(w, http.StatusForbidden, errorResponse{Error: "forbidden"})
+		return
+	}
+	stats, err := h.clicks.VariantStatsForCode(r.Context(), mux.Vars(r)["code"], pr.OrgID)
+	if err != nil {
+		log.Printf("handler: variant stats: %v", err)
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "internal error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// conversionPixel records the conversion and always serves the pixel, so a
+// bad click ID cannot be probed from the image response.
+func (h *Handler) conversionPixel(w http.ResponseWriter, r *http.Request) {
+	clickID := mux.Vars(r)["click_id"]
+	if err := h.clicks.RecordConversion(r.Context(), clickID, 0); err != nil && !common.IsNotFound(err) {
+		log.Printf("handler: pixel conversion %s: %v", clickID, err)
+	}
+	w.Header().Set("Content-Type", "image/gif")
+	w.Header().Set("Cache-Control", "no-store, private")
+	w.Write(transparentGIF)
+}
+
+func (h *Handler) conversionPostback(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ClickID string  `json:"click_id"`
+		Value   float64 `json:"value,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ClickID == "" {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "click_id is required"})
+		return
+	}
+	if err := h.clicks.RecordConversion(r.Context(), req.ClickID, req.Value); err != nil {
+		if common.IsNotFound(err) {
+			writeJSON(w, http.StatusNotFound, errorResponse{Error: "unknown click_id"})
+			return
+		}
+		log.Printf("handler: postback conversion %s: %v", req.ClickID, err)
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "internal error"})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) conversionStats(w http.ResponseWriter, r *http.Request) {
+	pr, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "unauthenticated"})
+		return
+	}
+	if err := auth.Authorize(pr, auth.PermAnalyticsRead, auth.Resource{OrgID: pr.OrgID}); err != nil {
+		writeJSON(w, http.StatusForbidden, errorResponse{Error: "forbidden"})
+		return
+	}
+	stats, err := h.clicks.ConversionStatsForCode(r.Context(), mux.Vars(r)["code"], pr.OrgID)
+	if err != nil {
+		log.Printf("handler: conversion stats: %v", err)
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "internal error"})
+		return
+	}
+	// Round the rate for presentation; four decimals is plenty.
+	stats.Rate, _ = strconv.ParseFloat(strconv.FormatFloat(stats.Rate, 'f', 4, 64), 64)
+	writeJSON(w, http.StatusOK, stats)
+}
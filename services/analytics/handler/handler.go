@@ -0,0 +1,205 @@
+// Package handler exposes the analytics query API.
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/ashtonholgate/url-minifier/pkg/auth"
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/metering"
+	"github.com/ashtonholgate/url-minifier/pkg/middleware"
+	"github.com/ashtonholgate/url-minifier/pkg/uniques"
+	"github.com/ashtonholgate/url-minifier/services/analytics/anomaly"
+	"github.com/ashtonholgate/url-minifier/services/analytics/query"
+	"github.com/ashtonholgate/url-minifier/services/analytics/repository"
+)
+
+// Handler serves the analytics HTTP API.
+type Handler struct {
+	clicks  *repository.ClickRepository
+	meter   *metering.Meter
+	rdb     *redis.Client
+	anomaly *anomaly.Detector
+}
+
+// SetAnomalyDetector attaches fraud-flag lookups for query warnings.
+func (h *Handler) SetAnomalyDetector(d *anomaly.Detector) { h.anomaly = d }
+
+// New returns a Handler over the click repository, metering queries through
+// meter and reading unique-visitor HLLs from rdb.
+func New(clicks *repository.ClickRepository, meter *metering.Meter, rdb *redis.Client) *Handler {
+	return &Handler{clicks: clicks, meter: meter, rdb: rdb}
+}
+
+// Register mounts the analytics routes on the authenticated api router.
+func (h *Handler) Register(api *mux.Router) {
+	api.HandleFunc("/analytics/query", h.runQuery).Methods(http.MethodPost)
+	api.HandleFunc("/analytics/urls/{code}/uniques", h.uniqueVisitors).Methods(http.MethodGet)
+	api.HandleFunc("/analytics/domains/{domain}", h.domainStats).Methods(http.MethodGet)
+}
+
+// domainStats serves the per-domain roll-up (clicks, uniques,
+// impressions, top links) over ?from/?to (default last 30 days).
+func (h *Handler) domainStats(w http.ResponseWriter, r *http.Request) {
+	pr, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "unauthenticated"})
+		return
+	}
+	if err := auth.Authorize(pr, auth.PermAnalyticsRead, auth.Resource{OrgID: pr.OrgID}); err != nil {
+		writeJSON(w, http.StatusForbidden, errorResponse{Error: "forbidden"})
+		return
+	}
+	to := time.Now().UTC()
+	from := to.AddDate(0, 0, -30)
+	if v := r.URL.Query().Get("from"); v != "" {
+		if t, err := time.Parse("2006-01-02", v); err == nil {
+			from = t
+		}
+	}
+	if v := r.URL.Query().Get("to"); v != "" {
+		if t, err := time.Parse("2006-01-02", v); err == nil {
+			to = t
+		}
+	}
+	stats, err := h.clicks.DomainStatsFor(r.Context(), pr.OrgID, mux.Vars(r)["domain"], from, to)
+	if err != nil {
+		log.Printf("handler: domain stats: %v", err)
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "internal error"})
+		return
+	}
+	h.meter.Record(r.Context(), pr.OrgID, pr.UserID, metering.OpAnalyticsQuery)
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// uniqueVisitors returns the HyperLogLog estimate of distinct visitors for
+// a code over a day range (default: the last 30 days).
+func (h *Handler) uniqueVisitors(w http.ResponseWriter, r *http.Request) {
+	pr, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "unauthenticated"})
+		return
+	}
+	if err := auth.Authorize(pr, auth.PermAnalyticsRead, auth.Resource{OrgID: pr.OrgID}); err != nil {
+		writeJSON(w, http.StatusForbidden, errorResponse{Error: "forbidden"})
+		return
+	}
+	code := mux.Vars(r)["code"]
+	inOrg, err := h.clicks.CodeInOrg(r.Context(), code, pr.OrgID)
+	if err != nil {
+		log.Printf("handler: scoping code: %v", err)
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "internal error"})
+		return
+	}
+	if !inOrg {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "not found"})
+		return
+	}
+	// The daily sketches only advance at day boundaries, so the last full
+	// day is the freshness watermark for conditional polling.
+	watermark := time.Now().UTC().Truncate(24 * time.Hour)
+	w.Header().Set("Last-Modified", watermark.Format(http.TimeFormat))
+	if since := r.Header.Get("If-Modified-Since"); since != "" {
+		if t, err := http.ParseTime(since); err == nil && !watermark.After(t) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	to := time.Now().UTC()
+	from := to.AddDate(0, 0, -30)
+	if v := r.URL.Query().Get("from"); v != "" {
+		if t, err := time.Parse("2006-01-02", v); err == nil {
+			from = t
+		}
+	}
+	if v := r.URL.Query().Get("to"); v != "" {
+		if t, err := time.Parse("2006-01-02", v); err == nil {
+			to = t
+		}
+	}
+	n, err := uniques.Count(r.Context(), h.rdb, code, from, to)
+	if err != nil {
+		log.Printf("handler: counting uniques: %v", err)
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "internal error"})
+		return
+	}
+	h.meter.Record(r.Context(), pr.OrgID, pr.UserID, metering.OpAnalyticsQuery)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"code":            code,
+		"from":            from.Format("2006-01-02"),
+		"to":              to.Format("2006-01-02"),
+		"unique_visitors": n,
+	})
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("handler: encoding response: %v", err)
+	}
+}
+
+func (h *Handler) runQuery(w http.ResponseWriter, r *http.Request) {
+	pr, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "unauthenticated"})
+		return
+	}
+	if err := auth.Authorize(pr, auth.PermAnalyticsRead, auth.Resource{OrgID: pr.OrgID}); err != nil {
+		writeJSON(w, http.StatusForbidden, errorResponse{Error: "forbidden"})
+		return
+	}
+	var q query.Query
+	if err := json.NewDecoder(r.Body).Decode(&q); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "malformed body"})
+		return
+	}
+	if err := q.Validate(); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	rows, err := h.clicks.Run(r.Context(), pr.OrgID, &q)
+	if err != nil {
+		if errors.Is(err, common.ErrInvalidInput) {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+			return
+		}
+		log.Printf("handler: analytics query: %v", err)
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "internal error"})
+		return
+	}
+	h.meter.Record(r.Context(), pr.OrgID, pr.UserID, metering.OpAnalyticsQuery)
+	// Surface fraud flags covering the queried range so inflated numbers
+	// never ship silently.
+	var warnings []anomaly.Flag
+	if h.anomaly != nil {
+		from, to := time.Time{}, time.Now().UTC()
+		if q.From != nil {
+			from = *q.From
+		}
+		if q.To != nil {
+			to = *q.To
+		}
+		if flags, err := h.anomaly.FlagsOverlapping(r.Context(), pr.OrgID, q.Codes, from, to); err == nil {
+			warnings = flags
+		} else {
+			log.Printf("handler: anomaly flags: %v", err)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"rows":     rows,
+		"warnings": warnings,
+	})
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/ashtonholgate/url-minifier/pkg/auth"
+	"github.com/ashtonholgate/url-minifier/pkg/middleware"
+	"github.com/ashtonholgate/url-minifier/services/analytics/query"
+)
+
+// share is a stored read-only analytics view. The token is the only
+// credential: anyone holding the link sees exactly the stored query's
+// results, nothing else.
+type share struct {
+	Token     string      `bson:"token" json:"token"`
+	OrgID     string      `bson:"org_id" json:"-"`
+	Query     query.Query `bson:"query" json:"query"`
+	CreatedBy string      `bson:"created_by" json:"-"`
+	CreatedAt time.Time   `bson:"created_at" json:"created_at"`
+	ExpiresAt time.Time   `bson:"expires_at" json:"expires_at"`
+}
+
+// defaultShareTTL applies when the creator does not pick an expiry.
+const defaultShareTTL = 30 * 24 * time.Hour
+
+// RegisterShareRoutes mounts share creation on the authenticated router
+// and share viewing on the public one.
+func (h *Handler) RegisterShareRoutes(root *mux.Router, api *mux.Router) {
+	api.HandleFunc("/analytics/shares", h.createShare).Methods(http.MethodPost)
+	root.HandleFunc("/share/{token}", h.viewShare).Methods(http.MethodGet)
+}
+
+func (h *Handler) shares() *mongo.Collection {
+	return h.clicks.Database().Collection("analytics_shares")
+}
+
+func (h *Handler) createShare(w http.ResponseWriter, r *http.Request) {
+	pr, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "unauthenticated"})
+		return
+	}
+	if err := auth.Authorize(pr, auth.PermAnalyticsRead, auth.Resource{OrgID: pr.OrgID}); err != nil {
+		writeJSON(w, http.StatusForbidden, errorResponse{Error: "forbidden"})
+		return
+	}
+	var req struct {
+		Query     query.Query `json:"query"`
+		ExpiresAt *time.Time  `json:"expires_at,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "malformed body"})
+		return
+	}
+	if err := req.Query.Validate(); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "internal error"})
+		return
+	}
+	s := share{
+		Token:     hex.EncodeToString(buf),
+		OrgID:     pr.OrgID,
+		Query:     req.Query,
+		CreatedBy: pr.UserID,
+		CreatedAt: time.Now().UTC(),
+		ExpiresAt: time.Now().UTC().Add(defaultShareTTL),
+	}
+	if req.ExpiresAt != nil {
+		s.ExpiresAt = req.ExpiresAt.UTC()
+	}
+	if _, err := h.shares().InsertOne(r.Context(), s); err != nil {
+		log.Printf("handler: storing share: %v", err)
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "internal error"})
+		return
+	}
+	writeJSON(w, http.StatusCreated, s)
+}
+
+// viewShare runs a stored share's query without authentication; the
+// results are scoped to the org captured at creation time.
+func (h *Handler) viewShare(w http.ResponseWriter, r *http.Request) {
+	var s share
+	err := h.shares().FindOne(r.Context(), bson.M{"token": mux.Vars(r)["token"]}).Decode(&s)
+	if errors.Is(err, mongo.ErrNoDocuments) || (err == nil && time.Now().After(s.ExpiresAt)) {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("handler: loading share: %v", err)
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "internal error"})
+		return
+	}
+	rows, err := h.clicks.Run(r.Context(), s.OrgID, &s.Query)
+	if err != nil {
+		log.Printf("handler: running shared query: %v", err)
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "internal error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"query":      s.Query,
+		"rows":       rows,
+		"expires_at": s.ExpiresAt,
+	})
+}
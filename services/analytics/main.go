@@ -0,0 +1,73 @@
+// Command analytics runs the analytics service: the query DSL over click
+// events recorded by the redirect path.
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ashtonholgate/url-minifier/pkg/auth"
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/metering"
+	"github.com/ashtonholgate/url-minifier/pkg/middleware"
+	"github.com/ashtonholgate/url-minifier/services/analytics/anomaly"
+	"github.com/ashtonholgate/url-minifier/services/analytics/handler"
+	"github.com/ashtonholgate/url-minifier/services/analytics/repository"
+	"github.com/ashtonholgate/url-minifier/services/analytics/rollup"
+)
+
+func main() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	mongoURI := common.Getenv("MONGO_URI", "mongodb://localhost:27017")
+	redisAddr := common.Getenv("REDIS_ADDR", "localhost:6379")
+	listenAddr := common.Getenv("LISTEN_ADDR", ":8082")
+	jwtSecret := common.Getenv("JWT_SECRET", "")
+	if jwtSecret == "" {
+		log.Fatal("JWT_SECRET must be set")
+	}
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
+	if err != nil {
+		log.Fatalf("connecting to mongo: %v", err)
+	}
+	defer client.Disconnect(context.Background())
+
+	rdb := redis.NewClient(&redis.Options{Addr: redisAddr})
+	defer rdb.Close()
+
+	rollup.NewJob(client.Database("analytics")).Start(context.Background())
+	detector := anomaly.NewDetector(client.Database("analytics"))
+	detector.Start(context.Background())
+
+	meter := metering.New(rdb, client.Database("shortener"))
+	h := handler.New(repository.NewClickRepository(client.Database("analytics")), meter, rdb)
+	h.SetAnomalyDetector(detector)
+
+	root := mux.NewRouter()
+	root.Use(middleware.Metrics)
+	root.Handle("/metrics", promhttp.Handler()).Methods(http.MethodGet)
+	api := root.PathPrefix("/api/v1").Subrouter()
+	api.Use(middleware.Authenticate([]byte(jwtSecret), auth.NewSessionStore(rdb)))
+	h.Register(api)
+	h.RegisterConversionRoutes(root, api)
+	h.RegisterShareRoutes(root, api)
+
+	srv := &http.Server{
+		Addr:         listenAddr,
+		Handler:      root,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+	log.Printf("analytics listening on %s", listenAddr)
+	log.Fatal(srv.ListenAndServe())
+}
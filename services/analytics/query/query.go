@@ -0,0 +1,151 @@
+// Package query defines the analytics query DSL and its translation to
+// MongoDB aggregation pipelines. Clients describe what they want — a metric
+// grouped by a dimension over a time range — and never touch pipeline
+// syntax, so the storage layout can evolve behind the DSL.
+package query
+
+import (
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+)
+
+// Metrics the DSL can compute. MetricClicks counts raw clicks;
+// MetricUniqueClicks counts only the first click per visitor fingerprint
+// within the attribution window (last-click dedup done at record time).
+const (
+	MetricClicks       = "clicks"
+	MetricUniqueClicks = "unique_clicks"
+	MetricUniqueIPs    = "unique_ips"
+)
+
+// Dimensions the DSL can group by. GroupBy may be empty for a single
+// overall total.
+const (
+	GroupByDay      = "day"
+	GroupByCode     = "code"
+	GroupByReferrer = "referrer"
+	GroupByBrowser  = "browser"
+	GroupByOS       = "os"
+	GroupByDevice   = "device"
+	GroupByNetwork  = "network"
+)
+
+// Query is one analytics question.
+type Query struct {
+	Metric  string   `json:"metric"`
+	GroupBy string   `json:"group_by,omitempty"`
+	Codes   []string `json:"codes,omitempty"`
+	// CampaignID restricts the query to one campaign's clicks, giving
+	// campaign roll-ups without joining link metadata.
+	CampaignID string `json:"campaign_id,omitempty"`
+	// Event selects the event kind: empty counts clicks only,
+	// "impression" counts pixel loads.
+	Event string     `json:"event,omitempty"`
+	From  *time.Time `json:"from,omitempty"`
+	To    *time.Time `json:"to,omitempty"`
+	Limit int        `json:"limit,omitempty"`
+}
+
+// Row is one result bucket.
+type Row struct {
+	Key   string `bson:"_id" json:"key"`
+	Value int64  `bson:"value" json:"value"`
+}
+
+// defaultLimit bounds result sets when the client does not.
+const defaultLimit = 100
+
+// Validate checks the query against the known metrics and dimensions.
+func (q *Query) Validate() error {
+	switch q.Metric {
+	case MetricClicks, MetricUniqueClicks, MetricUniqueIPs:
+	default:
+		return fmt.Errorf("unknown metric %q: %w", q.Metric, common.ErrInvalidInput)
+	}
+	switch q.GroupBy {
+	case "", GroupByDay, GroupByCode, GroupByReferrer, GroupByBrowser, GroupByOS, GroupByDevice, GroupByNetwork:
+	default:
+		return fmt.Errorf("unknown group_by %q: %w", q.GroupBy, common.ErrInvalidInput)
+	}
+	return nil
+}
+
+// Pipeline translates the query into an aggregation pipeline scoped to one
+// org. Callers must have validated the query first.
+func (q *Query) Pipeline(orgID string) bson.A {
+	match := bson.M{"org_id": orgID}
+	if len(q.Codes) > 0 {
+		match["code"] = bson.M{"$in": q.Codes}
+	}
+	if q.CampaignID != "" {
+		match["campaign_id"] = q.CampaignID
+	}
+	if q.Event == "" {
+		// Impressions live in the same collection; keep click metrics
+		// pure by default.
+		match["event"] = bson.M{"$ne": "impression"}
+	} else {
+		match["event"] = q.Event
+	}
+	at := bson.M{}
+	if q.From != nil {
+		at["$gte"] = *q.From
+	}
+	if q.To != nil {
+		at["$lte"] = *q.To
+	}
+	if len(at) > 0 {
+		match["at"] = at
+	}
+	if q.Metric == MetricUniqueClicks {
+		match["unique"] = true
+	}
+
+	var key interface{}
+	switch q.GroupBy {
+	case GroupByDay:
+		key = bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$at"}}
+	case GroupByCode:
+		key = "$code"
+	case GroupByReferrer:
+		key = "$referrer"
+	case GroupByBrowser:
+		key = "$browser"
+	case GroupByOS:
+		key = "$os"
+	case GroupByDevice:
+		key = "$device"
+	case GroupByNetwork:
+		key = "$network"
+	default:
+		key = "total"
+	}
+
+	var group bson.M
+	pipeline := bson.A{bson.M{"$match": match}}
+	switch q.Metric {
+	case MetricUniqueIPs:
+		group = bson.M{"_id": key, "ips": bson.M{"$addToSet": "$ip"}}
+		pipeline = append(pipeline,
+			bson.M{"$group": group},
+			bson.M{"$project": bson.M{"value": bson.M{"$size": "$ips"}}},
+		)
+	default: // MetricClicks
+		group = bson.M{"_id": key, "value": bson.M{"$sum": 1}}
+		pipeline = append(pipeline, bson.M{"$group": group})
+	}
+
+	limit := q.Limit
+	if limit <= 0 || limit > 1000 {
+		limit = defaultLimit
+	}
+	pipeline = append(pipeline,
+		bson.M{"$sort": bson.D{{Key: "value", Value: -1}, {Key: "_id", Value: 1}}},
+		bson.M{"$limit": limit},
+	)
+	return pipeline
+}
@@ -0,0 +1,95 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestValidate(t *testing.T) {
+	valid := []Query{
+		{Metric: MetricClicks},
+		{Metric: MetricUniqueClicks, GroupBy: GroupByDay},
+		{Metric: MetricUniqueIPs, GroupBy: GroupByCode},
+		{Metric: MetricClicks, GroupBy: GroupByNetwork},
+	}
+	for _, q := range valid {
+		if err := q.Validate(); err != nil {
+			t.Errorf("Validate(%+v): %v", q, err)
+		}
+	}
+	invalid := []Query{
+		{Metric: "bogus"},
+		{Metric: MetricClicks, GroupBy: "bogus"},
+		{},
+	}
+	for _, q := range invalid {
+		if err := q.Validate(); err == nil {
+			t.Errorf("Validate(%+v) accepted", q)
+		}
+	}
+}
+
+// stage fetches a named stage from the pipeline, or nil.
+func stage(p bson.A, name string) bson.M {
+	for _, s := range p {
+		if m, ok := s.(bson.M)[name]; ok {
+			return m.(bson.M)
+		}
+	}
+	return nil
+}
+
+func TestPipelineScopesToOrg(t *testing.T) {
+	q := Query{Metric: MetricClicks}
+	match := stage(q.Pipeline("org42"), "$match")
+	if match == nil || match["org_id"] != "org42" {
+		t.Fatalf("match stage not scoped to org: %v", match)
+	}
+}
+
+func TestPipelineExcludesImpressionsByDefault(t *testing.T) {
+	q := Query{Metric: MetricClicks}
+	match := stage(q.Pipeline("org"), "$match")
+	if _, ok := match["event"]; !ok {
+		t.Error("click metrics should exclude impressions by default")
+	}
+	q.Event = "impression"
+	match = stage(q.Pipeline("org"), "$match")
+	if match["event"] != "impression" {
+		t.Errorf("event filter: got %v", match["event"])
+	}
+}
+
+func TestPipelineFilters(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	q := Query{
+		Metric:     MetricUniqueClicks,
+		Codes:      []string{"a", "b"},
+		CampaignID: "cmp1",
+		From:       &from,
+	}
+	match := stage(q.Pipeline("org"), "$match")
+	if match["campaign_id"] != "cmp1" {
+		t.Errorf("campaign filter: got %v", match["campaign_id"])
+	}
+	if match["unique"] != true {
+		t.Error("unique_clicks must match unique events only")
+	}
+	if _, ok := match["code"]; !ok {
+		t.Error("code filter missing")
+	}
+	if _, ok := match["at"]; !ok {
+		t.Error("time range filter missing")
+	}
+}
+
+func TestPipelineLimitBounds(t *testing.T) {
+	q := Query{Metric: MetricClicks, Limit: 10_000}
+	p := q.Pipeline("org")
+	limit := p[len(p)-1].(bson.M)["$limit"]
+	if limit != defaultLimit {
+		t.Errorf("oversized limit not clamped: got %v", limit)
+	}
+}
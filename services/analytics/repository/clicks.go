@@ -0,0 +1,248 @@
+// Package repository reads click events for the analytics service.
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/ashtonholgate/url-minifier/services/analytics/query"
+	"github.com/ashtonholgate/url-minifier/services/analytics/rollup"
+)
+
+// ClickRepository runs analytics queries over the clicks collection.
+type ClickRepository struct {
+	clicks *mongo.Collection
+}
+
+// NewClickRepository returns a repository over db's clicks collection.
+func NewClickRepository(db *mongo.Database) *ClickRepository {
+	return &ClickRepository{clicks: db.Collection("clicks")}
+}
+
+// Database exposes the analytics database for sibling collections (shares,
+// rollups) owned by the same service.
+func (r *ClickRepository) Database() *mongo.Database {
+	return r.clicks.Database()
+}
+
+// CodeInOrg reports whether the code has any recorded clicks under the
+// org; used to scope per-code endpoints without a link lookup.
+func (r *ClickRepository) CodeInOrg(ctx context.Context, code, orgID string) (bool, error) {
+	err := r.clicks.FindOne(ctx, bson.M{"code": code, "org_id": orgID}).Err()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("checking code org: %w", err)
+	}
+	return true, nil
+}
+
+// rawWindow mirrors the rollup job's retention: raw events older than this
+// have been reduced to daily summaries, so queries reaching further back
+// must merge in the rollups.
+const rawWindow = 30 * 24 * time.Hour
+
+// DomainStats is the per-domain roll-up for brand reporting.
+type DomainStats struct {
+	Domain       string      `json:"domain"`
+	Clicks       int64       `json:"clicks"`
+	UniqueClicks int64       `json:"unique_clicks"`
+	Impressions  int64       `json:"impressions"`
+	TopLinks     []query.Row `json:"top_links"`
+}
+
+// DomainStatsFor aggregates a serving domain's performance for the org
+// over a time range.
+func (r *ClickRepository) DomainStatsFor(ctx context.Context, orgID, domain string, from, to time.Time) (*DomainStats, error) {
+	match := bson.M{
+		"org_id": orgID,
+		"domain": domain,
+		"at":     bson.M{"$gte": from, "$lte": to},
+	}
+	cur, err := r.clicks.Aggregate(ctx, bson.A{
+		bson.M{"$match": match},
+		bson.M{"$group": bson.M{
+			"_id": nil,
+			"clicks": bson.M{"$sum": bson.M{"$cond": bson.A{
+				bson.M{"$ne": bson.A{"$event", "impression"}}, 1, 0}}},
+			"unique_clicks": bson.M{"$sum": bson.M{"$cond": bson.A{
+				bson.M{"$and": bson.A{"$unique", bson.M{"$ne": bson.A{"$event", "impression"}}}}, 1, 0}}},
+			"impressions": bson.M{"$sum": bson.M{"$cond": bson.A{
+				bson.M{"$eq": bson.A{"$event", "impression"}}, 1, 0}}},
+		}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("aggregating domain stats: %w", err)
+	}
+	defer cur.Close(ctx)
+	stats := &DomainStats{Domain: domain}
+	if cur.Next(ctx) {
+		var row struct {
+			Clicks       int64 `bson:"clicks"`
+			UniqueClicks int64 `bson:"unique_clicks"`
+			Impressions  int64 `bson:"impressions"`
+		}
+		if err := cur.Decode(&row); err != nil {
+			return nil, fmt.Errorf("decoding domain stats: %w", err)
+		}
+		stats.Clicks, stats.UniqueClicks, stats.Impressions = row.Clicks, row.UniqueClicks, row.Impressions
+	}
+	if err := cur.Err(); err != nil {
+		return nil, fmt.Errorf("iterating domain stats: %w", err)
+	}
+
+	top, err := r.clicks.Aggregate(ctx, bson.A{
+		bson.M{"$match": bson.M{
+			"org_id": orgID, "domain": domain,
+			"at":    bson.M{"$gte": from, "$lte": to},
+			"event": bson.M{"$ne": "impression"},
+		}},
+		bson.M{"$group": bson.M{"_id": "$code", "value": bson.M{"$sum": 1}}},
+		bson.M{"$sort": bson.D{{Key: "value", Value: -1}}},
+		bson.M{"$limit": 10},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("aggregating top links: %w", err)
+	}
+	defer top.Close(ctx)
+	if err := top.All(ctx, &stats.TopLinks); err != nil {
+		return nil, fmt.Errorf("decoding top links: %w", err)
+	}
+	return stats, nil
+}
+
+// Run executes a validated DSL query scoped to orgID, transparently
+// merging daily rollups for any part of the range that predates the raw
+// event retention.
+func (r *ClickRepository) Run(ctx context.Context, orgID string, q *query.Query) ([]query.Row, error) {
+	// Day-aligned with the rollup job's prune cutoff: rollups cover days
+	// strictly before the boundary day, raw events cover the boundary
+	// day onward, so no hour falls between the two sources.
+	boundary := time.Now().UTC().Add(-rawWindow).Truncate(24 * time.Hour)
+	// Campaign filtering only exists on raw events, not in rollups.
+	needsRollups := mergeableMetric(q.Metric) && mergeableDimension(q.GroupBy) &&
+		q.CampaignID == "" && (q.From == nil || q.From.Before(boundary))
+
+	rawQuery := *q
+	if needsRollups {
+		// The raw pipeline only covers the retained window; older days
+		// come from rollups below.
+		from := boundary
+		rawQuery.From = &from
+		if q.To != nil && q.To.Before(boundary) {
+			empty := boundary
+			rawQuery.From, rawQuery.To = &empty, &empty
+		}
+	}
+	cur, err := r.clicks.Aggregate(ctx, rawQuery.Pipeline(orgID))
+	if err != nil {
+		return nil, fmt.Errorf("running analytics query: %w", err)
+	}
+	defer cur.Close(ctx)
+	var rows []query.Row
+	if err := cur.All(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("decoding analytics rows: %w", err)
+	}
+	if !needsRollups {
+		return rows, nil
+	}
+	rolled, err := r.rollupRows(ctx, orgID, q, boundary)
+	if err != nil {
+		return nil, err
+	}
+	return mergeRows(rows, rolled, q.Limit), nil
+}
+
+// mergeableMetric reports whether the metric can be reconstructed from
+// daily rollups. Unique IPs cannot: the HLL path covers those.
+func mergeableMetric(metric string) bool {
+	return metric == query.MetricClicks || metric == query.MetricUniqueClicks
+}
+
+// mergeableDimension reports whether the dimension survives rollup.
+// Referrer detail is only in raw events.
+func mergeableDimension(groupBy string) bool {
+	return groupBy == "" || groupBy == query.GroupByDay || groupBy == query.GroupByCode
+}
+
+// rollupRows reads the pre-boundary part of the range from click_rollups
+// and buckets it by the query's dimension.
+func (r *ClickRepository) rollupRows(ctx context.Context, orgID string, q *query.Query, boundary time.Time) ([]query.Row, error) {
+	filter := bson.M{"org_id": orgID, "day": bson.M{"$lt": boundary.Format("2006-01-02")}}
+	if q.From != nil {
+		filter["day"].(bson.M)["$gte"] = q.From.UTC().Format("2006-01-02")
+	}
+	if q.To != nil && q.To.Before(boundary) {
+		filter["day"].(bson.M)["$lte"] = q.To.UTC().Format("2006-01-02")
+	}
+	if len(q.Codes) > 0 {
+		filter["code"] = bson.M{"$in": q.Codes}
+	}
+	cur, err := r.clicks.Database().Collection("click_rollups").Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("reading rollups: %w", err)
+	}
+	defer cur.Close(ctx)
+	buckets := map[string]int64{}
+	for cur.Next(ctx) {
+		var s rollup.Summary
+		if err := cur.Decode(&s); err != nil {
+			return nil, fmt.Errorf("decoding rollup: %w", err)
+		}
+		value := s.Clicks
+		if q.Metric == query.MetricUniqueClicks {
+			value = s.UniqueClicks
+		}
+		key := "total"
+		switch q.GroupBy {
+		case query.GroupByDay:
+			key = s.Day
+		case query.GroupByCode:
+			key = s.Code
+		}
+		buckets[key] += value
+	}
+	if err := cur.Err(); err != nil {
+		return nil, fmt.Errorf("iterating rollups: %w", err)
+	}
+	out := make([]query.Row, 0, len(buckets))
+	for k, v := range buckets {
+		out = append(out, query.Row{Key: k, Value: v})
+	}
+	return out, nil
+}
+
+// mergeRows sums two row sets by key and re-applies ordering and limit.
+func mergeRows(a, b []query.Row, limit int) []query.Row {
+	merged := map[string]int64{}
+	for _, row := range a {
+		merged[row.Key] += row.Value
+	}
+	for _, row := range b {
+		merged[row.Key] += row.Value
+	}
+	out := make([]query.Row, 0, len(merged))
+	for k, v := range merged {
+		out = append(out, query.Row{Key: k, Value: v})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Value != out[j].Value {
+			return out[i].Value > out[j].Value
+		}
+		return out[i].Key < out[j].Key
+	})
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+)
+
+// Conversion ties a conversion event back to the click that produced it.
+type Conversion struct {
+	ID      string    `bson:"_id,omitempty" json:"id"`
+	ClickID string    `bson:"click_id" json:"click_id"`
+	Code    string    `bson:"code" json:"code"`
+	OrgID   string    `bson:"org_id,omitempty" json:"org_id,omitempty"`
+	At      time.Time `bson:"at" json:"at"`
+	// Value is an optional monetary or custom value from the postback.
+	Value float64 `bson:"value,omitempty" json:"value,omitempty"`
+	// Variant carries the experiment arm of the originating click.
+	Variant string `bson:"variant,omitempty" json:"variant,omitempty"`
+}
+
+// RecordConversion resolves the click behind clickID and stores a
+// conversion against its link. Repeat conversions for the same click are
+// dropped so retrying postbacks cannot inflate the numbers.
+func (r *ClickRepository) RecordConversion(ctx context.Context, clickID string, value float64) error {
+	var click struct {
+		Code    string `bson:"code"`
+		OrgID   string `bson:"org_id"`
+		Variant string `bson:"variant"`
+	}
+	err := r.clicks.FindOne(ctx, bson.M{"click_id": clickID}).Decode(&click)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return fmt.Errorf("click %q: %w", clickID, common.ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("resolving click: %w", err)
+	}
+	conversions := r.clicks.Database().Collection("conversions")
+	if existing := conversions.FindOne(ctx, bson.M{"click_id": clickID}); existing.Err() == nil {
+		return nil
+	}
+	_, err = conversions.InsertOne(ctx, Conversion{
+		ClickID: clickID,
+		Code:    click.Code,
+		OrgID:   click.OrgID,
+		At:      time.Now().UTC(),
+		Value:   value,
+		Variant: click.Variant,
+	})
+	if err != nil {
+		return fmt.Errorf("inserting conversion: %w", err)
+	}
+	return nil
+}
+
+// ConversionStats summarises a link's conversion performance.
+type ConversionStats struct {
+	Code        string  `json:"code"`
+	Clicks      int64   `json:"clicks"`
+	Conversions int64   `json:"conversions"`
+	Rate        float64 `json:"rate"`
+}
+
+// VariantStats is one experiment arm's performance.
+type VariantStats struct {
+	Variant     string  `json:"variant"`
+	Exposures   int64   `json:"exposures"`
+	Conversions int64   `json:"conversions"`
+	Rate        float64 `json:"rate"`
+}
+
+// VariantStatsForCode computes per-variant exposure and conversion counts
+// for a link running an experiment.
+func (r *ClickRepository) VariantStatsForCode(ctx context.Context, code, orgID string) ([]VariantStats, error) {
+	countBy := func(coll *mongo.Collection) (map[string]int64, error) {
+		cur, err := coll.Aggregate(ctx, bson.A{
+			bson.M{"$match": bson.M{"code": code, "org_id": orgID, "variant": bson.M{"$ne": ""}}},
+			bson.M{"$group": bson.M{"_id": "$variant", "n": bson.M{"$sum": 1}}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		defer cur.Close(ctx)
+		out := map[string]int64{}
+		for cur.Next(ctx) {
+			var row struct {
+				ID string `bson:"_id"`
+				N  int64  `bson:"n"`
+			}
+			if err := cur.Decode(&row); err != nil {
+				return nil, err
+			}
+			out[row.ID] = row.N
+		}
+		return out, cur.Err()
+	}
+	exposures, err := countBy(r.clicks)
+	if err != nil {
+		return nil, fmt.Errorf("counting exposures: %w", err)
+	}
+	conversions, err := countBy(r.clicks.Database().Collection("conversions"))
+	if err != nil {
+		return nil, fmt.Errorf("counting variant conversions: %w", err)
+	}
+	out := make([]VariantStats, 0, len(exposures))
+	for variant, n := range exposures {
+		stats := VariantStats{Variant: variant, Exposures: n, Conversions: conversions[variant]}
+		if n > 0 {
+			stats.Rate = float64(stats.Conversions) / float64(n)
+		}
+		out = append(out, stats)
+	}
+	return out, nil
+}
+
+// ConversionStatsForCode computes click and conversion counts and the rate
+// for one link.
+func (r *ClickRepository) ConversionStatsForCode(ctx context.Context, code, orgID string) (*ConversionStats, error) {
+	clicks, err := r.clicks.CountDocuments(ctx, bson.M{"code": code, "org_id": orgID})
+	if err != nil {
+		return nil, fmt.Errorf("counting clicks: %w", err)
+	}
+	conversions, err := r.clicks.Database().Collection("conversions").
+		CountDocuments(ctx, bson.M{"code": code, "org_id": orgID})
+	if err != nil {
+		return nil, fmt.Errorf("counting conversions: %w", err)
+	}
+	stats := &ConversionStats{Code: code, Clicks: clicks, Conversions: conversions}
+	if clicks > 0 {
+		stats.Rate = float64(conversions) / float64(clicks)
+	}
+	return stats, nil
+}
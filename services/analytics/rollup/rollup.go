@@ -0,0 +1,176 @@
+// Package rollup compacts raw click events into per-link daily summaries
+// so analytics stay queryable long after the raw events are deleted for
+// retention.
+package rollup
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// rawRetention is how long raw click events are kept. Rollups are
+// permanent.
+const rawRetention = 30 * 24 * time.Hour
+
+// Summary is one day of one link's clicks, the granularity everything
+// older than rawRetention is reduced to.
+type Summary struct {
+	Day          string `bson:"day" json:"day"`
+	Code         string `bson:"code" json:"code"`
+	OrgID        string `bson:"org_id,omitempty" json:"org_id,omitempty"`
+	Clicks       int64  `bson:"clicks" json:"clicks"`
+	UniqueClicks int64  `bson:"unique_clicks" json:"unique_clicks"`
+}
+
+// Job rolls up and prunes the clicks collection.
+type Job struct {
+	clicks  *mongo.Collection
+	rollups *mongo.Collection
+}
+
+// NewJob returns a Job over db's clicks and click_rollups collections.
+func NewJob(db *mongo.Database) *Job {
+	return &Job{
+		clicks:  db.Collection("clicks"),
+		rollups: db.Collection("click_rollups"),
+	}
+}
+
+// RollupDay aggregates the raw clicks of one UTC day (YYYY-MM-DD) into
+// summary documents. Idempotent: reruns upsert the same keys.
+func (j *Job) RollupDay(ctx context.Context, day string) error {
+	start, err := time.Parse("2006-01-02", day)
+	if err != nil {
+		return fmt.Errorf("parsing day %q: %w", day, err)
+	}
+	end := start.AddDate(0, 0, 1)
+	pipeline := bson.A{
+		bson.M{"$match": bson.M{"at": bson.M{"$gte": start, "$lt": end}}},
+		bson.M{"$group": bson.M{
+			"_id":    bson.M{"code": "$code", "org_id": "$org_id"},
+			"clicks": bson.M{"$sum": 1},
+			"unique_clicks": bson.M{"$sum": bson.M{
+				"$cond": bson.A{"$unique", 1, 0},
+			}},
+		}},
+	}
+	cur, err := j.clicks.Aggregate(ctx, pipeline)
+	if err != nil {
+		return fmt.Errorf("aggregating %s: %w", day, err)
+	}
+	defer cur.Close(ctx)
+	for cur.Next(ctx) {
+		var row struct {
+			ID struct {
+				Code  string `bson:"code"`
+				OrgID string `bson:"org_id"`
+			} `bson:"_id"`
+			Clicks       int64 `bson:"clicks"`
+			UniqueClicks int64 `bson:"unique_clicks"`
+		}
+		if err := cur.Decode(&row); err != nil {
+			return fmt.Errorf("decoding rollup row: %w", err)
+		}
+		s := Summary{
+			Day: day, Code: row.ID.Code, OrgID: row.ID.OrgID,
+			Clicks: row.Clicks, UniqueClicks: row.UniqueClicks,
+		}
+		_, err := j.rollups.ReplaceOne(ctx,
+			bson.M{"day": day, "code": s.Code},
+			s, options.Replace().SetUpsert(true))
+		if err != nil {
+			return fmt.Errorf("writing rollup for %s/%s: %w", day, s.Code, err)
+		}
+	}
+	return cur.Err()
+}
+
+// Prune deletes raw clicks past retention, one whole day at a time. The
+// cutoff is truncated to a UTC day boundary so the boundary day keeps its
+// full raw events — the query layer reads rollups for days strictly
+// before the boundary and raw events from the boundary day onward, and a
+// mid-day cutoff would leave the pruned morning counted by neither. Each
+// day is only deleted once a rollup for it exists (or it produced no
+// events at all); a day whose rollup failed keeps its raw events for a
+// later sweep.
+func (j *Job) Prune(ctx context.Context) error {
+	cutoff := time.Now().UTC().Add(-rawRetention).Truncate(24 * time.Hour)
+	cur, err := j.clicks.Aggregate(ctx, bson.A{
+		bson.M{"$match": bson.M{"at": bson.M{"$lt": cutoff}}},
+		bson.M{"$group": bson.M{"_id": bson.M{
+			"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$at"},
+		}}},
+	})
+	if err != nil {
+		return fmt.Errorf("listing prunable days: %w", err)
+	}
+	defer cur.Close(ctx)
+	var days []string
+	for cur.Next(ctx) {
+		var row struct {
+			Day string `bson:"_id"`
+		}
+		if err := cur.Decode(&row); err != nil {
+			return fmt.Errorf("decoding prunable day: %w", err)
+		}
+		days = append(days, row.Day)
+	}
+	if err := cur.Err(); err != nil {
+		return fmt.Errorf("iterating prunable days: %w", err)
+	}
+	for _, day := range days {
+		n, err := j.rollups.CountDocuments(ctx, bson.M{"day": day})
+		if err != nil {
+			return fmt.Errorf("checking rollups for %s: %w", day, err)
+		}
+		if n == 0 {
+			// Not reduced yet; roll it up now and delete on the next
+			// sweep once the write is confirmed.
+			if err := j.RollupDay(ctx, day); err != nil {
+				log.Printf("rollup: late rollup of %s: %v", day, err)
+			}
+			continue
+		}
+		start, err := time.Parse("2006-01-02", day)
+		if err != nil {
+			continue
+		}
+		_, err = j.clicks.DeleteMany(ctx, bson.M{"at": bson.M{
+			"$gte": start, "$lt": start.AddDate(0, 0, 1),
+		}})
+		if err != nil {
+			return fmt.Errorf("pruning raw clicks for %s: %w", day, err)
+		}
+	}
+	return nil
+}
+
+// Start rolls up yesterday and prunes expired raw events every hour until
+// ctx is cancelled.
+func (j *Job) Start(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				yesterday := time.Now().UTC().AddDate(0, 0, -1).Format("2006-01-02")
+				if err := j.RollupDay(ctx, yesterday); err != nil {
+					log.Printf("rollup: %v", err)
+					continue
+				}
+				if err := j.Prune(ctx); err != nil {
+					log.Printf("rollup: %v", err)
+				}
+			}
+		}
+	}()
+}
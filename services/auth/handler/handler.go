@@ -0,0 +1,158 @@
+// Package handler exposes the auth service's session API: login issues a
+// token bound to a Redis session, refresh extends it, and logout kills one
+// session or all of them.
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/ashtonholgate/url-minifier/pkg/auth"
+	"github.com/ashtonholgate/url-minifier/pkg/middleware"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+// Handler serves the session lifecycle endpoints.
+type Handler struct {
+	users    *mongo.Collection
+	sessions *auth.SessionStore
+	secret   []byte
+}
+
+// New returns a Handler reading users from the given collection and tracking
+// sessions in store.
+func New(users *mongo.Collection, sessions *auth.SessionStore, secret []byte) *Handler {
+	return &Handler{users: users, sessions: sessions, secret: secret}
+}
+
+// Register mounts the public login route on root and the authenticated
+// session-management routes on api.
+func (h *Handler) Register(root *mux.Router, api *mux.Router) {
+	root.HandleFunc("/api/v1/sessions", h.login).Methods(http.MethodPost)
+	api.HandleFunc("/sessions/refresh", h.refresh).Methods(http.MethodPost)
+	api.HandleFunc("/sessions/current", h.logout).Methods(http.MethodDelete)
+	api.HandleFunc("/sessions", h.logoutEverywhere).Methods(http.MethodDelete)
+}
+
+type loginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type tokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// issueToken mints a JWT for the user bound to a fresh session.
+func (h *Handler) issueToken(ctx context.Context, u *models.User) (*tokenResponse, error) {
+	sid, err := h.sessions.Issue(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	exp := time.Now().Add(auth.SessionTTL)
+	claims := middleware.Claims{
+		OrgID:     u.OrgID,
+		Role:      u.Role,
+		SessionID: sid,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   u.ID,
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(exp),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(h.secret)
+	if err != nil {
+		return nil, fmt.Errorf("signing token: %w", err)
+	}
+	return &tokenResponse{Token: token, ExpiresAt: exp}, nil
+}
+
+func (h *Handler) login(w http.ResponseWriter, r *http.Request) {
+	var req loginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "malformed body", http.StatusBadRequest)
+		return
+	}
+	var u models.User
+	err := h.users.FindOne(r.Context(), bson.M{"email": req.Email}).Decode(&u)
+	if errors.Is(err, mongo.ErrNoDocuments) ||
+		(err == nil && bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(req.Password)) != nil) {
+		http.Error(w, "invalid credentials", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		log.Printf("auth: looking up user: %v", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	resp, err := h.issueToken(r.Context(), &u)
+	if err != nil {
+		log.Printf("auth: issuing token: %v", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// sessionID extracts the caller's session ID from their bearer token. The
+// authentication middleware has already verified the token.
+func (h *Handler) sessionID(r *http.Request) string {
+	raw := r.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if len(raw) <= len(prefix) {
+		return ""
+	}
+	var claims middleware.Claims
+	if _, err := jwt.ParseWithClaims(raw[len(prefix):], &claims, func(t *jwt.Token) (interface{}, error) {
+		return h.secret, nil
+	}, jwt.WithValidMethods([]string{"HS256"})); err != nil {
+		return ""
+	}
+	return claims.SessionID
+}
+
+func (h *Handler) refresh(w http.ResponseWriter, r *http.Request) {
+	if err := h.sessions.Refresh(r.Context(), h.sessionID(r)); err != nil {
+		if errors.Is(err, auth.ErrSessionRevoked) {
+			http.Error(w, "session revoked", http.StatusUnauthorized)
+			return
+		}
+		log.Printf("auth: refreshing session: %v", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) logout(w http.ResponseWriter, r *http.Request) {
+	pr, _ := middleware.PrincipalFrom(r.Context())
+	if err := h.sessions.Revoke(r.Context(), pr.UserID, h.sessionID(r)); err != nil {
+		log.Printf("auth: revoking session: %v", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) logoutEverywhere(w http.ResponseWriter, r *http.Request) {
+	pr, _ := middleware.PrincipalFrom(r.Context())
+	if err := h.sessions.RevokeAll(r.Context(), pr.UserID); err != nil {
+		log.Printf("auth: revoking sessions: %v", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
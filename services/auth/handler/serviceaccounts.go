@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/ashtonholgate/url-minifier/pkg/auth"
+	"github.com/ashtonholgate/url-minifier/pkg/middleware"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+// ServiceAccountHandler manages machine principals and their scoped keys.
+type ServiceAccountHandler struct {
+	accounts *mongo.Collection
+	keys     *mongo.Collection
+}
+
+// NewServiceAccountHandler returns a handler over db's service_accounts
+// and api_keys collections.
+func NewServiceAccountHandler(db *mongo.Database) *ServiceAccountHandler {
+	return &ServiceAccountHandler{
+		accounts: db.Collection("service_accounts"),
+		keys:     db.Collection("api_keys"),
+	}
+}
+
+// Register mounts the service-account routes on the authenticated api
+// router.
+func (h *ServiceAccountHandler) Register(api *mux.Router) {
+	api.HandleFunc("/service-accounts", h.create).Methods(http.MethodPost)
+	api.HandleFunc("/service-accounts", h.list).Methods(http.MethodGet)
+	api.HandleFunc("/service-accounts/{id}/keys", h.createKey).Methods(http.MethodPost)
+}
+
+// admin authorizes the caller as an org admin and returns the principal.
+func admin(w http.ResponseWriter, r *http.Request) (auth.Principal, bool) {
+	pr, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		http.Error(w, "unauthenticated", http.StatusUnauthorized)
+		return pr, false
+	}
+	if err := auth.Authorize(pr, auth.PermAdmin, auth.Resource{OrgID: pr.OrgID}); err != nil {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return pr, false
+	}
+	return pr, true
+}
+
+func (h *ServiceAccountHandler) create(w http.ResponseWriter, r *http.Request) {
+	pr, ok := admin(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+	sa := models.ServiceAccount{
+		ID:        fmt.Sprintf("sa_%d", time.Now().UnixNano()),
+		Name:      req.Name,
+		OrgID:     pr.OrgID,
+		CreatedBy: pr.UserID,
+		CreatedAt: time.Now().UTC(),
+	}
+	if _, err := h.accounts.InsertOne(r.Context(), sa); err != nil {
+		log.Printf("auth: creating service account: %v", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(sa)
+}
+
+func (h *ServiceAccountHandler) list(w http.ResponseWriter, r *http.Request) {
+	pr, ok := admin(w, r)
+	if !ok {
+		return
+	}
+	cur, err := h.accounts.Find(r.Context(), bson.M{"org_id": pr.OrgID})
+	if err != nil {
+		log.Printf("auth: listing service accounts: %v", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	defer cur.Close(r.Context())
+	var out []models.ServiceAccount
+	if err := cur.All(r.Context(), &out); err != nil {
+		log.Printf("auth: decoding service accounts: %v", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(out)
+}
+
+// createKey mints a scoped API key for a service account. The secret is
+// returned exactly once.
+func (h *ServiceAccountHandler) createKey(w http.ResponseWriter, r *http.Request) {
+	pr, ok := admin(w, r)
+	if !ok {
+		return
+	}
+	id := mux.Vars(r)["id"]
+	var sa models.ServiceAccount
+	err := h.accounts.FindOne(r.Context(), bson.M{"_id": id, "org_id": pr.OrgID}).Decode(&sa)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("auth: fetching service account: %v", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	var req struct {
+		Scopes []string `json:"scopes"`
+		Role   string   `json:"role,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Scopes) == 0 {
+		http.Error(w, "scopes are required", http.StatusBadRequest)
+		return
+	}
+	role := req.Role
+	if role == "" || !auth.Role(role).Valid() {
+		role = string(auth.RoleEditor)
+	}
+	keyID, secret := randomHex(8), randomHex(32)
+	key := models.APIKey{
+		KeyID:            keyID,
+		Secret:           secret,
+		UserID:           sa.ID,
+		OrgID:            sa.OrgID,
+		Role:             role,
+		Scopes:           req.Scopes,
+		ServiceAccountID: sa.ID,
+		CreatedAt:        time.Now().UTC(),
+	}
+	if _, err := h.keys.InsertOne(r.Context(), key); err != nil {
+		log.Printf("auth: storing service account key: %v", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"key_id": keyID,
+		// Shown once; only the verifier-side copy is kept.
+		"secret": secret,
+		"scopes": req.Scopes,
+		"role":   role,
+	})
+}
+
+func randomHex(n int) string {
+	buf := make([]byte, n)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
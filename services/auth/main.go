@@ -0,0 +1,63 @@
+// Command auth runs the authentication service: login, session refresh and
+// revocation, including "log out everywhere".
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ashtonholgate/url-minifier/pkg/auth"
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/middleware"
+	"github.com/ashtonholgate/url-minifier/services/auth/handler"
+)
+
+func main() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	mongoURI := common.Getenv("MONGO_URI", "mongodb://localhost:27017")
+	redisAddr := common.Getenv("REDIS_ADDR", "localhost:6379")
+	listenAddr := common.Getenv("LISTEN_ADDR", ":8081")
+	jwtSecret := common.Getenv("JWT_SECRET", "")
+	if jwtSecret == "" {
+		log.Fatal("JWT_SECRET must be set")
+	}
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
+	if err != nil {
+		log.Fatalf("connecting to mongo: %v", err)
+	}
+	defer client.Disconnect(context.Background())
+
+	rdb := redis.NewClient(&redis.Options{Addr: redisAddr})
+	defer rdb.Close()
+
+	sessions := auth.NewSessionStore(rdb)
+	h := handler.New(client.Database("auth").Collection("users"), sessions, []byte(jwtSecret))
+
+	root := mux.NewRouter()
+	root.Use(middleware.Metrics)
+	root.Handle("/metrics", promhttp.Handler()).Methods(http.MethodGet)
+	api := root.PathPrefix("/api/v1").Subrouter()
+	api.Use(middleware.Authenticate([]byte(jwtSecret), sessions))
+	h.Register(root, api)
+	handler.NewServiceAccountHandler(client.Database("auth")).Register(api)
+
+	srv := &http.Server{
+		Addr:         listenAddr,
+		Handler:      root,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+	log.Printf("auth listening on %s", listenAddr)
+	log.Fatal(srv.ListenAndServe())
+}
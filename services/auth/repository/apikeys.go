@@ -0,0 +1,49 @@
+// Package repository persists auth-service data: API keys and, through the
+// shared models, users.
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+// APIKeyRepository stores API keys in the api_keys collection. It satisfies
+// middleware.APIKeyResolver, so any service can verify signed requests
+// against the shared auth database.
+type APIKeyRepository struct {
+	keys *mongo.Collection
+}
+
+// NewAPIKeyRepository returns a repository over db's api_keys collection.
+func NewAPIKeyRepository(db *mongo.Database) *APIKeyRepository {
+	return &APIKeyRepository{keys: db.Collection("api_keys")}
+}
+
+// LookupAPIKey returns the key with the given public key ID, or a wrapped
+// common.ErrNotFound.
+func (r *APIKeyRepository) LookupAPIKey(ctx context.Context, keyID string) (*models.APIKey, error) {
+	var k models.APIKey
+	err := r.keys.FindOne(ctx, bson.M{"key_id": keyID}).Decode(&k)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("api key %q: %w", keyID, common.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetching api key: %w", err)
+	}
+	return &k, nil
+}
+
+// StoreAPIKey inserts a new key.
+func (r *APIKeyRepository) StoreAPIKey(ctx context.Context, k *models.APIKey) error {
+	if _, err := r.keys.InsertOne(ctx, k); err != nil {
+		return fmt.Errorf("inserting api key: %w", err)
+	}
+	return nil
+}
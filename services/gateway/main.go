@@ -0,0 +1,131 @@
+// Command gateway is the public entry point: it terminates authentication
+// once at the edge and routes requests to the backing services — sessions
+// to auth, analytics to analytics, the management API to the shortener and
+// everything else to the redirect service. Backends still verify tokens
+// themselves (defence in depth); the gateway's job is to reject garbage
+// early and keep routing in one place.
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ashtonholgate/url-minifier/pkg/auth"
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/middleware"
+	"github.com/ashtonholgate/url-minifier/pkg/plans"
+)
+
+// proxyTo returns a reverse proxy handler for target, annotating forwarded
+// requests with the authenticated principal so backends can trust the edge
+// when they choose to.
+func proxyTo(rawURL string) http.Handler {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		log.Fatalf("parsing upstream %q: %v", rawURL, err)
+	}
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	director := proxy.Director
+	proxy.Director = func(r *http.Request) {
+		director(r)
+		if pr, ok := middleware.PrincipalFrom(r.Context()); ok {
+			r.Header.Set("X-User-Id", pr.UserID)
+			r.Header.Set("X-Org-Id", pr.OrgID)
+			r.Header.Set("X-Role", string(pr.Role))
+		}
+	}
+	return proxy
+}
+
+// passHMACThrough skips the edge bearer check for requests carrying HMAC
+// signature headers: the gateway holds no key material, so these go
+// through to the shortener's HMACAuth, which verifies the signature and
+// replay guard itself.
+func passHMACThrough(authed func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		bearer := authed(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get(middleware.HeaderSignatureKeyID) != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			bearer.ServeHTTP(w, r)
+		})
+	}
+}
+
+func main() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	listenAddr := common.Getenv("LISTEN_ADDR", ":8000")
+	redisAddr := common.Getenv("REDIS_ADDR", "localhost:6379")
+	mongoURI := common.Getenv("MONGO_URI", "mongodb://localhost:27017")
+	jwtSecret := common.Getenv("JWT_SECRET", "")
+	if jwtSecret == "" {
+		log.Fatal("JWT_SECRET must be set")
+	}
+	shortenerURL := common.Getenv("SHORTENER_URL", "http://localhost:8080")
+	authURL := common.Getenv("AUTH_URL", "http://localhost:8081")
+	analyticsURL := common.Getenv("ANALYTICS_URL", "http://localhost:8082")
+	redirectURL := common.Getenv("REDIRECT_URL", "http://localhost:8090")
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
+	if err != nil {
+		log.Fatalf("connecting to mongo: %v", err)
+	}
+	defer client.Disconnect(context.Background())
+
+	rdb := redis.NewClient(&redis.Options{Addr: redisAddr})
+	defer rdb.Close()
+	sessions := auth.NewSessionStore(rdb)
+	authenticate := middleware.Authenticate([]byte(jwtSecret), sessions)
+	// Per-plan rate limiting applies at the edge too; the shortener keeps
+	// its own limiter for deployments running without the gateway, and
+	// both share the same Redis windows so nothing double-counts into a
+	// larger allowance.
+	limiter := middleware.NewRateLimiter(rdb, plans.NewEntitlements(client.Database("auth")))
+
+	root := mux.NewRouter()
+	root.Use(middleware.Metrics)
+	root.Handle("/metrics", promhttp.Handler()).Methods(http.MethodGet)
+
+	// Login and the public conversion endpoints stay unauthenticated.
+	root.PathPrefix("/api/v1/sessions").Handler(proxyTo(authURL))
+	root.PathPrefix("/api/v1/conversions").Handler(proxyTo(analyticsURL))
+	root.PathPrefix("/px/").Handler(proxyTo(analyticsURL))
+	root.PathPrefix("/share/").Handler(proxyTo(analyticsURL))
+
+	// Authenticated API surface: bearer auth checked once at the edge
+	// (HMAC-signed requests pass through for the backend to verify),
+	// then the plan rate limit.
+	api := root.PathPrefix("/api/v1").Subrouter()
+	api.Use(passHMACThrough(authenticate), limiter.Middleware)
+	api.PathPrefix("/analytics").Handler(proxyTo(analyticsURL))
+	api.PathPrefix("/").Handler(proxyTo(shortenerURL))
+
+	// Everything else is a short link or a well-known file.
+	root.PathPrefix("/").Handler(proxyTo(redirectURL))
+
+	srv := &http.Server{
+		Addr:         listenAddr,
+		Handler:      root,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+	log.Printf("gateway listening on %s, routing api=%s auth=%s analytics=%s redirect=%s",
+		listenAddr, shortenerURL, authURL, analyticsURL,
+		strings.TrimSuffix(redirectURL, "/"))
+	log.Fatal(srv.ListenAndServe())
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/redirect"
+)
+
+// registerBundleRoutes mounts the public bundle item redirect; the landing
+// page itself is served by the redirect handler's bundle fallback.
+func (h *Handler) registerBundleRoutes(root *mux.Router) {
+	root.HandleFunc("/{code}/items/{index:[0-9]+}", h.bundleItem).Methods(http.MethodGet)
+}
+
+// serveBundleFallback renders a bundle landing page when the code is not a
+// single link; returns false when no bundle exists either.
+func (h *Handler) serveBundleFallback(w http.ResponseWriter, r *http.Request, code string) bool {
+	if h.bundles == nil {
+		return false
+	}
+	b, err := h.bundles.GetBundleByCode(r.Context(), code)
+	if err != nil {
+		return false
+	}
+	redirect.ServeBundlePage(w, b)
+	return true
+}
+
+func (h *Handler) bundleItem(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	index, err := strconv.Atoi(vars["index"])
+	if err != nil || h.bundles == nil {
+		writeStatus(w, http.StatusNotFound, "not found")
+		return
+	}
+	code := h.canonicalCode(vars["code"])
+	b, err := h.bundles.GetBundleByCode(r.Context(), code)
+	if err != nil {
+		if !common.IsNotFound(err) {
+			log.Printf("handler: resolving bundle %s: %v", code, err)
+		}
+		writeStatus(w, http.StatusNotFound, "not found")
+		return
+	}
+	if index < 0 || index >= len(b.Items) {
+		writeStatus(w, http.StatusNotFound, "not found")
+		return
+	}
+	// The per-item counter is this service's one small asynchronous
+	// write, same budget as the analytics click record.
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := h.bundles.IncrementItemClicks(ctx, code, index); err != nil {
+			log.Printf("handler: counting bundle item click: %v", err)
+		}
+	}()
+	http.Redirect(w, r, b.Items[index].LongURL, http.StatusFound)
+}
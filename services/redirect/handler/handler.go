@@ -0,0 +1,178 @@
+// Package handler serves the redirect-only HTTP surface. It resolves
+// through the shared cache-first repository, never writes to MongoDB, and
+// records analytics asynchronously.
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/middleware"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+	"github.com/ashtonholgate/url-minifier/pkg/redirect"
+	"github.com/ashtonholgate/url-minifier/services/shortener/abuse"
+	"github.com/ashtonholgate/url-minifier/services/shortener/clicks"
+	"github.com/ashtonholgate/url-minifier/services/shortener/repository"
+	"github.com/ashtonholgate/url-minifier/services/shortener/service"
+)
+
+// Handler serves redirects.
+type Handler struct {
+	repo     repository.URLRepository
+	clicks   *clicks.Recorder
+	abuse    *abuse.Tracker
+	domains  *repository.DomainRepository
+	bundles  *repository.BundleRepository
+	settings *repository.DomainSettingsCache
+	// rdb backs rotation pointer advancement, shared with the shortener.
+	rdb *redis.Client
+	// caseInsensitive mirrors the shortener's CASE_INSENSITIVE_CODES
+	// deployment choice; both binaries must fold identically or
+	// mixed-case codes resolve on one and 404 on the other.
+	caseInsensitive bool
+}
+
+// New returns a Handler over the shared repository.
+func New(repo repository.URLRepository, rec *clicks.Recorder, tracker *abuse.Tracker, domains *repository.DomainRepository, rdb *redis.Client) *Handler {
+	return &Handler{
+		repo: repo, clicks: rec, abuse: tracker, domains: domains, rdb: rdb,
+		settings: repository.NewDomainSettingsCache(domains),
+	}
+}
+
+// SetBundleRepository attaches bundle storage so bundle codes render
+// their landing pages here too.
+func (h *Handler) SetBundleRepository(bundles *repository.BundleRepository) {
+	h.bundles = bundles
+}
+
+// EnableCaseInsensitiveCodes folds incoming codes to lower case before
+// lookup, matching the shortener's canonicalisation at creation.
+func (h *Handler) EnableCaseInsensitiveCodes() { h.caseInsensitive = true }
+
+// canonicalCode folds code according to the deployment's case mode.
+func (h *Handler) canonicalCode(code string) string {
+	if h.caseInsensitive {
+		return strings.ToLower(code)
+	}
+	return code
+}
+
+// Register mounts the redirect, bundle, public and well-known routes on
+// root.
+func (h *Handler) Register(root *mux.Router) {
+	h.registerWellKnownRoutes(root)
+	h.registerBundleRoutes(root)
+	h.registerPublicRoutes(root)
+	root.HandleFunc("/{code}", h.redirect).Methods(http.MethodGet)
+}
+
+func writeStatus(w http.ResponseWriter, status int, msg string) {
+	middleware.ObserveRedirect(status)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
+
+// writeNotFound serves the domain's branded 404 page when one is
+// configured, the JSON payload otherwise.
+func writeNotFound(w http.ResponseWriter, settings models.DomainSettings) {
+	if settings.NotFoundHTML != "" {
+		middleware.ObserveRedirect(http.StatusNotFound)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(settings.NotFoundHTML))
+		return
+	}
+	writeStatus(w, http.StatusNotFound, "not found")
+}
+
+func (h *Handler) redirect(w http.ResponseWriter, r *http.Request) {
+	ip := middleware.ClientIP(r)
+	ipStr := ""
+	if ip != nil {
+		ipStr = ip.String()
+	}
+	if h.abuse.Banned(r.Context(), ipStr) {
+		writeStatus(w, http.StatusTooManyRequests, "temporarily banned")
+		return
+	}
+	code := h.canonicalCode(mux.Vars(r)["code"])
+	settings := h.settings.SettingsFor(r.Context(), requestDomain(r))
+	u, err := h.repo.GetURLByCode(r.Context(), code)
+	if err != nil {
+		if common.IsNotFound(err) {
+			// The code may name a bundle rather than a single link.
+			if h.serveBundleFallback(w, r, code) {
+				return
+			}
+			h.abuse.RecordMiss(r.Context(), ipStr)
+			writeNotFound(w, settings)
+			return
+		}
+		log.Printf("handler: resolving %s: %v", code, err)
+		writeStatus(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	if u.Expired(time.Now().UTC()) || !u.Active() {
+		h.abuse.RecordMiss(r.Context(), ipStr)
+		writeNotFound(w, settings)
+		return
+	}
+	if !redirect.ApplyFrequencyCap(w, r, u) {
+		middleware.ObserveRedirect(http.StatusFound)
+		return
+	}
+	if u.Rotation != nil {
+		rotated := *u
+		rotated.LongURL = service.RotateDestination(r.Context(), h.rdb, u)
+		u = &rotated
+	}
+	variant := ""
+	if u.Experiment != nil {
+		// Same sticky assignment as the shortener: the same visitor gets
+		// the same arm regardless of which binary served them.
+		name, dest := service.AssignVariant(u.Experiment, u.Code, ipStr+"\x00"+r.UserAgent())
+		variant = name
+		exposed := *u
+		exposed.LongURL = dest
+		u = &exposed
+	}
+	status := http.StatusMovedPermanently
+	if settings.RedirectStatus != 0 {
+		status = settings.RedirectStatus
+	}
+	middleware.ObserveRedirect(status)
+	clickID := ""
+	if u.Redirect.AppendClickID {
+		clickID = redirect.NewClickID()
+		tagged := *u
+		tagged.LongURL = redirect.AppendQueryParam(u.LongURL, "um_cid", clickID)
+		u = &tagged
+	}
+	// The click record is this service's only write path, and it is
+	// asynchronous; the click counter on the URL document is left to the
+	// analytics rollups.
+	if !settings.AnalyticsDisabled {
+		h.clicks.Record(models.Click{
+			ClickID:    clickID,
+			Code:       u.Code,
+			Variant:    variant,
+			CampaignID: u.CampaignID,
+			Domain:     requestDomain(r),
+			OwnerID:    u.OwnerID,
+			OrgID:      u.OrgID,
+			IP:         ipStr,
+			UserAgent:  r.UserAgent(),
+			Referrer:   r.Referer(),
+		})
+	}
+	redirect.ServeWithStatus(w, r, u, status)
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/middleware"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+// The gateway forwards every non-/api/v1 path here, so the public
+// endpoints the shortener also serves — the error catalog, the CDN
+// resolve API and the impression pixel — must exist on this binary too or
+// they 404 for every gateway client.
+
+// impressionPixelGIF is the 1x1 transparent companion pixel.
+var impressionPixelGIF = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00,
+	0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x01, 0x00,
+	0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+// registerPublicRoutes mounts the unauthenticated public endpoints.
+func (h *Handler) registerPublicRoutes(root *mux.Router) {
+	root.HandleFunc("/api/errors", h.errorCatalog).Methods(http.MethodGet)
+	root.HandleFunc("/api/resolve/{code}", h.publicResolve).Methods(http.MethodGet)
+	root.HandleFunc("/{code}/pixel.gif", h.impressionPixel).Methods(http.MethodGet)
+}
+
+func (h *Handler) errorCatalog(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(common.ErrorCatalog())
+}
+
+// peek resolves an active link without counting a click.
+func (h *Handler) peek(r *http.Request, code string) (*models.URL, error) {
+	u, err := h.repo.GetURLByCode(r.Context(), h.canonicalCode(code))
+	if err != nil {
+		return nil, err
+	}
+	if u.Expired(time.Now().UTC()) || !u.Active() {
+		return nil, common.ErrNotFound
+	}
+	return u, nil
+}
+
+// publicResolve mirrors the shortener's read-only CDN resolve API.
+func (h *Handler) publicResolve(w http.ResponseWriter, r *http.Request) {
+	u, err := h.peek(r, mux.Vars(r)["code"])
+	if err != nil {
+		// Negative results cache briefly so enumeration scans mostly die
+		// at the edge.
+		w.Header().Set("Cache-Control", "public, max-age=60")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "not found"})
+		return
+	}
+	w.Header().Set("Cache-Control", "public, max-age=300, s-maxage=86400")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"code":     u.Code,
+		"long_url": u.LongURL,
+	})
+}
+
+// impressionPixel records an impression event for the link and always
+// serves the pixel, so JS-free open tracking works through the gateway.
+func (h *Handler) impressionPixel(w http.ResponseWriter, r *http.Request) {
+	if u, err := h.peek(r, mux.Vars(r)["code"]); err == nil {
+		ip := middleware.ClientIP(r)
+		ipStr := ""
+		if ip != nil {
+			ipStr = ip.String()
+		}
+		h.clicks.Record(models.Click{
+			Event:      models.ClickEventImpression,
+			Code:       u.Code,
+			CampaignID: u.CampaignID,
+			Domain:     requestDomain(r),
+			OwnerID:    u.OwnerID,
+			OrgID:      u.OrgID,
+			IP:         ipStr,
+			UserAgent:  r.UserAgent(),
+			Referrer:   r.Referer(),
+		})
+	}
+	w.Header().Set("Content-Type", "image/gif")
+	w.Header().Set("Cache-Control", "no-store, private")
+	w.Write(impressionPixelGIF)
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+)
+
+// The redirect service fronts all short-link domains through the gateway,
+// so it must serve the per-domain app association files too; the
+// management API keeps its copy for deployments that run without the
+// split binary.
+
+// requestDomain extracts the serving domain from the Host header.
+func requestDomain(r *http.Request) string {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(host)
+}
+
+// registerWellKnownRoutes mounts the app association documents for
+// universal/app links, keyed on the request Host.
+func (h *Handler) registerWellKnownRoutes(root *mux.Router) {
+	root.HandleFunc("/.well-known/apple-app-site-association", h.appleAssociation).Methods(http.MethodGet)
+	root.HandleFunc("/.well-known/assetlinks.json", h.androidAssetLinks).Methods(http.MethodGet)
+}
+
+// serveAssociation writes one stored association document, 404ing when
+// the serving domain is unregistered or has none configured.
+func (h *Handler) serveAssociation(w http.ResponseWriter, r *http.Request, pick func(apple, android string) string) {
+	d, err := h.domains.GetDomain(r.Context(), requestDomain(r))
+	doc := ""
+	if err == nil {
+		doc = pick(d.AppleAppSiteAssociation, d.AndroidAssetLinks)
+	}
+	if err != nil && !common.IsNotFound(err) {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "internal error"})
+		return
+	}
+	if doc == "" {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "not found"})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(doc))
+}
+
+func (h *Handler) appleAssociation(w http.ResponseWriter, r *http.Request) {
+	h.serveAssociation(w, r, func(apple, _ string) string { return apple })
+}
+
+func (h *Handler) androidAssetLinks(w http.ResponseWriter, r *http.Request) {
+	h.serveAssociation(w, r, func(_, android string) string { return android })
+}
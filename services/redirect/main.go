@@ -0,0 +1,102 @@
+// Command redirect runs the standalone redirect service: read-only,
+// cache-first resolution with asynchronous analytics as its only writes,
+// so it can scale independently of the management API.
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/fieldcrypt"
+	"github.com/ashtonholgate/url-minifier/pkg/ipclass"
+	"github.com/ashtonholgate/url-minifier/pkg/middleware"
+	"github.com/ashtonholgate/url-minifier/pkg/redirect"
+	"github.com/ashtonholgate/url-minifier/services/redirect/handler"
+	"github.com/ashtonholgate/url-minifier/services/shortener/abuse"
+	"github.com/ashtonholgate/url-minifier/services/shortener/clicks"
+	"github.com/ashtonholgate/url-minifier/services/shortener/repository"
+)
+
+func main() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	mongoURI := common.Getenv("MONGO_URI", "mongodb://localhost:27017")
+	redisAddr := common.Getenv("REDIS_ADDR", "localhost:6379")
+	listenAddr := common.Getenv("LISTEN_ADDR", ":8090")
+	if secret := common.Getenv("JWT_SECRET", ""); secret != "" {
+		redirect.SetFallbackSecret([]byte(secret))
+	}
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
+	if err != nil {
+		log.Fatalf("connecting to mongo: %v", err)
+	}
+	defer client.Disconnect(context.Background())
+
+	rdb := redis.NewClient(&redis.Options{Addr: redisAddr})
+	defer rdb.Close()
+
+	cacheClient := repository.NewCacheClient(common.Getenv("REDIS_CACHE_ADDRS", redisAddr))
+	repo := repository.NewCachedRepository(
+		repository.NewReadOnlyMongoRepository(client.Database("shortener")), cacheClient)
+	codec, err := fieldcrypt.FromEnv()
+	if err != nil {
+		log.Fatalf("initialising field encryption: %v", err)
+	}
+	if codec != nil {
+		repo = repository.NewEncryptedRepository(repo, codec)
+	}
+	if common.Getenv("BLOOM_PRECHECK", "") == "1" {
+		// This binary absorbs the enumeration scans, so the pre-check
+		// matters here most; the creates channel keeps it current with
+		// codes minted on the shortener instances.
+		var err error
+		repo, err = repository.NewBloomRepository(context.Background(), repo, client.Database("shortener"), rdb)
+		if err != nil {
+			log.Fatalf("building bloom pre-check: %v", err)
+		}
+	}
+	recorder := clicks.NewRecorder(client.Database("analytics"), rdb)
+	tracker := abuse.NewTracker(rdb)
+	if dbPath := common.Getenv("IP_CLASS_DB", ""); dbPath != "" {
+		classifier, err := ipclass.Load(dbPath)
+		if err != nil {
+			log.Fatalf("loading ip classification database: %v", err)
+		}
+		recorder.SetIPClassifier(classifier)
+		tracker.SetIPClassifier(classifier)
+	}
+	h := handler.New(repo,
+		recorder,
+		tracker,
+		repository.NewDomainRepository(client.Database("shortener")),
+		rdb)
+	if common.Getenv("CASE_INSENSITIVE_CODES", "") == "1" {
+		h.EnableCaseInsensitiveCodes()
+	}
+	h.SetBundleRepository(repository.NewBundleRepository(client.Database("shortener")))
+
+	root := mux.NewRouter()
+	root.Use(middleware.Metrics)
+	root.Handle("/metrics", promhttp.Handler()).Methods(http.MethodGet)
+	h.Register(root)
+
+	srv := &http.Server{
+		Addr:         listenAddr,
+		Handler:      root,
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 5 * time.Second,
+	}
+	log.Printf("redirect listening on %s", listenAddr)
+	log.Fatal(srv.ListenAndServe())
+}
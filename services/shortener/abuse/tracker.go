@@ -0,0 +1,155 @@
+// Package abuse detects short-code enumeration: clients probing many
+// nonexistent codes in a short window. Offenders are temporarily banned via
+// Redis so every instance enforces the same bans.
+package abuse
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/ashtonholgate/url-minifier/pkg/ipclass"
+)
+
+// Tunables for enumeration detection. A legitimate user mistyping a code
+// produces a handful of misses; a scanner produces hundreds.
+const (
+	missWindow    = 10 * time.Minute
+	missThreshold = 50
+	banTTL        = time.Hour
+)
+
+// offendersKey ranks IPs by recent misses; bansKey records active bans with
+// their expiry as the score.
+const (
+	offendersKey = "abuse:offenders"
+	bansKey      = "abuse:bans"
+)
+
+// Ban describes one active ban for the admin API.
+type Ban struct {
+	IP        string    `json:"ip"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Offender is an IP with recent 404 misses.
+type Offender struct {
+	IP     string `json:"ip"`
+	Misses int64  `json:"misses"`
+}
+
+// Tracker counts misses and manages bans.
+type Tracker struct {
+	rdb     *redis.Client
+	ipclass *ipclass.Classifier
+}
+
+// NewTracker returns a Tracker using rdb.
+func NewTracker(rdb *redis.Client) *Tracker {
+	return &Tracker{rdb: rdb}
+}
+
+// SetIPClassifier weights misses by network type: datacenter scanners
+// reach the ban threshold twice as fast as residential clients.
+func (t *Tracker) SetIPClassifier(c *ipclass.Classifier) { t.ipclass = c }
+
+// missWeight is how much one miss counts toward the threshold.
+func (t *Tracker) missWeight(ip string) int64 {
+	if t.ipclass == nil {
+		return 1
+	}
+	if t.ipclass.Classify(net.ParseIP(ip)).Network == ipclass.NetworkDatacenter {
+		return 2
+	}
+	return 1
+}
+
+func missKey(ip string) string { return "abuse:miss:" + ip }
+func banKey(ip string) string  { return "abuse:ban:" + ip }
+
+// RecordMiss notes a 404 redirect from ip and bans it once it crosses the
+// threshold within the window. Errors are logged and swallowed: telemetry
+// must not affect the visitor path.
+func (t *Tracker) RecordMiss(ctx context.Context, ip string) {
+	if ip == "" {
+		return
+	}
+	weight := t.missWeight(ip)
+	pipe := t.rdb.Pipeline()
+	count := pipe.IncrBy(ctx, missKey(ip), weight)
+	pipe.ExpireNX(ctx, missKey(ip), missWindow)
+	pipe.ZIncrBy(ctx, offendersKey, float64(weight), ip)
+	pipe.Expire(ctx, offendersKey, missWindow)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("abuse: recording miss for %s: %v", ip, err)
+		return
+	}
+	if count.Val() >= missThreshold {
+		t.ban(ctx, ip)
+	}
+}
+
+func (t *Tracker) ban(ctx context.Context, ip string) {
+	expiry := time.Now().Add(banTTL)
+	pipe := t.rdb.Pipeline()
+	pipe.Set(ctx, banKey(ip), 1, banTTL)
+	pipe.ZAdd(ctx, bansKey, redis.Z{Score: float64(expiry.Unix()), Member: ip})
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("abuse: banning %s: %v", ip, err)
+		return
+	}
+	log.Printf("abuse: banned %s until %s", ip, expiry.Format(time.RFC3339))
+}
+
+// Banned reports whether ip is currently banned. Redis errors fail open so
+// an outage never blocks legitimate traffic.
+func (t *Tracker) Banned(ctx context.Context, ip string) bool {
+	if ip == "" {
+		return false
+	}
+	n, err := t.rdb.Exists(ctx, banKey(ip)).Result()
+	if err != nil {
+		log.Printf("abuse: checking ban for %s: %v", ip, err)
+		return false
+	}
+	return n > 0
+}
+
+// Bans lists active bans, pruning expired entries from the index as a side
+// effect.
+func (t *Tracker) Bans(ctx context.Context) ([]Ban, error) {
+	now := time.Now()
+	if err := t.rdb.ZRemRangeByScore(ctx, bansKey, "-inf",
+		strconv.FormatInt(now.Unix(), 10)).Err(); err != nil {
+		return nil, fmt.Errorf("pruning bans: %w", err)
+	}
+	entries, err := t.rdb.ZRangeWithScores(ctx, bansKey, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("listing bans: %w", err)
+	}
+	out := make([]Ban, 0, len(entries))
+	for _, e := range entries {
+		ip, _ := e.Member.(string)
+		out = append(out, Ban{IP: ip, ExpiresAt: time.Unix(int64(e.Score), 0).UTC()})
+	}
+	return out, nil
+}
+
+// Offenders lists the top IPs by recent misses.
+func (t *Tracker) Offenders(ctx context.Context) ([]Offender, error) {
+	entries, err := t.rdb.ZRevRangeWithScores(ctx, offendersKey, 0, 99).Result()
+	if err != nil {
+		return nil, fmt.Errorf("listing offenders: %w", err)
+	}
+	out := make([]Offender, 0, len(entries))
+	for _, e := range entries {
+		ip, _ := e.Member.(string)
+		out = append(out, Offender{IP: ip, Misses: int64(e.Score)})
+	}
+	return out, nil
+}
@@ -0,0 +1,97 @@
+// Package activity maintains a per-user feed of recent link events for the
+// dashboard home screen. Events live in a capped Redis stream per user, so
+// the feed is cheap to append to and reads newest-first without indexes.
+package activity
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Event types surfaced in the feed.
+const (
+	EventCreated      = "created"
+	EventEdited       = "edited"
+	EventDeleted      = "deleted"
+	EventMilestone    = "click_milestone"
+	EventExpiringSoon = "expiring_soon"
+	EventFlagged      = "flagged"
+)
+
+// feedMaxLen caps each user's stream; older events fall off.
+const feedMaxLen = 200
+
+// Event is one feed entry.
+type Event struct {
+	Type    string    `json:"type"`
+	Code    string    `json:"code"`
+	Message string    `json:"message,omitempty"`
+	At      time.Time `json:"at"`
+}
+
+// Feed records and reads per-user activity.
+type Feed struct {
+	rdb *redis.Client
+}
+
+// NewFeed returns a Feed backed by rdb.
+func NewFeed(rdb *redis.Client) *Feed {
+	return &Feed{rdb: rdb}
+}
+
+func feedKey(userID string) string { return "activity:" + userID }
+
+// Record appends an event to the user's feed. Failures are logged and
+// swallowed; the feed is advisory.
+func (f *Feed) Record(ctx context.Context, userID string, e Event) {
+	if userID == "" {
+		return
+	}
+	err := f.rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: feedKey(userID),
+		MaxLen: feedMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{
+			"type":    e.Type,
+			"code":    e.Code,
+			"message": e.Message,
+			"at":      strconv.FormatInt(time.Now().UTC().UnixMilli(), 10),
+		},
+	}).Err()
+	if err != nil {
+		log.Printf("activity: recording %s for %s: %v", e.Type, userID, err)
+	}
+}
+
+// Recent returns up to limit newest events for the user.
+func (f *Feed) Recent(ctx context.Context, userID string, limit int64) ([]Event, error) {
+	entries, err := f.rdb.XRevRangeN(ctx, feedKey(userID), "+", "-", limit).Result()
+	if err != nil {
+		return nil, fmt.Errorf("reading feed: %w", err)
+	}
+	out := make([]Event, 0, len(entries))
+	for _, entry := range entries {
+		e := Event{}
+		if v, ok := entry.Values["type"].(string); ok {
+			e.Type = v
+		}
+		if v, ok := entry.Values["code"].(string); ok {
+			e.Code = v
+		}
+		if v, ok := entry.Values["message"].(string); ok {
+			e.Message = v
+		}
+		if v, ok := entry.Values["at"].(string); ok {
+			if ms, err := strconv.ParseInt(v, 10, 64); err == nil {
+				e.At = time.UnixMilli(ms).UTC()
+			}
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
@@ -0,0 +1,125 @@
+// Package clicks writes raw click events for the analytics service. The
+// redirect path records asynchronously and best-effort: analytics must never
+// slow down or fail a redirect.
+package clicks
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/ashtonholgate/url-minifier/pkg/ipclass"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+	"github.com/ashtonholgate/url-minifier/pkg/uniques"
+	"github.com/ashtonholgate/url-minifier/pkg/useragent"
+)
+
+// defaultAttributionWindow is how long a visitor's repeat clicks on the
+// same link count as the same attributed click.
+const defaultAttributionWindow = 24 * time.Hour
+
+// Recorder writes click events into the analytics clicks collection and
+// feeds the per-link unique-visitor HyperLogLogs.
+type Recorder struct {
+	clicks  *mongo.Collection
+	rdb     *redis.Client
+	window  time.Duration
+	ipclass *ipclass.Classifier
+}
+
+// NewRecorder returns a Recorder writing to db's clicks collection; db
+// should be the analytics database.
+func NewRecorder(db *mongo.Database, rdb *redis.Client) *Recorder {
+	return &Recorder{
+		clicks: db.Collection("clicks"),
+		rdb:    rdb,
+		window: defaultAttributionWindow,
+	}
+}
+
+// SetIPClassifier attaches network-type classification to recorded
+// events.
+func (r *Recorder) SetIPClassifier(c *ipclass.Classifier) { r.ipclass = c }
+
+// SetAttributionWindow overrides the dedup window.
+func (r *Recorder) SetAttributionWindow(w time.Duration) {
+	if w > 0 {
+		r.window = w
+	}
+}
+
+// fingerprint derives a stable visitor hash from IP and user agent. Coarse
+// by design: it only needs to catch the same person re-clicking, not
+// identify anyone.
+func fingerprint(ip, userAgent string) string {
+	sum := sha256.Sum256([]byte(ip + "\x00" + userAgent))
+	return hex.EncodeToString(sum[:16])
+}
+
+// replayWindow is how long an identical click event is considered a
+// replay of the first one. Long enough to absorb client retry storms and
+// proxy retries, short enough that a genuine re-click minutes later still
+// counts as raw traffic.
+const replayWindow = 10 * time.Second
+
+// isReplay dedupes identical events (same link, visitor fingerprint and
+// referrer) within the replay window via SETNX, so retried or replayed
+// requests during network flaps don't inflate counts. Redis errors fail
+// open and the event is recorded.
+func (r *Recorder) isReplay(ctx context.Context, c *models.Click) bool {
+	sum := sha256.Sum256([]byte(c.Code + "\x00" + c.Fingerprint + "\x00" + c.Referrer))
+	key := "clickdedup:" + hex.EncodeToString(sum[:16])
+	fresh, err := r.rdb.SetNX(ctx, key, 1, replayWindow).Result()
+	if err != nil {
+		log.Printf("clicks: replay check on %s: %v", c.Code, err)
+		return false
+	}
+	return !fresh
+}
+
+// firstInWindow reports whether this fingerprint's click on code is the
+// first within the attribution window, using SETNX so concurrent clicks
+// race safely. Redis errors count the click as unique: over-counting beats
+// silently dropping attributed clicks.
+func (r *Recorder) firstInWindow(ctx context.Context, code, fp string) bool {
+	ok, err := r.rdb.SetNX(ctx, "attrib:"+code+":"+fp, 1, r.window).Result()
+	if err != nil {
+		log.Printf("clicks: attribution check on %s: %v", code, err)
+		return true
+	}
+	return ok
+}
+
+// Record writes the click in a background goroutine with its own timeout,
+// detached from the request context so a fast redirect response does not
+// cancel the insert.
+func (r *Recorder) Record(c models.Click) {
+	if c.At.IsZero() {
+		c.At = time.Now().UTC()
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		ua := useragent.Parse(c.UserAgent)
+		c.Browser, c.OS, c.Device = ua.Browser, ua.OS, ua.Device
+		class := r.ipclass.Classify(net.ParseIP(c.IP))
+		c.Network, c.ASN = class.Network, class.ASN
+		c.Fingerprint = fingerprint(c.IP, c.UserAgent)
+		if r.isReplay(ctx, &c) {
+			return
+		}
+		c.Unique = r.firstInWindow(ctx, c.Code, c.Fingerprint)
+		if _, err := r.clicks.InsertOne(ctx, c); err != nil {
+			log.Printf("clicks: recording click on %s: %v", c.Code, err)
+		}
+		if err := uniques.Add(ctx, r.rdb, c.Code, c.IP, c.At); err != nil {
+			log.Printf("clicks: counting unique visitor on %s: %v", c.Code, err)
+		}
+	}()
+}
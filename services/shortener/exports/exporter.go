@@ -0,0 +1,220 @@
+// Package exports delivers nightly dumps of an org's links and click
+// aggregates to the org's own S3 bucket. Credentials come from the
+// deployment's secrets provider (environment-injected), referenced by name
+// in the export configuration so nothing sensitive lands in MongoDB.
+package exports
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/fieldcrypt"
+)
+
+// Config is one org's export destination.
+type Config struct {
+	ID     string `bson:"_id,omitempty" json:"id"`
+	OrgID  string `bson:"org_id" json:"org_id"`
+	Bucket string `bson:"bucket" json:"bucket"`
+	Prefix string `bson:"prefix,omitempty" json:"prefix,omitempty"`
+	Region string `bson:"region" json:"region"`
+	// RoleARN, when set, is assumed for delivery; otherwise the
+	// CredentialsRef names the access key pair in the secrets provider
+	// (environment variables <REF>_ACCESS_KEY_ID / <REF>_SECRET_KEY).
+	RoleARN        string `bson:"role_arn,omitempty" json:"role_arn,omitempty"`
+	CredentialsRef string `bson:"credentials_ref,omitempty" json:"credentials_ref,omitempty"`
+}
+
+// Receipt records one delivery attempt for auditability.
+type Receipt struct {
+	OrgID string    `bson:"org_id" json:"org_id"`
+	At    time.Time `bson:"at" json:"at"`
+	Key   string    `bson:"key" json:"key"`
+	Bytes int       `bson:"bytes" json:"bytes"`
+	Error string    `bson:"error,omitempty" json:"error,omitempty"`
+}
+
+// Exporter runs the nightly job.
+type Exporter struct {
+	configs  *mongo.Collection
+	receipts *mongo.Collection
+	urls     *mongo.Collection
+	rollups  *mongo.Collection
+	codec    *fieldcrypt.Codec
+}
+
+// New returns an Exporter over the shortener and analytics databases,
+// decrypting destinations with codec when the deployment encrypts at rest
+// (nil when it doesn't).
+func New(shortenerDB, analyticsDB *mongo.Database, codec *fieldcrypt.Codec) *Exporter {
+	return &Exporter{
+		configs:  shortenerDB.Collection("export_configs"),
+		receipts: shortenerDB.Collection("export_receipts"),
+		urls:     shortenerDB.Collection("urls"),
+		rollups:  analyticsDB.Collection("click_rollups"),
+		codec:    codec,
+	}
+}
+
+// Start runs one export sweep per night (around 03:00 UTC) until ctx is
+// cancelled.
+func (e *Exporter) Start(ctx context.Context) {
+	go func() {
+		for {
+			next := nextRun(time.Now().UTC())
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Until(next)):
+				e.runAll(ctx)
+			}
+		}
+	}()
+}
+
+func nextRun(now time.Time) time.Time {
+	run := time.Date(now.Year(), now.Month(), now.Day(), 3, 0, 0, 0, time.UTC)
+	if !run.After(now) {
+		run = run.AddDate(0, 0, 1)
+	}
+	return run
+}
+
+func (e *Exporter) runAll(ctx context.Context) {
+	cur, err := e.configs.Find(ctx, bson.M{})
+	if err != nil {
+		log.Printf("exports: listing configs: %v", err)
+		return
+	}
+	defer cur.Close(ctx)
+	for cur.Next(ctx) {
+		var cfg Config
+		if err := cur.Decode(&cfg); err != nil {
+			log.Printf("exports: decoding config: %v", err)
+			continue
+		}
+		receipt := Receipt{OrgID: cfg.OrgID, At: time.Now().UTC()}
+		receipt.Key, receipt.Bytes, err = e.deliver(ctx, cfg)
+		if err != nil {
+			receipt.Error = err.Error()
+			log.Printf("exports: delivering for org %s: %v", cfg.OrgID, err)
+		}
+		if _, err := e.receipts.InsertOne(ctx, receipt); err != nil {
+			log.Printf("exports: writing receipt: %v", err)
+		}
+	}
+}
+
+// deliver builds the export archive and uploads it, returning the object
+// key and size.
+func (e *Exporter) deliver(ctx context.Context, cfg Config) (string, int, error) {
+	body, err := e.buildArchive(ctx, cfg.OrgID)
+	if err != nil {
+		return "", 0, err
+	}
+	client, err := s3Client(cfg)
+	if err != nil {
+		return "", 0, err
+	}
+	key := fmt.Sprintf("%surl-minifier/%s.ndjson.gz",
+		cfg.Prefix, time.Now().UTC().Format("2006-01-02"))
+	_, err = client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(cfg.Bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String("application/gzip"),
+	})
+	if err != nil {
+		return key, 0, fmt.Errorf("uploading to s3: %w", err)
+	}
+	return key, len(body), nil
+}
+
+// buildArchive streams the org's links and click rollups into one gzip
+// NDJSON blob.
+func (e *Exporter) buildArchive(ctx context.Context, orgID string) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	enc := json.NewEncoder(gz)
+	for _, source := range []struct {
+		kind string
+		coll *mongo.Collection
+	}{{"url", e.urls}, {"click_rollup", e.rollups}} {
+		cur, err := source.coll.Find(ctx, bson.M{"org_id": orgID})
+		if err != nil {
+			return nil, fmt.Errorf("reading %s documents: %w", source.kind, err)
+		}
+		for cur.Next(ctx) {
+			var doc bson.M
+			if err := bson.Unmarshal(cur.Current, &doc); err != nil {
+				cur.Close(ctx)
+				return nil, err
+			}
+			// The raw collection read bypasses the encryption decorator;
+			// a customer export full of ciphertext is unusable.
+			if source.kind == "url" {
+				if sealed, ok := doc["long_url"].(string); ok {
+					plain, err := e.codec.DecryptIfConfigured(sealed)
+					if err != nil {
+						cur.Close(ctx)
+						return nil, fmt.Errorf("decrypting destination: %w", err)
+					}
+					doc["long_url"] = plain
+				}
+			}
+			raw, err := bson.MarshalExtJSON(doc, true, false)
+			if err != nil {
+				cur.Close(ctx)
+				return nil, err
+			}
+			if err := enc.Encode(map[string]json.RawMessage{source.kind: raw}); err != nil {
+				cur.Close(ctx)
+				return nil, err
+			}
+		}
+		err = cur.Err()
+		cur.Close(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// s3Client builds a client for the config's credential mode.
+func s3Client(cfg Config) (*s3.S3, error) {
+	sess, err := session.NewSession(aws.NewConfig().WithRegion(cfg.Region))
+	if err != nil {
+		return nil, fmt.Errorf("creating aws session: %w", err)
+	}
+	awsCfg := aws.NewConfig()
+	switch {
+	case cfg.RoleARN != "":
+		awsCfg = awsCfg.WithCredentials(stscreds.NewCredentials(sess, cfg.RoleARN))
+	case cfg.CredentialsRef != "":
+		id := common.Getenv(cfg.CredentialsRef+"_ACCESS_KEY_ID", "")
+		secret := common.Getenv(cfg.CredentialsRef+"_SECRET_KEY", "")
+		if id == "" || secret == "" {
+			return nil, fmt.Errorf("credentials %q not present in secrets provider", cfg.CredentialsRef)
+		}
+		awsCfg = awsCfg.WithCredentials(credentials.NewStaticCredentials(id, secret, ""))
+	}
+	return s3.New(sess, awsCfg), nil
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+	"github.com/ashtonholgate/url-minifier/services/shortener/service"
+)
+
+// Bitly v4 compatibility shim. Implements the endpoints migrating
+// customers actually use — shorten, bitlink lookup and click summary — so
+// integrations switch by changing their base URL. Everything else of the
+// Bitly surface intentionally 404s.
+
+// bitlink is the subset of Bitly's bitlink representation we can populate.
+type bitlink struct {
+	ID        string    `json:"id"`
+	Link      string    `json:"link"`
+	LongURL   string    `json:"long_url"`
+	CreatedAt time.Time `json:"created_at"`
+	Tags      []string  `json:"tags,omitempty"`
+}
+
+// RegisterBitlyRoutes mounts the shim on an authenticated router rooted at
+// /v4.
+func (h *Handler) RegisterBitlyRoutes(v4 *mux.Router) {
+	v4.HandleFunc("/shorten", h.bitlyShorten).Methods(http.MethodPost)
+	v4.HandleFunc("/bitlinks/{domain}/{code}", h.bitlyGetBitlink).Methods(http.MethodGet)
+	v4.HandleFunc("/bitlinks/{domain}/{code}/clicks", h.bitlyClicks).Methods(http.MethodGet)
+}
+
+// bitlinkFor renders a link in Bitly's shape under the requested host.
+func bitlinkFor(r *http.Request, u *models.URL) bitlink {
+	domain := requestDomain(r)
+	return bitlink{
+		ID:        domain + "/" + u.Code,
+		Link:      "https://" + domain + "/" + u.Code,
+		LongURL:   u.LongURL,
+		CreatedAt: u.CreatedAt,
+		Tags:      u.Tags,
+	}
+}
+
+func (h *Handler) bitlyShorten(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		LongURL string `json:"long_url"`
+		// Domain and group_guid are accepted and ignored: we serve on the
+		// request host and have no group concept.
+		Domain    string `json:"domain,omitempty"`
+		GroupGUID string `json:"group_guid,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "malformed body"})
+		return
+	}
+	u, err := h.svc.CreateURL(r.Context(), pr, service.CreateURLRequest{LongURL: req.LongURL})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, bitlinkFor(r, u))
+}
+
+// bitlyCode extracts our short code from the bitlink path, tolerating any
+// domain segment since migrated IDs keep their old domain.
+func bitlyCode(r *http.Request) string {
+	return strings.TrimSpace(mux.Vars(r)["code"])
+}
+
+func (h *Handler) bitlyGetBitlink(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	u, err := h.svc.GetURL(r.Context(), pr, bitlyCode(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, bitlinkFor(r, u))
+}
+
+func (h *Handler) bitlyClicks(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	u, err := h.svc.GetURL(r.Context(), pr, bitlyCode(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	// Bitly returns a time-bucketed series; we return a single summary
+	// bucket, which is what migrating dashboards read first.
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"unit":           "day",
+		"units":          -1,
+		"unit_reference": time.Now().UTC().Format(time.RFC3339),
+		"link_clicks": []map[string]interface{}{
+			{"date": u.UpdatedAt.UTC().Format(time.RFC3339), "clicks": u.Clicks},
+		},
+	})
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ashtonholgate/url-minifier/services/shortener/service"
+)
+
+// registerBundleRoutes mounts the bundle CRUD API and the public item
+// redirect.
+func (h *Handler) registerBundleRoutes(api *mux.Router, redirect *mux.Router) {
+	api.HandleFunc("/bundles", h.createBundle).Methods(http.MethodPost)
+	api.HandleFunc("/bundles", h.listBundles).Methods(http.MethodGet)
+	api.HandleFunc("/bundles/{code}", h.getBundle).Methods(http.MethodGet)
+	api.HandleFunc("/bundles/{code}", h.updateBundle).Methods(http.MethodPut)
+	api.HandleFunc("/bundles/{code}", h.deleteBundle).Methods(http.MethodDelete)
+	redirect.HandleFunc("/{code}/items/{index:[0-9]+}", h.bundleItem).Methods(http.MethodGet)
+}
+
+func (h *Handler) createBundle(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	var req service.BundleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "malformed body"})
+		return
+	}
+	b, err := h.svc.CreateBundle(r.Context(), pr, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, b)
+}
+
+func (h *Handler) listBundles(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	bundles, err := h.svc.ListBundles(r.Context(), pr)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, bundles)
+}
+
+func (h *Handler) getBundle(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	b, err := h.svc.GetBundle(r.Context(), pr, mux.Vars(r)["code"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, b)
+}
+
+func (h *Handler) updateBundle(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	var req service.BundleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "malformed body"})
+		return
+	}
+	b, err := h.svc.UpdateBundle(r.Context(), pr, mux.Vars(r)["code"], req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, b)
+}
+
+func (h *Handler) deleteBundle(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	if err := h.svc.DeleteBundle(r.Context(), pr, mux.Vars(r)["code"]); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) bundleItem(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	index, err := strconv.Atoi(vars["index"])
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "malformed index"})
+		return
+	}
+	dest, err := h.svc.ResolveBundleItem(r.Context(), vars["code"], index)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	http.Redirect(w, r, dest, http.StatusFound)
+}
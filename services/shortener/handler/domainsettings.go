@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ashtonholgate/url-minifier/pkg/auth"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+	"github.com/ashtonholgate/url-minifier/services/shortener/repository"
+)
+
+// registerDomainSettingsRoutes mounts the per-domain settings API.
+func (h *Handler) registerDomainSettingsRoutes(api *mux.Router) {
+	h.settingsCache = repository.NewDomainSettingsCache(h.domains)
+	api.HandleFunc("/domains/{domain}/settings", h.updateDomainSettings).Methods(http.MethodPut)
+}
+
+// domainSettings returns the serving domain's settings, memoised; unknown
+// domains get the zero value.
+func (h *Handler) domainSettings(r *http.Request) models.DomainSettings {
+	return h.settingsCache.SettingsFor(r.Context(), requestDomain(r))
+}
+
+func (h *Handler) updateDomainSettings(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	name := mux.Vars(r)["domain"]
+	d, err := h.domains.GetDomain(r.Context(), name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := auth.Authorize(pr, auth.PermAdmin, auth.Resource{OrgID: d.OrgID}); err != nil {
+		writeError(w, err)
+		return
+	}
+	var settings models.DomainSettings
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "malformed body"})
+		return
+	}
+	if settings.RedirectStatus != 0 &&
+		settings.RedirectStatus != http.StatusMovedPermanently &&
+		settings.RedirectStatus != http.StatusFound {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "redirect_status must be 301 or 302"})
+		return
+	}
+	if err := h.domains.UpdateSettings(r.Context(), name, settings); err != nil {
+		writeError(w, err)
+		return
+	}
+	h.settingsCache.Invalidate(name)
+	w.WriteHeader(http.StatusNoContent)
+}
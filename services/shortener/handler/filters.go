@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+	"github.com/ashtonholgate/url-minifier/services/shortener/service"
+)
+
+// registerFilterRoutes mounts the saved-filter (smart list) API.
+func (h *Handler) registerFilterRoutes(api *mux.Router) {
+	api.HandleFunc("/filters", h.saveFilter).Methods(http.MethodPost)
+	api.HandleFunc("/filters", h.listFilters).Methods(http.MethodGet)
+	api.HandleFunc("/filters/{name}", h.deleteFilter).Methods(http.MethodDelete)
+	api.HandleFunc("/filters/{name}/run", h.runFilter).Methods(http.MethodGet)
+	api.HandleFunc("/urls/search", h.searchURLs).Methods(http.MethodPost)
+	api.HandleFunc("/urls/bulk", h.bulkOperation).Methods(http.MethodPost)
+}
+
+func (h *Handler) bulkOperation(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	var req service.BulkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "malformed body"})
+		return
+	}
+	res, err := h.svc.BulkOperation(r.Context(), pr, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h *Handler) saveFilter(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		Name   string            `json:"name"`
+		Filter models.LinkFilter `json:"filter"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "malformed body"})
+		return
+	}
+	saved, err := h.svc.SaveFilter(r.Context(), pr, req.Name, req.Filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, saved)
+}
+
+func (h *Handler) listFilters(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	filters, err := h.svc.ListFilters(r.Context(), pr)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, filters)
+}
+
+func (h *Handler) deleteFilter(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	if err := h.svc.DeleteFilter(r.Context(), pr, mux.Vars(r)["name"]); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) runFilter(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	urls, err := h.svc.RunFilter(r.Context(), pr, mux.Vars(r)["name"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, urls)
+}
+
+// searchURLs runs an ad-hoc filter without saving it.
+func (h *Handler) searchURLs(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	var f models.LinkFilter
+	if err := json.NewDecoder(r.Body).Decode(&f); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "malformed body"})
+		return
+	}
+	urls, err := h.svc.FindURLs(r.Context(), pr, f)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, urls)
+}
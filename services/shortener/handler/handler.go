@@ -0,0 +1,944 @@
+// Package handler exposes the shortener service over HTTP. Handlers decode
+// and encode; all business rules and authorization live in the service
+// layer.
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/ashtonholgate/url-minifier/pkg/auth"
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/fieldcrypt"
+	"github.com/ashtonholgate/url-minifier/pkg/metering"
+	"github.com/ashtonholgate/url-minifier/pkg/middleware"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+	"github.com/ashtonholgate/url-minifier/pkg/plans"
+	"github.com/ashtonholgate/url-minifier/pkg/redirect"
+	"github.com/ashtonholgate/url-minifier/services/shortener/abuse"
+	"github.com/ashtonholgate/url-minifier/services/shortener/clicks"
+	"github.com/ashtonholgate/url-minifier/services/shortener/policy"
+	"github.com/ashtonholgate/url-minifier/services/shortener/repository"
+	"github.com/ashtonholgate/url-minifier/services/shortener/service"
+)
+
+// Handler serves the shortener HTTP API.
+type Handler struct {
+	svc           *service.Service
+	meter         *metering.Meter
+	verifier      *service.DomainVerifier
+	policies      *policy.Store
+	abuse         *abuse.Tracker
+	domains       *repository.DomainRepository
+	clicks        *clicks.Recorder
+	maintenance   *middleware.MaintenanceStore
+	adviceDB      *mongo.Database
+	qrSheetDir    string
+	settingsCache *repository.DomainSettingsCache
+	integrations  *repository.IntegrationRepository
+	limiter       *middleware.RateLimiter
+	ent           plans.Entitlements
+	codec         *fieldcrypt.Codec
+}
+
+// SetRateLimiter attaches the limiter backing the limits discovery
+// endpoint.
+func (h *Handler) SetRateLimiter(l *middleware.RateLimiter, ent plans.Entitlements) {
+	h.limiter, h.ent = l, ent
+}
+
+// SetAdviceDatabase points the admin index-advice endpoint at the
+// shortener database.
+func (h *Handler) SetAdviceDatabase(db *mongo.Database) { h.adviceDB = db }
+
+// SetFieldCodec attaches the deployment's field-encryption codec for
+// endpoints that read the raw collection (the edge delta feed); nil means
+// encryption is off.
+func (h *Handler) SetFieldCodec(codec *fieldcrypt.Codec) { h.codec = codec }
+
+// SetClickRecorder attaches raw click-event recording for analytics.
+func (h *Handler) SetClickRecorder(rec *clicks.Recorder) { h.clicks = rec }
+
+// New returns a Handler backed by svc, metering billable operations through
+// meter and running domain verification through verifier.
+func New(svc *service.Service, meter *metering.Meter, verifier *service.DomainVerifier, policies *policy.Store, tracker *abuse.Tracker, domains *repository.DomainRepository) *Handler {
+	return &Handler{svc: svc, meter: meter, verifier: verifier, policies: policies, abuse: tracker, domains: domains}
+}
+
+// Register mounts the API and redirect routes on r. The api subrouter must
+// already carry authentication middleware.
+func (h *Handler) Register(api *mux.Router, redirect *mux.Router) {
+	api.HandleFunc("/urls", h.createURL).Methods(http.MethodPost)
+	api.HandleFunc("/urls", h.listURLs).Methods(http.MethodGet)
+	api.HandleFunc("/urls/{code}", h.getURL).Methods(http.MethodGet)
+	api.HandleFunc("/urls/{code}", h.updateURL).Methods(http.MethodPatch)
+	api.HandleFunc("/urls/{code}", h.deleteURL).Methods(http.MethodDelete)
+	api.HandleFunc("/urls/{code}/revisions", h.urlRevisions).Methods(http.MethodGet)
+	api.HandleFunc("/urls/by-external-id/{system}/{id}", h.getURLByExternalID).Methods(http.MethodGet)
+	api.HandleFunc("/urls/{code}/qa", h.runQAChecklist).Methods(http.MethodPost)
+	api.HandleFunc("/urls/{code}/resolve-chain", h.resolveChain).Methods(http.MethodPost)
+	api.HandleFunc("/usage", h.usage).Methods(http.MethodGet)
+	api.HandleFunc("/aliases/{alias}/availability", h.aliasAvailability).Methods(http.MethodGet)
+	api.HandleFunc("/aliases/{alias}/reservation", h.reserveAlias).Methods(http.MethodPut)
+	api.HandleFunc("/aliases/{alias}/reservation", h.releaseAlias).Methods(http.MethodDelete)
+	api.HandleFunc("/domains", h.registerDomain).Methods(http.MethodPost)
+	api.HandleFunc("/domains/{domain}/verify", h.verifyDomain).Methods(http.MethodPost)
+	api.HandleFunc("/brand-terms", h.registerBrandTerm).Methods(http.MethodPost)
+	api.HandleFunc("/policies", h.listPolicies).Methods(http.MethodGet)
+	api.HandleFunc("/policies", h.createPolicy).Methods(http.MethodPost)
+	api.HandleFunc("/policies/{id}", h.deletePolicy).Methods(http.MethodDelete)
+	api.HandleFunc("/moderation/pending", h.pendingURLs).Methods(http.MethodGet)
+	api.HandleFunc("/moderation/decide", h.decideURLs).Methods(http.MethodPost)
+	api.HandleFunc("/abuse/bans", h.abuseBans).Methods(http.MethodGet)
+	api.HandleFunc("/abuse/offenders", h.abuseOffenders).Methods(http.MethodGet)
+	api.HandleFunc("/activity", h.activityFeed).Methods(http.MethodGet)
+	api.HandleFunc("/sync", h.sync).Methods(http.MethodGet)
+	api.HandleFunc("/link-health", h.linkHealth).Methods(http.MethodGet)
+	api.HandleFunc("/limits", h.apiLimits).Methods(http.MethodGet)
+	api.HandleFunc("/campaigns", h.createCampaign).Methods(http.MethodPost)
+	api.HandleFunc("/campaigns", h.listCampaigns).Methods(http.MethodGet)
+	api.HandleFunc("/campaigns/{id}", h.deleteCampaign).Methods(http.MethodDelete)
+	h.registerBundleRoutes(api, redirect)
+	h.registerFilterRoutes(api)
+	h.registerWorkspaceRoutes(api)
+	h.registerQRSheetRoutes(api, common.Getenv("QR_SHEET_DIR", "/var/lib/url-minifier/qr-sheets"))
+	h.registerQRRoutes(api)
+	h.registerDomainSettingsRoutes(api)
+	h.registerWellKnownRoutes(api, redirect)
+	// The error catalog is public documentation, not data.
+	redirect.HandleFunc("/api/errors", h.errorCatalog).Methods(http.MethodGet)
+	redirect.HandleFunc("/api/resolve/{code}", h.publicResolve).Methods(http.MethodGet)
+	redirect.HandleFunc("/{code}/pixel.gif", h.impressionPixel).Methods(http.MethodGet)
+	redirect.HandleFunc("/{code}", h.redirect).Methods(http.MethodGet)
+}
+
+// writeJSON writes v with the given status, logging encode failures.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("handler: encoding response: %v", err)
+	}
+}
+
+// errorResponse is the uniform error payload.
+type errorResponse struct {
+	Error string `json:"error"`
+	// Suggestions carries "did you mean" codes on redirect 404s.
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// writeError maps service errors onto status codes.
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, common.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "not found"})
+	case errors.Is(err, common.ErrConflict):
+		writeJSON(w, http.StatusConflict, errorResponse{Error: "conflict"})
+	case errors.Is(err, common.ErrInvalidInput):
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+	case errors.Is(err, auth.ErrForbidden):
+		writeJSON(w, http.StatusForbidden, errorResponse{Error: "forbidden"})
+	case errors.Is(err, plans.ErrUpgradeRequired):
+		writeJSON(w, http.StatusPaymentRequired, errorResponse{Error: err.Error()})
+	case errors.Is(err, policy.ErrDenied):
+		writeJSON(w, http.StatusUnprocessableEntity, errorResponse{Error: err.Error()})
+	default:
+		log.Printf("handler: internal error: %v", err)
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "internal error"})
+	}
+}
+
+// principal fetches the authenticated principal, writing a 401 when absent.
+func principal(w http.ResponseWriter, r *http.Request) (auth.Principal, bool) {
+	pr, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "unauthenticated"})
+	}
+	return pr, ok
+}
+
+func (h *Handler) createURL(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	var req service.CreateURLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "malformed body"})
+		return
+	}
+	if dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run")); dryRun {
+		res, err := h.svc.ValidateURLRequest(r.Context(), pr, req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, res)
+		return
+	}
+	u, err := h.svc.CreateURL(r.Context(), pr, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	h.meter.Record(r.Context(), pr.OrgID, pr.UserID, metering.OpCreate)
+	writeJSON(w, http.StatusCreated, u)
+}
+
+func (h *Handler) listURLs(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 64)
+	offset, _ := strconv.ParseInt(r.URL.Query().Get("offset"), 10, 64)
+	fields := parseFields(r)
+	urls, err := h.svc.ListURLs(r.Context(), pr, limit, offset, fields)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if len(fields) > 0 {
+		writeJSON(w, http.StatusOK, sparseURLs(urls, fields))
+		return
+	}
+	writeJSON(w, http.StatusOK, urls)
+}
+
+// parseFields reads the ?fields= sparse-fieldset parameter.
+func parseFields(r *http.Request) []string {
+	raw := r.URL.Query().Get("fields")
+	if raw == "" {
+		return nil
+	}
+	var fields []string
+	for _, f := range strings.Split(raw, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// sparseURLs re-marshals links keeping only the requested fields (plus
+// code), so the response matches the projection that was fetched.
+func sparseURLs(urls []*models.URL, fields []string) []map[string]interface{} {
+	keep := map[string]bool{"code": true}
+	for _, f := range fields {
+		keep[f] = true
+	}
+	out := make([]map[string]interface{}, 0, len(urls))
+	for _, u := range urls {
+		raw, err := json.Marshal(u)
+		if err != nil {
+			continue
+		}
+		var full map[string]interface{}
+		if err := json.Unmarshal(raw, &full); err != nil {
+			continue
+		}
+		entry := make(map[string]interface{}, len(keep))
+		for k := range keep {
+			if v, ok := full[k]; ok {
+				entry[k] = v
+			}
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+func (h *Handler) getURL(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	u, err := h.svc.GetURL(r.Context(), pr, mux.Vars(r)["code"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if notModified(w, r, u) {
+		return
+	}
+	if fields := parseFields(r); len(fields) > 0 {
+		writeJSON(w, http.StatusOK, sparseURLs([]*models.URL{u}, fields)[0])
+		return
+	}
+	writeJSON(w, http.StatusOK, u)
+}
+
+// parseIfMatch extracts the version from an If-Match validator in the
+// form urlETag emits (W/"<code>-<version>"); the second return is false
+// when the header doesn't parse.
+func parseIfMatch(header, code string) (int64, bool) {
+	trimmed := strings.TrimPrefix(strings.TrimSpace(header), "W/")
+	trimmed = strings.Trim(trimmed, `"`)
+	version, err := strconv.ParseInt(strings.TrimPrefix(trimmed, code+"-"), 10, 64)
+	if err != nil || !strings.HasPrefix(trimmed, code+"-") {
+		return 0, false
+	}
+	return version, true
+}
+
+func (h *Handler) updateURL(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	var req service.UpdateURLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "malformed body"})
+		return
+	}
+	code := mux.Vars(r)["code"]
+	// Concurrency control: honour the If-Match validator from the ETag
+	// this API emits; the if_version body field remains for clients that
+	// can't set headers.
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch != "" {
+		version, ok := parseIfMatch(ifMatch, code)
+		if !ok {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "malformed If-Match validator"})
+			return
+		}
+		req.IfVersion = &version
+	}
+	u, err := h.svc.UpdateURL(r.Context(), pr, code, req)
+	if err != nil {
+		// A failed If-Match precondition is 412 per RFC 9110, not the
+		// generic 409.
+		if ifMatch != "" && errors.Is(err, common.ErrConflict) {
+			writeJSON(w, http.StatusPreconditionFailed, errorResponse{Error: err.Error()})
+			return
+		}
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("ETag", urlETag(u))
+	writeJSON(w, http.StatusOK, u)
+}
+
+func (h *Handler) deleteURL(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	if err := h.svc.DeleteURL(r.Context(), pr, mux.Vars(r)["code"]); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) redirect(w http.ResponseWriter, r *http.Request) {
+	ip := middleware.ClientIP(r)
+	ipStr := ""
+	if ip != nil {
+		ipStr = ip.String()
+	}
+	if h.abuse.Banned(r.Context(), ipStr) {
+		middleware.ObserveRedirect(http.StatusTooManyRequests)
+		writeJSON(w, http.StatusTooManyRequests, errorResponse{Error: "temporarily banned"})
+		return
+	}
+	code := mux.Vars(r)["code"]
+	settings := h.domainSettings(r)
+	u, err := h.svc.Resolve(r.Context(), code)
+	if err != nil {
+		if errors.Is(err, common.ErrNotFound) {
+			// The code may name a bundle rather than a single link.
+			if b, berr := h.svc.ResolveBundle(r.Context(), code); berr == nil {
+				redirect.ServeBundlePage(w, b)
+				return
+			}
+			h.abuse.RecordMiss(r.Context(), ipStr)
+			middleware.ObserveRedirect(http.StatusNotFound)
+			if settings.NotFoundHTML != "" {
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(settings.NotFoundHTML))
+				return
+			}
+			writeJSON(w, http.StatusNotFound, errorResponse{
+				Error:       "not found",
+				Suggestions: h.svc.SuggestSimilarCodes(r.Context(), code),
+			})
+			return
+		}
+		middleware.ObserveRedirect(http.StatusInternalServerError)
+		writeError(w, err)
+		return
+	}
+	if !redirect.ApplyFrequencyCap(w, r, u) {
+		middleware.ObserveRedirect(http.StatusFound)
+		return
+	}
+	h.meter.Record(r.Context(), u.OrgID, "", metering.OpRedirect)
+	status := http.StatusMovedPermanently
+	if settings.RedirectStatus != 0 {
+		status = settings.RedirectStatus
+	}
+	// Observe exactly once, with the status this response actually
+	// carries; capped requests were observed above as their 302.
+	middleware.ObserveRedirect(status)
+	variant := ""
+	if u.Experiment != nil {
+		// Sticky weighted assignment; the exposure is logged on the
+		// click event below.
+		name, dest := service.AssignVariant(u.Experiment, u.Code, ipStr+"\x00"+r.UserAgent())
+		variant = name
+		exposed := *u
+		exposed.LongURL = dest
+		u = &exposed
+	}
+	clickID := ""
+	if u.Redirect.AppendClickID {
+		clickID = redirect.NewClickID()
+		tagged := *u
+		tagged.LongURL = redirect.AppendQueryParam(u.LongURL, "um_cid", clickID)
+		u = &tagged
+	}
+	if h.clicks != nil && !settings.AnalyticsDisabled {
+		h.clicks.Record(models.Click{
+			ClickID:    clickID,
+			CampaignID: u.CampaignID,
+			Domain:     requestDomain(r),
+			Variant:    variant,
+			Code:       u.Code,
+			OwnerID:    u.OwnerID,
+			OrgID:      u.OrgID,
+			IP:         ipStr,
+			UserAgent:  r.UserAgent(),
+			Referrer:   r.Referer(),
+		})
+	}
+	redirect.ServeWithStatus(w, r, u, status)
+}
+
+// publicResolve is the read-only resolve API intended to sit behind a
+// CDN: no auth, just the destination, with aggressive edge-cache headers.
+// Mutations purge the edge through the CDN purge client, so a long
+// s-maxage is safe.
+func (h *Handler) publicResolve(w http.ResponseWriter, r *http.Request) {
+	u, err := h.svc.Peek(r.Context(), mux.Vars(r)["code"])
+	if err != nil {
+		// Negative results cache briefly so enumeration scans mostly die
+		// at the edge.
+		w.Header().Set("Cache-Control", "public, max-age=60")
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "not found"})
+		return
+	}
+	w.Header().Set("Cache-Control", "public, max-age=300, s-maxage=86400")
+	writeJSON(w, http.StatusOK, map[string]string{
+		"code":     u.Code,
+		"long_url": u.LongURL,
+	})
+}
+
+// impressionPixelGIF is the 1x1 transparent companion pixel.
+var impressionPixelGIF = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00,
+	0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x01, 0x00,
+	0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+// impressionPixel records an impression (e.g. an email open) for the link
+// and always serves the pixel, so campaigns measure opens and clicks
+// through the same pipeline without any JavaScript.
+func (h *Handler) impressionPixel(w http.ResponseWriter, r *http.Request) {
+	code := mux.Vars(r)["code"]
+	if u, err := h.svc.Peek(r.Context(), code); err == nil && h.clicks != nil {
+		ip := middleware.ClientIP(r)
+		ipStr := ""
+		if ip != nil {
+			ipStr = ip.String()
+		}
+		h.clicks.Record(models.Click{
+			Event:      models.ClickEventImpression,
+			Code:       u.Code,
+			CampaignID: u.CampaignID,
+			OwnerID:    u.OwnerID,
+			OrgID:      u.OrgID,
+			IP:         ipStr,
+			UserAgent:  r.UserAgent(),
+			Referrer:   r.Referer(),
+		})
+	}
+	w.Header().Set("Content-Type", "image/gif")
+	w.Header().Set("Cache-Control", "no-store, private")
+	w.Write(impressionPixelGIF)
+}
+
+func (h *Handler) reserveAlias(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		// Minutes of hold; zero or absent reserves permanently.
+		Minutes int `json:"minutes,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "malformed body"})
+		return
+	}
+	res, err := h.svc.ReserveAlias(r.Context(), pr, mux.Vars(r)["alias"], req.Minutes)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, res)
+}
+
+func (h *Handler) releaseAlias(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	if err := h.svc.ReleaseAlias(r.Context(), pr, mux.Vars(r)["alias"]); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) aliasAvailability(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	res, err := h.svc.CheckAlias(r.Context(), pr, mux.Vars(r)["alias"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h *Handler) registerDomain(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		Domain string `json:"domain"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "malformed body"})
+		return
+	}
+	d, err := h.verifier.RegisterDomain(r.Context(), pr, req.Domain)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, d)
+}
+
+func (h *Handler) verifyDomain(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	d, err := h.verifier.VerifyDomain(r.Context(), pr, mux.Vars(r)["domain"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, d)
+}
+
+func (h *Handler) resolveChain(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	u, err := h.svc.ResolveChain(r.Context(), pr, mux.Vars(r)["code"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, u)
+}
+
+func (h *Handler) runQAChecklist(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	report, err := h.svc.RunQAChecklist(r.Context(), pr, mux.Vars(r)["code"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+func (h *Handler) getURLByExternalID(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	vars := mux.Vars(r)
+	u, err := h.svc.GetURLByExternalID(r.Context(), pr, vars["system"], vars["id"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, u)
+}
+
+func (h *Handler) urlRevisions(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	revisions, err := h.svc.Revisions(r.Context(), pr, mux.Vars(r)["code"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, revisions)
+}
+
+func (h *Handler) createCampaign(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		Name        string `json:"name"`
+		Description string `json:"description,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "malformed body"})
+		return
+	}
+	c, err := h.svc.CreateCampaign(r.Context(), pr, req.Name, req.Description)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, c)
+}
+
+func (h *Handler) listCampaigns(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	campaigns, err := h.svc.ListCampaigns(r.Context(), pr)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, campaigns)
+}
+
+func (h *Handler) deleteCampaign(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	if err := h.svc.DeleteCampaign(r.Context(), pr, mux.Vars(r)["id"]); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// errorCatalog serves the machine-readable error registry.
+func (h *Handler) errorCatalog(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	writeJSON(w, http.StatusOK, common.ErrorCatalog())
+}
+
+// apiLimits is the plan discovery endpoint: current plan, remaining
+// window quota and reset time, matching the X-RateLimit-* headers.
+func (h *Handler) apiLimits(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	plan, err := h.ent.PlanFor(r.Context(), pr.OrgID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	state, err := h.limiter.State(r.Context(), pr.UserID, pr.OrgID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"plan":       plan,
+		"rate_limit": state,
+	})
+}
+
+func (h *Handler) linkHealth(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	report, err := h.svc.LinkHealthReport(r.Context(), pr)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// sync serves the delta endpoint: ?cursor= is the RFC 3339 watermark from
+// the previous response, absent for a full initial sync.
+func (h *Handler) sync(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	var cursor time.Time
+	if raw := r.URL.Query().Get("cursor"); raw != "" {
+		t, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "cursor must be RFC 3339"})
+			return
+		}
+		cursor = t
+	}
+	res, err := h.svc.Sync(r.Context(), pr, cursor)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h *Handler) activityFeed(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 64)
+	events, err := h.svc.ActivityFeed(r.Context(), pr, limit)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, events)
+}
+
+func (h *Handler) abuseBans(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.adminPrincipal(w, r); !ok {
+		return
+	}
+	bans, err := h.abuse.Bans(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, bans)
+}
+
+func (h *Handler) abuseOffenders(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.adminPrincipal(w, r); !ok {
+		return
+	}
+	offenders, err := h.abuse.Offenders(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, offenders)
+}
+
+// urlETag derives a weak validator from the link's version, which bumps on
+// every update.
+func urlETag(u *models.URL) string {
+	return fmt.Sprintf(`W/"%s-%d"`, u.Code, u.Version)
+}
+
+// notModified answers conditional GETs on link metadata: it sets the ETag
+// and Last-Modified validators and writes a 304 when the client's
+// If-None-Match or If-Modified-Since still holds. Polling dashboards get a
+// header-only response instead of the full payload.
+func notModified(w http.ResponseWriter, r *http.Request, u *models.URL) bool {
+	etag := urlETag(u)
+	lastModified := u.UpdatedAt.UTC().Truncate(time.Second)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		if match == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+		return false
+	}
+	if since := r.Header.Get("If-Modified-Since"); since != "" {
+		if t, err := http.ParseTime(since); err == nil && !lastModified.After(t) {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+// adminPrincipal is like principal but additionally requires PermAdmin on
+// the caller's own org.
+func (h *Handler) adminPrincipal(w http.ResponseWriter, r *http.Request) (auth.Principal, bool) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return pr, false
+	}
+	if err := auth.Authorize(pr, auth.PermAdmin, auth.Resource{OrgID: pr.OrgID}); err != nil {
+		writeError(w, err)
+		return pr, false
+	}
+	return pr, true
+}
+
+func (h *Handler) registerBrandTerm(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		Term string `json:"term"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "malformed body"})
+		return
+	}
+	t, err := h.svc.RegisterBrandTerm(r.Context(), pr, req.Term)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, t)
+}
+
+func (h *Handler) listPolicies(w http.ResponseWriter, r *http.Request) {
+	pr, ok := h.adminPrincipal(w, r)
+	if !ok {
+		return
+	}
+	out, err := h.policies.List(r.Context(), pr.OrgID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (h *Handler) createPolicy(w http.ResponseWriter, r *http.Request) {
+	pr, ok := h.adminPrincipal(w, r)
+	if !ok {
+		return
+	}
+	var p policy.Policy
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "malformed body"})
+		return
+	}
+	// Only superadmins may write deployment-level policies; everyone else
+	// writes within their own org.
+	if pr.Role != auth.RoleSuperadmin || p.OrgID == "" {
+		p.OrgID = pr.OrgID
+	}
+	p.CreatedBy = pr.UserID
+	if err := h.policies.Create(r.Context(), &p); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, p)
+}
+
+func (h *Handler) deletePolicy(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.adminPrincipal(w, r); !ok {
+		return
+	}
+	if err := h.policies.Delete(r.Context(), mux.Vars(r)["id"]); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) pendingURLs(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	urls, err := h.svc.PendingURLs(r.Context(), pr)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, urls)
+}
+
+func (h *Handler) decideURLs(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		Codes   []string `json:"codes"`
+		Approve bool     `json:"approve"`
+		Reason  string   `json:"reason,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "malformed body"})
+		return
+	}
+	urls, err := h.svc.DecideURLs(r.Context(), pr, req.Codes, req.Approve, req.Reason)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, urls)
+}
+
+// usage returns the caller's org usage rollups, as JSON or CSV depending on
+// the Accept header. Restricted to org admins.
+func (h *Handler) usage(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	if err := auth.Authorize(pr, auth.PermAdmin, auth.Resource{OrgID: pr.OrgID}); err != nil {
+		writeError(w, err)
+		return
+	}
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+	if from == "" || to == "" {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "from and to are required (YYYY-MM-DD)"})
+		return
+	}
+	records, err := h.meter.Usage(r.Context(), pr.OrgID, from, to)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if strings.Contains(r.Header.Get("Accept"), "text/csv") {
+		w.Header().Set("Content-Type", "text/csv")
+		if err := metering.WriteCSV(w, records); err != nil {
+			log.Printf("handler: writing usage csv: %v", err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, records)
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ashtonholgate/url-minifier/services/shortener/repository"
+)
+
+// RegisterIntegrations mounts the outbound-connector configuration API,
+// org-admin only.
+func (h *Handler) RegisterIntegrations(api *mux.Router, integrations *repository.IntegrationRepository) {
+	h.integrations = integrations
+	api.HandleFunc("/integrations", h.createIntegration).Methods(http.MethodPost)
+	api.HandleFunc("/integrations", h.listIntegrations).Methods(http.MethodGet)
+	api.HandleFunc("/integrations/{id}", h.deleteIntegration).Methods(http.MethodDelete)
+}
+
+func (h *Handler) createIntegration(w http.ResponseWriter, r *http.Request) {
+	pr, ok := h.adminPrincipal(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		Type   string            `json:"type"`
+		Config map[string]string `json:"config"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "malformed body"})
+		return
+	}
+	integration := &repository.Integration{OrgID: pr.OrgID, Type: req.Type, Config: req.Config}
+	if err := h.integrations.StoreIntegration(r.Context(), integration); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, integration)
+}
+
+func (h *Handler) listIntegrations(w http.ResponseWriter, r *http.Request) {
+	pr, ok := h.adminPrincipal(w, r)
+	if !ok {
+		return
+	}
+	integrations, err := h.integrations.ListIntegrations(r.Context(), pr.OrgID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, integrations)
+}
+
+func (h *Handler) deleteIntegration(w http.ResponseWriter, r *http.Request) {
+	pr, ok := h.adminPrincipal(w, r)
+	if !ok {
+		return
+	}
+	if err := h.integrations.DeleteIntegration(r.Context(), pr.OrgID, mux.Vars(r)["id"]); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
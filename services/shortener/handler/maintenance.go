@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ashtonholgate/url-minifier/pkg/middleware"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+	"github.com/ashtonholgate/url-minifier/services/shortener/repository"
+)
+
+// RegisterMaintenanceRoutes mounts the maintenance switch on an
+// authenticated admin router that is deliberately NOT behind the
+// maintenance middleware, so operators can turn the mode off again.
+func (h *Handler) RegisterMaintenanceRoutes(admin *mux.Router, store *middleware.MaintenanceStore) {
+	h.maintenance = store
+	admin.HandleFunc("/maintenance", h.getMaintenance).Methods(http.MethodGet)
+	admin.HandleFunc("/maintenance", h.setMaintenance).Methods(http.MethodPut)
+	admin.HandleFunc("/maintenance", h.clearMaintenance).Methods(http.MethodDelete)
+	admin.HandleFunc("/index-advice", h.indexAdvice).Methods(http.MethodGet)
+	admin.HandleFunc("/storage-usage", h.storageUsage).Methods(http.MethodGet)
+	admin.HandleFunc("/edge-delta", h.edgeDelta).Methods(http.MethodGet)
+}
+
+// edgeDelta feeds edge resolvers the mappings changed since their
+// snapshot or last poll: active links as code→destination pairs, inactive
+// or expired ones as deletions.
+func (h *Handler) edgeDelta(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.adminPrincipal(w, r); !ok {
+		return
+	}
+	var cursor time.Time
+	if raw := r.URL.Query().Get("cursor"); raw != "" {
+		t, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "cursor must be RFC 3339"})
+			return
+		}
+		cursor = t
+	}
+	cur, err := h.adviceDB.Collection("urls").Find(r.Context(),
+		bson.M{"updated_at": bson.M{"$gt": cursor}},
+		options.Find().
+			SetSort(bson.D{{Key: "updated_at", Value: 1}}).
+			SetLimit(1000).
+			SetProjection(bson.M{"code": 1, "long_url": 1, "status": 1, "expires_at": 1, "updated_at": 1}))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer cur.Close(r.Context())
+	type deltaEntry struct {
+		Code    string `json:"code"`
+		LongURL string `json:"long_url,omitempty"`
+		Deleted bool   `json:"deleted,omitempty"`
+	}
+	var (
+		entries []deltaEntry
+		next    = cursor
+		now     = time.Now().UTC()
+	)
+	for cur.Next(r.Context()) {
+		var u models.URL
+		if err := cur.Decode(&u); err != nil {
+			writeError(w, err)
+			return
+		}
+		// The raw collection read bypasses the encryption decorator; edge
+		// resolvers need plaintext destinations.
+		dest, err := h.codec.DecryptIfConfigured(u.LongURL)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		entry := deltaEntry{Code: u.Code, LongURL: dest}
+		if !u.Active() || u.Expired(now) {
+			entry = deltaEntry{Code: u.Code, Deleted: true}
+		}
+		entries = append(entries, entry)
+		if u.UpdatedAt.After(next) {
+			next = u.UpdatedAt
+		}
+	}
+	if err := cur.Err(); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"entries": entries,
+		"cursor":  next,
+		"more":    len(entries) == 1000,
+	})
+}
+
+// storageUsage reports the org's document counts and analytics bytes
+// against plan limits.
+func (h *Handler) storageUsage(w http.ResponseWriter, r *http.Request) {
+	pr, ok := h.adminPrincipal(w, r)
+	if !ok {
+		return
+	}
+	usage, err := h.svc.StorageUsageReport(r.Context(), pr)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, usage)
+}
+
+// indexAdvice reruns the startup index advisor on demand.
+func (h *Handler) indexAdvice(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.adminPrincipal(w, r); !ok {
+		return
+	}
+	warnings, err := repository.AdviseIndexes(r.Context(), h.adviceDB)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"warnings": warnings})
+}
+
+func (h *Handler) getMaintenance(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.adminPrincipal(w, r); !ok {
+		return
+	}
+	state := h.maintenance.Get(r.Context())
+	if state == nil {
+		writeJSON(w, http.StatusOK, map[string]bool{"enabled": false})
+		return
+	}
+	writeJSON(w, http.StatusOK, state)
+}
+
+func (h *Handler) setMaintenance(w http.ResponseWriter, r *http.Request) {
+	pr, ok := h.adminPrincipal(w, r)
+	if !ok {
+		return
+	}
+	var state middleware.MaintenanceState
+	if err := json.NewDecoder(r.Body).Decode(&state); err != nil || state.Message == "" {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "message is required"})
+		return
+	}
+	state.SetBy = pr.UserID
+	if err := h.maintenance.Set(r.Context(), state); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) clearMaintenance(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.adminPrincipal(w, r); !ok {
+		return
+	}
+	if err := h.maintenance.Clear(r.Context()); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
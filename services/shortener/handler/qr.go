@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ashtonholgate/url-minifier/pkg/qr"
+)
+
+// registerQRRoutes mounts single-code QR rendering and the per-org logo
+// upload. Logos live in the same blob directory as the sheets, keyed by
+// org.
+func (h *Handler) registerQRRoutes(api *mux.Router) {
+	api.HandleFunc("/urls/{code}/qr", h.renderQR).Methods(http.MethodGet)
+	api.HandleFunc("/org/qr-logo", h.uploadQRLogo).Methods(http.MethodPut)
+}
+
+func (h *Handler) logoPath(orgID string) string {
+	return filepath.Join(h.qrSheetDir, "logos", orgID+".png")
+}
+
+// uploadQRLogo stores the org's centre logo for branded codes.
+func (h *Handler) uploadQRLogo(w http.ResponseWriter, r *http.Request) {
+	pr, ok := h.adminPrincipal(w, r)
+	if !ok {
+		return
+	}
+	raw, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "reading body"})
+		return
+	}
+	if _, err := png.Decode(bytes.NewReader(raw)); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "logo must be a PNG"})
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(h.logoPath(pr.OrgID)), 0o755); err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := os.WriteFile(h.logoPath(pr.OrgID), raw, 0o644); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseHexColor reads "rrggbb" into a color; empty returns nil.
+func parseHexColor(raw string) (color.Color, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var r, g, b uint8
+	if _, err := fmt.Sscanf(raw, "%02x%02x%02x", &r, &g, &b); err != nil {
+		return nil, fmt.Errorf("malformed color %q (want rrggbb)", raw)
+	}
+	return color.RGBA{R: r, G: g, B: b, A: 255}, nil
+}
+
+// renderQR serves the link's QR code with optional branding:
+// ?format=png|svg|eps, ?fg=/?bg= hex colors, ?logo=1 for the org logo.
+func (h *Handler) renderQR(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	u, err := h.svc.GetURL(r.Context(), pr, mux.Vars(r)["code"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	q := r.URL.Query()
+	opts := qr.RenderOptions{Format: q.Get("format")}
+	if opts.Foreground, err = parseHexColor(q.Get("fg")); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	if opts.Background, err = parseHexColor(q.Get("bg")); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	if q.Get("logo") == "1" {
+		f, err := os.Open(h.logoPath(pr.OrgID))
+		if err != nil {
+			writeJSON(w, http.StatusUnprocessableEntity, errorResponse{Error: "no logo uploaded for this org"})
+			return
+		}
+		logo, _, err := image.Decode(f)
+		f.Close()
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		opts.Logo = logo
+	}
+	base := "https://" + requestDomain(r)
+	if u.Domain != "" {
+		base = "https://" + u.Domain
+	}
+	out, contentType, err := qr.Render(base+"/"+u.Code, opts)
+	if err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, errorResponse{Error: err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(out)
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"archive/zip"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+	"github.com/ashtonholgate/url-minifier/pkg/qr"
+)
+
+// qrSheetTTL is how long a generated sheet stays downloadable.
+const qrSheetTTL = 24 * time.Hour
+
+// registerQRSheetRoutes mounts bulk QR sheet generation and download. The
+// sheet directory is typically an object-storage mount; the random token
+// in the download path acts as the presigned credential.
+func (h *Handler) registerQRSheetRoutes(api *mux.Router, sheetDir string) {
+	h.qrSheetDir = sheetDir
+	api.HandleFunc("/qr-sheets", h.createQRSheet).Methods(http.MethodPost)
+	api.HandleFunc("/qr-sheets/{token}", h.downloadQRSheet).Methods(http.MethodGet)
+}
+
+// createQRSheet renders a QR code per link matching the filter into one
+// ZIP, file names doubling as labels (code + host of the destination).
+func (h *Handler) createQRSheet(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		Filter  models.LinkFilter `json:"filter"`
+		BaseURL string            `json:"base_url"`
+		Size    int               `json:"size,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.BaseURL == "" {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "base_url is required"})
+		return
+	}
+	urls, err := h.svc.FindURLs(r.Context(), pr, req.Filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if len(urls) == 0 {
+		writeJSON(w, http.StatusUnprocessableEntity, errorResponse{Error: "filter matched no links"})
+		return
+	}
+
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	token := hex.EncodeToString(buf)
+	if err := os.MkdirAll(h.qrSheetDir, 0o755); err != nil {
+		writeError(w, err)
+		return
+	}
+	path := filepath.Join(h.qrSheetDir, token+".zip")
+	f, err := os.Create(path)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for _, u := range urls {
+		png, err := qr.PNG(req.BaseURL+"/"+u.Code, qr.Options{Size: req.Size})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		entry, err := zw.Create(fmt.Sprintf("%s.png", u.Code))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if _, err := entry.Write(png); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+	if err := zw.Close(); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"token":      token,
+		"links":      len(urls),
+		"expires_at": time.Now().UTC().Add(qrSheetTTL),
+	})
+}
+
+func (h *Handler) downloadQRSheet(w http.ResponseWriter, r *http.Request) {
+	if _, ok := principal(w, r); !ok {
+		return
+	}
+	token := mux.Vars(r)["token"]
+	if len(token) != 32 || filepath.Base(token) != token {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "malformed token"})
+		return
+	}
+	path := filepath.Join(h.qrSheetDir, token+".zip")
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) > qrSheetTTL {
+		writeError(w, common.ErrNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="qr-sheet.zip"`)
+	http.ServeFile(w, r, path)
+}
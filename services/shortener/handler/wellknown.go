@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ashtonholgate/url-minifier/pkg/auth"
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+)
+
+// registerWellKnownRoutes serves the per-domain app association documents
+// that make universal/app links work through custom domains, plus the API
+// to configure them.
+func (h *Handler) registerWellKnownRoutes(api *mux.Router, redirect *mux.Router) {
+	api.HandleFunc("/domains/{domain}/app-associations", h.setAppAssociations).Methods(http.MethodPut)
+	redirect.HandleFunc("/.well-known/apple-app-site-association", h.appleAssociation).Methods(http.MethodGet)
+	redirect.HandleFunc("/.well-known/assetlinks.json", h.androidAssetLinks).Methods(http.MethodGet)
+}
+
+// requestDomain extracts the serving domain from the Host header.
+func requestDomain(r *http.Request) string {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(host)
+}
+
+func (h *Handler) setAppAssociations(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	name := mux.Vars(r)["domain"]
+	d, err := h.domains.GetDomain(r.Context(), name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := auth.Authorize(pr, auth.PermAdmin, auth.Resource{OrgID: d.OrgID}); err != nil {
+		writeError(w, err)
+		return
+	}
+	var req struct {
+		Apple   json.RawMessage `json:"apple,omitempty"`
+		Android json.RawMessage `json:"android,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "malformed body"})
+		return
+	}
+	err = h.domains.UpdateAppAssociations(r.Context(), name, string(req.Apple), string(req.Android))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// serveAssociation writes one stored association document, 404ing when the
+// serving domain is unregistered or has none configured.
+func (h *Handler) serveAssociation(w http.ResponseWriter, r *http.Request, pick func(d string, apple string, android string) string) {
+	d, err := h.domains.GetDomain(r.Context(), requestDomain(r))
+	if err != nil {
+		if common.IsNotFound(err) {
+			writeJSON(w, http.StatusNotFound, errorResponse{Error: "not found"})
+			return
+		}
+		writeError(w, err)
+		return
+	}
+	doc := pick(d.Domain, d.AppleAppSiteAssociation, d.AndroidAssetLinks)
+	if doc == "" {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "not found"})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(doc))
+}
+
+func (h *Handler) appleAssociation(w http.ResponseWriter, r *http.Request) {
+	h.serveAssociation(w, r, func(_, apple, _ string) string { return apple })
+}
+
+func (h *Handler) androidAssetLinks(w http.ResponseWriter, r *http.Request) {
+	h.serveAssociation(w, r, func(_, _, android string) string { return android })
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+// registerWorkspaceRoutes mounts the workspace and membership API.
+func (h *Handler) registerWorkspaceRoutes(api *mux.Router) {
+	api.HandleFunc("/workspaces", h.createWorkspace).Methods(http.MethodPost)
+	api.HandleFunc("/workspaces", h.listWorkspaces).Methods(http.MethodGet)
+	api.HandleFunc("/workspaces/{id}/urls", h.listWorkspaceURLs).Methods(http.MethodGet)
+	api.HandleFunc("/workspaces/{id}/members", h.setWorkspaceMember).Methods(http.MethodPut)
+	api.HandleFunc("/workspaces/{id}/members/{user_id}", h.removeWorkspaceMember).Methods(http.MethodDelete)
+	api.HandleFunc("/invites", h.createInvite).Methods(http.MethodPost)
+	api.HandleFunc("/invites", h.listInvites).Methods(http.MethodGet)
+	api.HandleFunc("/invites/{token}/accept", h.acceptInvite).Methods(http.MethodPost)
+}
+
+func (h *Handler) createInvite(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		Email       string `json:"email"`
+		WorkspaceID string `json:"workspace_id,omitempty"`
+		Role        string `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "malformed body"})
+		return
+	}
+	inv, err := h.svc.CreateInvite(r.Context(), pr, req.Email, req.WorkspaceID, req.Role)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, inv)
+}
+
+func (h *Handler) listInvites(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	invites, err := h.svc.ListPendingInvites(r.Context(), pr, r.URL.Query().Get("workspace_id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, invites)
+}
+
+func (h *Handler) acceptInvite(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	inv, err := h.svc.AcceptInvite(r.Context(), pr, mux.Vars(r)["token"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, inv)
+}
+
+func (h *Handler) createWorkspace(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		Name          string `json:"name"`
+		DefaultDomain string `json:"default_domain,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "malformed body"})
+		return
+	}
+	ws, err := h.svc.CreateWorkspace(r.Context(), pr, req.Name, req.DefaultDomain)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, ws)
+}
+
+func (h *Handler) listWorkspaces(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	workspaces, err := h.svc.ListWorkspaces(r.Context(), pr)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, workspaces)
+}
+
+func (h *Handler) listWorkspaceURLs(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 64)
+	offset, _ := strconv.ParseInt(r.URL.Query().Get("offset"), 10, 64)
+	urls, err := h.svc.ListWorkspaceURLs(r.Context(), pr, mux.Vars(r)["id"], limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, urls)
+}
+
+func (h *Handler) setWorkspaceMember(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	var m models.WorkspaceMember
+	if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "malformed body"})
+		return
+	}
+	if err := h.svc.SetWorkspaceMember(r.Context(), pr, mux.Vars(r)["id"], m); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) removeWorkspaceMember(w http.ResponseWriter, r *http.Request) {
+	pr, ok := principal(w, r)
+	if !ok {
+		return
+	}
+	vars := mux.Vars(r)
+	if err := h.svc.RemoveWorkspaceMember(r.Context(), pr, vars["id"], vars["user_id"]); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,247 @@
+// Command shortener runs the URL shortening service: the authenticated
+// management API under /api/v1 and the public redirect endpoint at the root.
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ashtonholgate/url-minifier/pkg/auth"
+	"github.com/ashtonholgate/url-minifier/pkg/cdn"
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/connectors"
+	"github.com/ashtonholgate/url-minifier/pkg/feature"
+	"github.com/ashtonholgate/url-minifier/pkg/fieldcrypt"
+	"github.com/ashtonholgate/url-minifier/pkg/ipclass"
+	"github.com/ashtonholgate/url-minifier/pkg/metering"
+	"github.com/ashtonholgate/url-minifier/pkg/middleware"
+	"github.com/ashtonholgate/url-minifier/pkg/notify"
+	"github.com/ashtonholgate/url-minifier/pkg/plans"
+	"github.com/ashtonholgate/url-minifier/pkg/redirect"
+	authrepo "github.com/ashtonholgate/url-minifier/services/auth/repository"
+	"github.com/ashtonholgate/url-minifier/services/shortener/abuse"
+	"github.com/ashtonholgate/url-minifier/services/shortener/activity"
+	"github.com/ashtonholgate/url-minifier/services/shortener/clicks"
+	"github.com/ashtonholgate/url-minifier/services/shortener/exports"
+	"github.com/ashtonholgate/url-minifier/services/shortener/handler"
+	"github.com/ashtonholgate/url-minifier/services/shortener/policy"
+	"github.com/ashtonholgate/url-minifier/services/shortener/queue"
+	"github.com/ashtonholgate/url-minifier/services/shortener/repository"
+	"github.com/ashtonholgate/url-minifier/services/shortener/service"
+)
+
+func main() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	mongoURI := common.Getenv("MONGO_URI", "mongodb://localhost:27017")
+	redisAddr := common.Getenv("REDIS_ADDR", "localhost:6379")
+	listenAddr := common.Getenv("LISTEN_ADDR", ":8080")
+	jwtSecret := common.Getenv("JWT_SECRET", "")
+	if jwtSecret == "" {
+		log.Fatal("JWT_SECRET must be set")
+	}
+	redirect.SetFallbackSecret([]byte(jwtSecret))
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
+	if err != nil {
+		log.Fatalf("connecting to mongo: %v", err)
+	}
+	defer client.Disconnect(context.Background())
+
+	rdb := redis.NewClient(&redis.Options{Addr: redisAddr})
+	defer rdb.Close()
+
+	repo, err := repository.NewMongoRepository(ctx, client.Database("shortener"))
+	if err != nil {
+		log.Fatalf("initialising repository: %v", err)
+	}
+	if shadowURI := common.Getenv("SHADOW_MONGO_URI", ""); shadowURI != "" {
+		// Dark launch: dual-write and shadow-read against a candidate
+		// backend without affecting responses.
+		shadowClient, err := mongo.Connect(ctx, options.Client().ApplyURI(shadowURI))
+		if err != nil {
+			log.Fatalf("connecting to shadow mongo: %v", err)
+		}
+		defer shadowClient.Disconnect(context.Background())
+		candidate, err := repository.NewMongoRepository(ctx, shadowClient.Database("shortener"))
+		if err != nil {
+			log.Fatalf("initialising shadow repository: %v", err)
+		}
+		repo = repository.NewShadowRepository(repo, candidate)
+	}
+	// The URL cache can shard across several Redis instances; session,
+	// rate-limit and abuse state stay on the primary.
+	cacheClient := repository.NewCacheClient(common.Getenv("REDIS_CACHE_ADDRS", redisAddr))
+	repo = repository.NewCachedRepository(repo, cacheClient)
+	if common.Getenv("BLOOM_PRECHECK", "") == "1" {
+		// Creates broadcast over the primary Redis so every instance's
+		// filter learns them immediately.
+		repo, err = repository.NewBloomRepository(context.Background(), repo, client.Database("shortener"), rdb)
+		if err != nil {
+			log.Fatalf("building bloom pre-check: %v", err)
+		}
+	}
+	codec, err := fieldcrypt.FromEnv()
+	if err != nil {
+		log.Fatalf("initialising field encryption: %v", err)
+	}
+	if codec != nil {
+		// Outside the cache so Mongo and Redis both hold ciphertext only.
+		repo = repository.NewEncryptedRepository(repo, codec)
+	}
+
+	repository.LogIndexAdvice(ctx, client.Database("shortener"), log.Printf)
+
+	ent := plans.NewEntitlements(client.Database("auth"))
+	domains := repository.NewDomainRepository(client.Database("shortener"))
+	policies := policy.NewStore(client.Database("shortener"))
+	scheme := service.NewGenerationScheme()
+	if n, err := strconv.Atoi(common.Getenv("CODE_GENERATION", "1")); err == nil && n > 1 {
+		// Later generations register here as the format evolves.
+		scheme.Register(n, service.NewRandomGenerator(0))
+		if err := scheme.SetActive(n); err != nil {
+			log.Fatalf("activating code generation: %v", err)
+		}
+	}
+	var gen service.CodeGenerator = scheme
+	if common.Getenv("UNLEASH_URL", "") != "" {
+		flags, err := feature.NewClient("shortener")
+		if err != nil {
+			log.Fatalf("initialising feature flags: %v", err)
+		}
+		// Canary strategy under evaluation: shorter 6-character codes.
+		gen = service.NewCanaryGenerator(gen, service.NewRandomGenerator(6), flags)
+	}
+	svc := service.New(repo, gen, ent, domains, policies)
+	// Registered explicitly: the canary wrapper above may hide the
+	// scheme from the generator chain, but resolution must still
+	// dispatch on code generations.
+	svc.SetGenerationScheme(scheme)
+	svc.SetBundleRepository(repository.NewBundleRepository(client.Database("shortener")))
+	svc.SetActivityFeed(activity.NewFeed(rdb))
+	svc.SetSavedFilterRepository(repository.NewSavedFilterRepository(client.Database("shortener")))
+	svc.SetRevisionRepository(repository.NewRevisionRepository(client.Database("shortener")))
+	svc.SetCampaignRepository(repository.NewCampaignRepository(client.Database("shortener")))
+	svc.SetWorkspaceRepository(repository.NewWorkspaceRepository(client.Database("shortener")))
+	svc.SetInviteRepository(repository.NewInviteRepository(client.Database("shortener")))
+	svc.SetReservationRepository(repository.NewReservationRepository(client.Database("shortener")))
+	svc.SetBrandTermRepository(repository.NewBrandTermRepository(client.Database("shortener")))
+	integrations := repository.NewIntegrationRepository(client.Database("shortener"))
+	svc.SetConnectorDispatcher(&connectors.Dispatcher{Resolve: integrations.ConnectorsFor})
+	svc.SetRotationRedis(rdb)
+	svc.SetAnalyticsAccounting(client.Database("analytics"))
+	if base := common.Getenv("PUBLIC_BASE_URL", ""); base != "" {
+		var purger cdn.Purger = cdn.NoopPurger{}
+		switch common.Getenv("CDN_PROVIDER", "") {
+		case "cloudflare":
+			purger = cdn.CloudflarePurger{
+				ZoneID:   common.Getenv("CLOUDFLARE_ZONE_ID", ""),
+				APIToken: common.Getenv("CLOUDFLARE_API_TOKEN", ""),
+			}
+		case "fastly":
+			purger = cdn.FastlyPurger{APIToken: common.Getenv("FASTLY_API_TOKEN", "")}
+		}
+		svc.SetCDNPurger(purger, base)
+	}
+	if common.Getenv("MODERATION_MODE", "") == "manual" {
+		svc.EnableModeration()
+	}
+	if common.Getenv("UNICODE_ALIASES", "") == "1" {
+		svc.EnableUnicodeAliases()
+	}
+	if common.Getenv("CASE_INSENSITIVE_CODES", "") == "1" {
+		svc.EnableCaseInsensitiveCodes()
+	}
+	if common.Getenv("TYPO_SUGGESTIONS", "") == "1" {
+		svc.EnableTypoSuggestions()
+	}
+	svc.StartRotChecker(context.Background(), time.Hour, 200)
+	exports.New(client.Database("shortener"), client.Database("analytics"), codec).Start(context.Background())
+	svc.StartPublisher(context.Background(), time.Minute)
+	meter := metering.New(rdb, client.Database("shortener"))
+	meter.Start(context.Background())
+	verifier := service.NewDomainVerifier(domains, ent)
+	verifier.BindService(svc)
+	verifier.StartDNSPoller(context.Background(), 5*time.Minute, nil, notify.LogNotifier{})
+	tracker := abuse.NewTracker(rdb)
+	recorder := clicks.NewRecorder(client.Database("analytics"), rdb)
+	if dbPath := common.Getenv("IP_CLASS_DB", ""); dbPath != "" {
+		classifier, err := ipclass.Load(dbPath)
+		if err != nil {
+			log.Fatalf("loading ip classification database: %v", err)
+		}
+		recorder.SetIPClassifier(classifier)
+		tracker.SetIPClassifier(classifier)
+	}
+	h := handler.New(svc, meter, verifier, policies, tracker, domains)
+	h.SetClickRecorder(recorder)
+	h.SetAdviceDatabase(client.Database("shortener"))
+	h.SetFieldCodec(codec)
+
+	root := mux.NewRouter()
+	root.Use(middleware.Metrics)
+	// Registered before the handler's catch-all /{code} route.
+	root.Handle("/metrics", promhttp.Handler()).Methods(http.MethodGet)
+	hmacAuth := middleware.HMACAuth(authrepo.NewAPIKeyRepository(client.Database("auth")), rdb)
+	bearerAuth := middleware.Authenticate([]byte(jwtSecret), auth.NewSessionStore(rdb))
+	readOnly := common.Getenv("READ_ONLY_MODE", "") == "1"
+	maint := middleware.NewMaintenanceStore(rdb)
+	// The admin router is registered first and skips the maintenance
+	// middleware so the switch can always be turned off again.
+	adminRouter := root.PathPrefix("/api/v1/admin").Subrouter()
+	adminRouter.Use(hmacAuth, bearerAuth)
+	h.RegisterMaintenanceRoutes(adminRouter, maint)
+	api := root.PathPrefix("/api/v1").Subrouter()
+	api.Use(middleware.ReadOnly(func() bool { return readOnly }))
+	api.Use(middleware.Maintenance(maint))
+	limiter := middleware.NewRateLimiter(rdb, ent)
+	h.SetRateLimiter(limiter, ent)
+	api.Use(hmacAuth, bearerAuth, limiter.Middleware)
+	h.RegisterIntegrations(api, integrations)
+	// Bitly v4 compatibility shim shares the same authentication.
+	v4 := root.PathPrefix("/v4").Subrouter()
+	v4.Use(hmacAuth, bearerAuth)
+	h.RegisterBitlyRoutes(v4)
+	h.Register(api, root)
+
+	// Optional queue consumer mode for asynchronous batch creates.
+	switch common.Getenv("QUEUE_MODE", "") {
+	case "sqs":
+		source, err := queue.NewSQSSource(
+			common.Getenv("AWS_REGION", "us-east-1"),
+			common.Getenv("QUEUE_URL", ""),
+			common.Getenv("REPLY_QUEUE_URL", ""))
+		if err != nil {
+			log.Fatalf("starting sqs consumer: %v", err)
+		}
+		go queue.NewConsumer(svc, source).Run(context.Background())
+	case "amqp":
+		source, err := queue.NewAMQPSource(
+			common.Getenv("AMQP_URL", "amqp://localhost:5672/"),
+			common.Getenv("QUEUE_NAME", "shorten-requests"),
+			common.Getenv("REPLY_QUEUE_NAME", "shorten-results"))
+		if err != nil {
+			log.Fatalf("starting amqp consumer: %v", err)
+		}
+		go queue.NewConsumer(svc, source).Run(context.Background())
+	}
+
+	srv := &http.Server{
+		Addr:         listenAddr,
+		Handler:      root,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+	log.Printf("shortener listening on %s", listenAddr)
+	log.Fatal(srv.ListenAndServe())
+}
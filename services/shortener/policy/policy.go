@@ -0,0 +1,159 @@
+// Package policy evaluates destination-domain rules: deployment-wide and
+// per-org allowlists and denylists deciding which destinations may be
+// shortened. Internal deployments run an allowlist; the public deployment
+// runs a denylist of known-bad domains and TLDs.
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+)
+
+// ErrDenied is returned when a destination is rejected by policy.
+var ErrDenied = errors.New("policy: destination not permitted")
+
+func init() {
+	common.RegisterError(common.ErrorInfo{
+		Code: "policy_denied", HTTPStatus: 422,
+		Description: "the destination domain is blocked by a deployment or org policy",
+		Remediation: "use a permitted destination, or ask an org admin to adjust the policy",
+	})
+}
+
+// Policy types.
+const (
+	TypeAllow = "allow"
+	TypeDeny  = "deny"
+)
+
+// Policy is one allowlist or denylist. OrgID is empty for deployment-level
+// policies, which apply to everyone.
+type Policy struct {
+	ID    string `bson:"_id,omitempty" json:"id"`
+	OrgID string `bson:"org_id,omitempty" json:"org_id,omitempty"`
+	Type  string `bson:"type" json:"type"`
+	// Patterns are matched against the destination host: an exact domain
+	// ("evil.example"), a suffix with leading dot (".zip") covering a TLD
+	// or any subdomain, or "*" in an allowlist to admit everything.
+	Patterns  []string  `bson:"patterns" json:"patterns"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	CreatedBy string    `bson:"created_by" json:"created_by"`
+}
+
+// matches reports whether host matches any of the policy's patterns.
+func (p *Policy) matches(host string) bool {
+	for _, pat := range p.Patterns {
+		switch {
+		case pat == "*":
+			return true
+		case strings.HasPrefix(pat, "."):
+			if strings.HasSuffix(host, pat) || host == strings.TrimPrefix(pat, ".") {
+				return true
+			}
+		case host == pat:
+			return true
+		}
+	}
+	return false
+}
+
+// Store persists policies in the policies collection.
+type Store struct {
+	policies *mongo.Collection
+}
+
+// NewStore returns a Store over db's policies collection.
+func NewStore(db *mongo.Database) *Store {
+	return &Store{policies: db.Collection("policies")}
+}
+
+// Create inserts a policy after validating its type.
+func (s *Store) Create(ctx context.Context, p *Policy) error {
+	if p.Type != TypeAllow && p.Type != TypeDeny {
+		return fmt.Errorf("policy type must be %q or %q: %w", TypeAllow, TypeDeny, common.ErrInvalidInput)
+	}
+	if len(p.Patterns) == 0 {
+		return fmt.Errorf("policy needs at least one pattern: %w", common.ErrInvalidInput)
+	}
+	p.CreatedAt = time.Now().UTC()
+	res, err := s.policies.InsertOne(ctx, p)
+	if err != nil {
+		return fmt.Errorf("inserting policy: %w", err)
+	}
+	if id, ok := res.InsertedID.(string); ok {
+		p.ID = id
+	}
+	return nil
+}
+
+// Delete removes a policy by ID.
+func (s *Store) Delete(ctx context.Context, id string) error {
+	res, err := s.policies.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return fmt.Errorf("deleting policy: %w", err)
+	}
+	if res.DeletedCount == 0 {
+		return fmt.Errorf("policy %q: %w", id, common.ErrNotFound)
+	}
+	return nil
+}
+
+// List returns the deployment-level policies plus the org's own.
+func (s *Store) List(ctx context.Context, orgID string) ([]*Policy, error) {
+	filter := bson.M{"org_id": bson.M{"$in": []string{"", orgID}}}
+	cur, err := s.policies.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("listing policies: %w", err)
+	}
+	defer cur.Close(ctx)
+	var out []*Policy
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, fmt.Errorf("decoding policies: %w", err)
+	}
+	return out, nil
+}
+
+// Evaluate checks longURL's host against the deployment and org policies.
+// Denylists reject a match outright; when any allowlist exists for a level,
+// the host must match one of that level's allow policies.
+func (s *Store) Evaluate(ctx context.Context, orgID, longURL string) error {
+	parsed, err := url.Parse(longURL)
+	if err != nil {
+		return fmt.Errorf("parsing destination: %w", common.ErrInvalidInput)
+	}
+	host := strings.ToLower(parsed.Hostname())
+	policies, err := s.List(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	allowSeen := map[string]bool{}
+	allowMatched := map[string]bool{}
+	for _, p := range policies {
+		switch p.Type {
+		case TypeDeny:
+			if p.matches(host) {
+				return fmt.Errorf("host %q denied: %w", host, ErrDenied)
+			}
+		case TypeAllow:
+			allowSeen[p.OrgID] = true
+			if p.matches(host) {
+				allowMatched[p.OrgID] = true
+			}
+		}
+	}
+	for level := range allowSeen {
+		if !allowMatched[level] {
+			return fmt.Errorf("host %q not on allowlist: %w", host, ErrDenied)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// amqpSource implements Source over RabbitMQ queues.
+type amqpSource struct {
+	conn       *amqp.Connection
+	channel    *amqp.Channel
+	deliveries <-chan amqp.Delivery
+	replyQueue string
+}
+
+// NewAMQPSource connects to the broker and consumes queueName, replying
+// on replyQueue. Both queues are declared durable.
+func NewAMQPSource(url, queueName, replyQueue string) (Source, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, fmt.Errorf("queue: dialing amqp: %w", err)
+	}
+	channel, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("queue: opening channel: %w", err)
+	}
+	for _, name := range []string{queueName, replyQueue} {
+		if _, err := channel.QueueDeclare(name, true, false, false, false, nil); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("queue: declaring %s: %w", name, err)
+		}
+	}
+	deliveries, err := channel.Consume(queueName, "", false, false, false, false, nil)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("queue: consuming %s: %w", queueName, err)
+	}
+	return &amqpSource{conn: conn, channel: channel, deliveries: deliveries, replyQueue: replyQueue}, nil
+}
+
+func (s *amqpSource) Receive(ctx context.Context) (*Message, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case d, ok := <-s.deliveries:
+		if !ok {
+			return nil, fmt.Errorf("queue: amqp channel closed")
+		}
+		return &Message{
+			Body: d.Body,
+			Ack:  func() error { return d.Ack(false) },
+		}, nil
+	}
+}
+
+func (s *amqpSource) Publish(ctx context.Context, body []byte) error {
+	err := s.channel.PublishWithContext(ctx, "", s.replyQueue, false, false, amqp.Publishing{
+		ContentType: "application/json",
+		Body:        body,
+	})
+	if err != nil {
+		return fmt.Errorf("queue: publishing to amqp: %w", err)
+	}
+	return nil
+}
+
+func (s *amqpSource) Close() error {
+	s.channel.Close()
+	return s.conn.Close()
+}
@@ -0,0 +1,115 @@
+// Package queue consumes shorten requests from a message queue and
+// publishes results to a reply queue, so batch producers minting millions
+// of links never hammer the HTTP API synchronously. The consumer is
+// transport-agnostic; SQS and AMQP sources implement the Source interface.
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/ashtonholgate/url-minifier/pkg/auth"
+	"github.com/ashtonholgate/url-minifier/services/shortener/service"
+)
+
+// CreateRequest is the queue message schema. RequestID is echoed on the
+// reply so producers correlate results.
+type CreateRequest struct {
+	RequestID string `json:"request_id"`
+	UserID    string `json:"user_id"`
+	OrgID     string `json:"org_id,omitempty"`
+	Role      string `json:"role,omitempty"`
+	service.CreateURLRequest
+}
+
+// CreateResult is published to the reply queue for every consumed
+// request, success or failure.
+type CreateResult struct {
+	RequestID string `json:"request_id"`
+	Code      string `json:"code,omitempty"`
+	LongURL   string `json:"long_url,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Message is one received queue entry.
+type Message struct {
+	Body []byte
+	// Ack removes the message from the queue; unacked messages redeliver
+	// per the transport's policy.
+	Ack func() error
+}
+
+// Source abstracts the queue transport.
+type Source interface {
+	// Receive blocks for the next message or until ctx is done.
+	Receive(ctx context.Context) (*Message, error)
+	// Publish sends a reply payload.
+	Publish(ctx context.Context, body []byte) error
+	Close() error
+}
+
+// Consumer drains a Source through the service layer.
+type Consumer struct {
+	svc    *service.Service
+	source Source
+}
+
+// NewConsumer returns a Consumer over source.
+func NewConsumer(svc *service.Service, source Source) *Consumer {
+	return &Consumer{svc: svc, source: source}
+}
+
+// Run consumes until ctx is cancelled. Malformed messages are acked and
+// answered with an error reply rather than poisoning the queue.
+func (c *Consumer) Run(ctx context.Context) {
+	for {
+		msg, err := c.source.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("queue: receiving: %v", err)
+			time.Sleep(time.Second)
+			continue
+		}
+		if msg == nil {
+			continue
+		}
+		c.handle(ctx, msg)
+	}
+}
+
+func (c *Consumer) handle(ctx context.Context, msg *Message) {
+	var req CreateRequest
+	result := CreateResult{}
+	if err := json.Unmarshal(msg.Body, &req); err != nil {
+		result.Error = "malformed request: " + err.Error()
+	} else {
+		result.RequestID = req.RequestID
+		pr := auth.Principal{UserID: req.UserID, OrgID: req.OrgID, Role: auth.Role(req.Role)}
+		if !pr.Role.Valid() {
+			pr.Role = auth.RoleEditor
+		}
+		u, err := c.svc.CreateURL(ctx, pr, req.CreateURLRequest)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Code, result.LongURL = u.Code, u.LongURL
+		}
+	}
+	reply, err := json.Marshal(result)
+	if err == nil {
+		if err := c.source.Publish(ctx, reply); err != nil {
+			log.Printf("queue: publishing reply for %s: %v", result.RequestID, err)
+			// Leave the message unacked so it redelivers and the reply
+			// is retried; creation conflicts on redelivery surface as
+			// error replies, which producers must tolerate.
+			return
+		}
+	}
+	if err := msg.Ack(); err != nil {
+		log.Printf("queue: acking %s: %v", result.RequestID, err)
+	}
+}
@@ -0,0 +1,64 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sqs"
+)
+
+// sqsSource implements Source over a pair of SQS queues.
+type sqsSource struct {
+	client   *sqs.SQS
+	queueURL string
+	replyURL string
+}
+
+// NewSQSSource connects to the request and reply queues in region.
+func NewSQSSource(region, queueURL, replyURL string) (Source, error) {
+	sess, err := session.NewSession(aws.NewConfig().WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("queue: creating aws session: %w", err)
+	}
+	return &sqsSource{client: sqs.New(sess), queueURL: queueURL, replyURL: replyURL}, nil
+}
+
+func (s *sqsSource) Receive(ctx context.Context) (*Message, error) {
+	out, err := s.client.ReceiveMessageWithContext(ctx, &sqs.ReceiveMessageInput{
+		QueueUrl:            aws.String(s.queueURL),
+		MaxNumberOfMessages: aws.Int64(1),
+		WaitTimeSeconds:     aws.Int64(20),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("queue: receiving from sqs: %w", err)
+	}
+	if len(out.Messages) == 0 {
+		return nil, nil
+	}
+	raw := out.Messages[0]
+	return &Message{
+		Body: []byte(aws.StringValue(raw.Body)),
+		Ack: func() error {
+			_, err := s.client.DeleteMessage(&sqs.DeleteMessageInput{
+				QueueUrl:      aws.String(s.queueURL),
+				ReceiptHandle: raw.ReceiptHandle,
+			})
+			return err
+		},
+	}, nil
+}
+
+func (s *sqsSource) Publish(ctx context.Context, body []byte) error {
+	_, err := s.client.SendMessageWithContext(ctx, &sqs.SendMessageInput{
+		QueueUrl:    aws.String(s.replyURL),
+		MessageBody: aws.String(string(body)),
+	})
+	if err != nil {
+		return fmt.Errorf("queue: publishing to sqs: %w", err)
+	}
+	return nil
+}
+
+func (s *sqsSource) Close() error { return nil }
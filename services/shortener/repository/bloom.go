@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+var bloomOutcomes = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "urlminifier",
+	Name:      "bloom_precheck_total",
+	Help:      "Bloom pre-check outcomes (short_circuit, pass).",
+}, []string{"outcome"})
+
+// bloomHashes is the number of hash functions; with ~10 bits per element
+// this keeps the false-positive rate around 1%.
+const (
+	bloomHashes        = 7
+	bloomBitsPerCode   = 10
+	bloomRebuildPeriod = 5 * time.Minute
+)
+
+// codeBloom is a plain bloom filter over existing codes.
+type codeBloom struct {
+	mu   sync.RWMutex
+	bits []uint64
+	m    uint64 // bit count
+}
+
+func newCodeBloom(capacity int64) *codeBloom {
+	if capacity < 1024 {
+		capacity = 1024
+	}
+	m := uint64(capacity * bloomBitsPerCode)
+	return &codeBloom{bits: make([]uint64, (m+63)/64), m: m}
+}
+
+// positions derives the k bit positions for code via double hashing.
+func (b *codeBloom) positions(code string) [bloomHashes]uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(code))
+	h1 := h.Sum64()
+	h2 := h1>>33 | h1<<31
+	var out [bloomHashes]uint64
+	for i := range out {
+		out[i] = (h1 + uint64(i)*h2) % b.m
+	}
+	return out
+}
+
+func (b *codeBloom) add(code string) {
+	pos := b.positions(code)
+	b.mu.Lock()
+	for _, p := range pos {
+		b.bits[p/64] |= 1 << (p % 64)
+	}
+	b.mu.Unlock()
+}
+
+func (b *codeBloom) mayContain(code string) bool {
+	pos := b.positions(code)
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, p := range pos {
+		if b.bits[p/64]&(1<<(p%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// bloomCreatesChannel is the Redis pub/sub channel every instance
+// publishes new codes on, so a code minted anywhere enters every
+// instance's filter immediately rather than waiting for the next
+// rebuild — a false negative here is a freshly created link that 404s.
+const bloomCreatesChannel = "bloom:creates"
+
+// bloomRepository short-circuits lookups for codes the filter has never
+// seen, so enumeration scans and typos skip Redis and Mongo entirely.
+// False positives just fall through to the normal path; creates
+// propagate across instances over pub/sub, with the periodic rebuild as
+// the backstop for missed messages.
+type bloomRepository struct {
+	URLRepository
+	urls *mongo.Collection
+	rdb  *redis.Client
+
+	filterMu sync.RWMutex
+	filter   *codeBloom
+}
+
+// NewBloomRepository wraps next with a bloom pre-check built from db's
+// urls collection, kept current via the creates channel on rdb and
+// rebuilt periodically until ctx is cancelled.
+func NewBloomRepository(ctx context.Context, next URLRepository, db *mongo.Database, rdb *redis.Client) (URLRepository, error) {
+	r := &bloomRepository{URLRepository: next, urls: db.Collection("urls"), rdb: rdb}
+	filter, err := r.build(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.setFilter(filter)
+	go r.subscribeCreates(ctx)
+	go func() {
+		ticker := time.NewTicker(bloomRebuildPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				rebuilt, err := r.build(ctx)
+				if err != nil {
+					log.Printf("bloom: rebuilding: %v", err)
+					continue
+				}
+				r.setFilter(rebuilt)
+			}
+		}
+	}()
+	return r, nil
+}
+
+// subscribeCreates feeds codes minted on other instances into the local
+// filter. The subscription reconnects on error; anything missed while
+// disconnected is covered by the next rebuild.
+func (r *bloomRepository) subscribeCreates(ctx context.Context) {
+	for ctx.Err() == nil {
+		sub := r.rdb.Subscribe(ctx, bloomCreatesChannel)
+		for {
+			msg, err := sub.ReceiveMessage(ctx)
+			if err != nil {
+				break
+			}
+			r.currentFilter().add(msg.Payload)
+		}
+		sub.Close()
+		if ctx.Err() == nil {
+			time.Sleep(time.Second)
+		}
+	}
+}
+
+func (r *bloomRepository) setFilter(f *codeBloom) {
+	r.filterMu.Lock()
+	r.filter = f
+	r.filterMu.Unlock()
+}
+
+func (r *bloomRepository) currentFilter() *codeBloom {
+	r.filterMu.RLock()
+	defer r.filterMu.RUnlock()
+	return r.filter
+}
+
+// build scans every code into a fresh filter sized for current volume.
+func (r *bloomRepository) build(ctx context.Context) (*codeBloom, error) {
+	count, err := r.urls.EstimatedDocumentCount(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("bloom: counting codes: %w", err)
+	}
+	filter := newCodeBloom(count * 2) // headroom for growth between rebuilds
+	cur, err := r.urls.Find(ctx, bson.M{},
+		options.Find().SetProjection(bson.M{"code": 1}).SetBatchSize(5000))
+	if err != nil {
+		return nil, fmt.Errorf("bloom: scanning codes: %w", err)
+	}
+	defer cur.Close(ctx)
+	for cur.Next(ctx) {
+		var doc struct {
+			Code string `bson:"code"`
+		}
+		if err := cur.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("bloom: decoding code: %w", err)
+		}
+		filter.add(doc.Code)
+	}
+	if err := cur.Err(); err != nil {
+		return nil, fmt.Errorf("bloom: iterating codes: %w", err)
+	}
+	return filter, nil
+}
+
+func (r *bloomRepository) GetURLByCode(ctx context.Context, code string) (*models.URL, error) {
+	if !r.currentFilter().mayContain(code) {
+		bloomOutcomes.WithLabelValues("short_circuit").Inc()
+		return nil, fmt.Errorf("code %q: %w", code, common.ErrNotFound)
+	}
+	bloomOutcomes.WithLabelValues("pass").Inc()
+	return r.URLRepository.GetURLByCode(ctx, code)
+}
+
+func (r *bloomRepository) StoreURL(ctx context.Context, u *models.URL) error {
+	if err := r.URLRepository.StoreURL(ctx, u); err != nil {
+		return err
+	}
+	// New codes enter the local filter immediately and are broadcast so
+	// every other instance admits them too.
+	r.currentFilter().add(u.Code)
+	if err := r.rdb.Publish(ctx, bloomCreatesChannel, u.Code).Err(); err != nil {
+		log.Printf("bloom: broadcasting create %s: %v", u.Code, err)
+	}
+	return nil
+}
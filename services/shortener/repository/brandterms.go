@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// BrandTerm is a protected term registered by an org; aliases and domains
+// confusable with it are blocked or queued. Skeleton is the precomputed
+// UTS #39-style skeleton used for matching.
+type BrandTerm struct {
+	Term      string    `bson:"term" json:"term"`
+	Skeleton  string    `bson:"skeleton" json:"skeleton"`
+	OrgID     string    `bson:"org_id" json:"org_id"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+// BrandTermRepository stores protected brand terms.
+type BrandTermRepository struct {
+	terms *mongo.Collection
+}
+
+// NewBrandTermRepository returns a repository over db's brand_terms
+// collection.
+func NewBrandTermRepository(db *mongo.Database) *BrandTermRepository {
+	return &BrandTermRepository{terms: db.Collection("brand_terms")}
+}
+
+// StoreBrandTerm registers a protected term with its skeleton.
+func (r *BrandTermRepository) StoreBrandTerm(ctx context.Context, t *BrandTerm) error {
+	t.CreatedAt = time.Now().UTC()
+	if _, err := r.terms.InsertOne(ctx, t); err != nil {
+		return fmt.Errorf("inserting brand term: %w", err)
+	}
+	return nil
+}
+
+// FindBySkeleton returns the terms whose skeleton matches, regardless of
+// org; the caller decides whether the claimant is the owning org.
+func (r *BrandTermRepository) FindBySkeleton(ctx context.Context, skeleton string) ([]*BrandTerm, error) {
+	cur, err := r.terms.Find(ctx, bson.M{"skeleton": skeleton})
+	if err != nil {
+		return nil, fmt.Errorf("matching brand terms: %w", err)
+	}
+	defer cur.Close(ctx)
+	var out []*BrandTerm
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, fmt.Errorf("decoding brand terms: %w", err)
+	}
+	return out, nil
+}
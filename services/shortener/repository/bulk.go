@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+// matchingCodes resolves a filter to the concrete codes it covers. Bulk
+// mutations operate on this snapshot so the caller learns exactly which
+// links were touched and the cache layer can invalidate them.
+func (r *mongoRepository) matchingCodes(ctx context.Context, ownerID string, f models.LinkFilter) ([]string, error) {
+	cur, err := r.urls.Find(ctx, filterQuery(ownerID, f),
+		options.Find().SetProjection(bson.M{"code": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("resolving filter: %w", err)
+	}
+	defer cur.Close(ctx)
+	var codes []string
+	for cur.Next(ctx) {
+		var doc struct {
+			Code string `bson:"code"`
+		}
+		if err := cur.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("decoding code: %w", err)
+		}
+		codes = append(codes, doc.Code)
+	}
+	if err := cur.Err(); err != nil {
+		return nil, fmt.Errorf("iterating codes: %w", err)
+	}
+	return codes, nil
+}
+
+func (r *mongoRepository) BulkDeleteURLs(ctx context.Context, ownerID string, f models.LinkFilter) ([]string, error) {
+	defer timeOp("bulk")()
+	codes, err := r.matchingCodes(ctx, ownerID, f)
+	if err != nil || len(codes) == 0 {
+		return codes, err
+	}
+	if _, err := r.urls.DeleteMany(ctx, bson.M{"code": bson.M{"$in": codes}}); err != nil {
+		return nil, fmt.Errorf("bulk deleting: %w", err)
+	}
+	return codes, nil
+}
+
+func (r *mongoRepository) BulkRetagURLs(ctx context.Context, ownerID string, f models.LinkFilter, add, remove []string) ([]string, error) {
+	defer timeOp("bulk")()
+	codes, err := r.matchingCodes(ctx, ownerID, f)
+	if err != nil || len(codes) == 0 {
+		return codes, err
+	}
+	match := bson.M{"code": bson.M{"$in": codes}}
+	now := time.Now().UTC()
+	if len(add) > 0 {
+		_, err = r.urls.UpdateMany(ctx, match, bson.M{
+			"$addToSet": bson.M{"tags": bson.M{"$each": add}},
+			"$set":      bson.M{"updated_at": now},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("bulk adding tags: %w", err)
+		}
+	}
+	if len(remove) > 0 {
+		_, err = r.urls.UpdateMany(ctx, match, bson.M{
+			"$pullAll": bson.M{"tags": remove},
+			"$set":     bson.M{"updated_at": now},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("bulk removing tags: %w", err)
+		}
+	}
+	return codes, nil
+}
+
+func (r *mongoRepository) BulkExtendExpiry(ctx context.Context, ownerID string, f models.LinkFilter, extendBy time.Duration) ([]string, error) {
+	defer timeOp("bulk")()
+	// Only links that actually expire can be extended.
+	if f.ExpiringBefore == nil {
+		far := time.Now().UTC().AddDate(100, 0, 0)
+		f.ExpiringBefore = &far
+	}
+	codes, err := r.matchingCodes(ctx, ownerID, f)
+	if err != nil || len(codes) == 0 {
+		return codes, err
+	}
+	// Pipeline update so each document shifts relative to its own expiry.
+	pipeline := bson.A{bson.M{"$set": bson.M{
+		"expires_at": bson.M{"$add": bson.A{"$expires_at", extendBy.Milliseconds()}},
+		"updated_at": time.Now().UTC(),
+	}}}
+	if _, err := r.urls.UpdateMany(ctx, bson.M{"code": bson.M{"$in": codes}}, pipeline); err != nil {
+		return nil, fmt.Errorf("bulk extending expiry: %w", err)
+	}
+	return codes, nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+// BundleRepository stores link bundles. Bundles share the code keyspace
+// with single links by convention: the service checks both before claiming
+// a code.
+type BundleRepository struct {
+	bundles *mongo.Collection
+}
+
+// NewBundleRepository returns a repository over db's bundles collection.
+func NewBundleRepository(db *mongo.Database) *BundleRepository {
+	return &BundleRepository{bundles: db.Collection("bundles")}
+}
+
+// StoreBundle inserts a new bundle.
+func (r *BundleRepository) StoreBundle(ctx context.Context, b *models.Bundle) error {
+	_, err := r.bundles.InsertOne(ctx, b)
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("code %q: %w", b.Code, common.ErrConflict)
+	}
+	if err != nil {
+		return fmt.Errorf("inserting bundle: %w", err)
+	}
+	return nil
+}
+
+// GetBundleByCode returns the bundle for code, or a wrapped
+// common.ErrNotFound.
+func (r *BundleRepository) GetBundleByCode(ctx context.Context, code string) (*models.Bundle, error) {
+	var b models.Bundle
+	err := r.bundles.FindOne(ctx, bson.M{"code": code}).Decode(&b)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("bundle %q: %w", code, common.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetching bundle: %w", err)
+	}
+	return &b, nil
+}
+
+// UpdateBundle replaces the stored record for b.Code.
+func (r *BundleRepository) UpdateBundle(ctx context.Context, b *models.Bundle) error {
+	b.UpdatedAt = time.Now().UTC()
+	res, err := r.bundles.ReplaceOne(ctx, bson.M{"code": b.Code}, b)
+	if err != nil {
+		return fmt.Errorf("updating bundle: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("bundle %q: %w", b.Code, common.ErrNotFound)
+	}
+	return nil
+}
+
+// DeleteBundle removes the bundle for code.
+func (r *BundleRepository) DeleteBundle(ctx context.Context, code string) error {
+	res, err := r.bundles.DeleteOne(ctx, bson.M{"code": code})
+	if err != nil {
+		return fmt.Errorf("deleting bundle: %w", err)
+	}
+	if res.DeletedCount == 0 {
+		return fmt.Errorf("bundle %q: %w", code, common.ErrNotFound)
+	}
+	return nil
+}
+
+// ListBundlesByOwner returns the owner's bundles, newest first.
+func (r *BundleRepository) ListBundlesByOwner(ctx context.Context, ownerID string) ([]*models.Bundle, error) {
+	cur, err := r.bundles.Find(ctx, bson.M{"owner_id": ownerID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, fmt.Errorf("listing bundles: %w", err)
+	}
+	defer cur.Close(ctx)
+	var out []*models.Bundle
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, fmt.Errorf("decoding bundles: %w", err)
+	}
+	return out, nil
+}
+
+// IncrementItemClicks bumps the click counter of one item by position.
+func (r *BundleRepository) IncrementItemClicks(ctx context.Context, code string, index int) error {
+	field := fmt.Sprintf("items.%d.clicks", index)
+	_, err := r.bundles.UpdateOne(ctx, bson.M{"code": code},
+		bson.M{"$inc": bson.M{field: 1}})
+	if err != nil {
+		return fmt.Errorf("incrementing item clicks: %w", err)
+	}
+	return nil
+}
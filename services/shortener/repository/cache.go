@@ -0,0 +1,376 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/ashtonholgate/url-minifier/pkg/middleware"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+// cacheTTL bounds how long a cached URL may be served without consulting
+// MongoDB. Mutations invalidate eagerly, so this mainly caps drift after
+// out-of-band writes.
+const cacheTTL = 24 * time.Hour
+
+// cacheRequests counts cache outcomes; hits carry the request's trace ID as
+// an exemplar so cache behaviour can be correlated with traces.
+var cacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "urlminifier",
+	Name:      "url_cache_requests_total",
+	Help:      "URL cache lookups by result (hit, miss).",
+}, []string{"result"})
+
+// countCache records a cache outcome with an exemplar when a trace ID is
+// in flight.
+func countCache(ctx context.Context, result string) {
+	c := cacheRequests.WithLabelValues(result)
+	if tid := middleware.TraceIDFrom(ctx); tid != "" {
+		if ea, ok := c.(prometheus.ExemplarAdder); ok {
+			ea.AddWithExemplar(1, prometheus.Labels{"trace_id": tid})
+			return
+		}
+	}
+	c.Inc()
+}
+
+// cacheQueueSize bounds the async population queue; at sustained create
+// rates beyond what the worker drains, new entries are dropped (and
+// counted) rather than blocking creation.
+const cacheQueueSize = 4096
+
+var cacheQueueDrops = promauto.NewCounter(prometheus.CounterOpts{
+	Namespace: "urlminifier",
+	Name:      "cache_population_drops_total",
+	Help:      "Links dropped from the async cache population queue on overflow.",
+})
+
+// cachedRepository decorates a URLRepository with a Redis read-through cache
+// keyed by short code. Cache failures are logged and otherwise ignored: Redis
+// being down degrades latency, never correctness.
+//
+// The client is a UniversalClient so deployments whose working set exceeds
+// one instance can hand in a consistent-hash ring (see NewCacheClient)
+// without the cache layer knowing.
+type cachedRepository struct {
+	URLRepository
+	rdb redis.UniversalClient
+	// local is the in-process tier in front of Redis, with TinyLFU
+	// admission so zipfian long-tail lookups don't evict hot links.
+	local *localCache
+	// writeQueue feeds the async population worker so create latency
+	// tracks the Mongo insert only; the read path repopulates anything
+	// dropped here.
+	writeQueue chan cacheWrite
+	// mutated tombstones codes changed since their queue entry was
+	// snapshotted (code -> mutation time), so the worker never writes a
+	// stale or deleted link back into Redis.
+	mutated sync.Map
+}
+
+// cacheWrite is one queued population entry with its snapshot time.
+type cacheWrite struct {
+	u  *models.URL
+	at time.Time
+}
+
+// tombstoneHorizon bounds how long mutation tombstones are kept; queue
+// residency is far shorter.
+const tombstoneHorizon = time.Minute
+
+// invalidationsChannel broadcasts mutated codes so every process — other
+// shortener replicas and the redirect binary — drops its in-process copy
+// instead of serving a stale or deleted link until the local TTL expires.
+const invalidationsChannel = "cache:invalidations"
+
+// NewCachedRepository wraps next with a Redis cache fronted by a small
+// in-process tier. Cache population after StoreURL happens on a
+// background worker.
+func NewCachedRepository(next URLRepository, rdb redis.UniversalClient) URLRepository {
+	r := &cachedRepository{
+		URLRepository: next,
+		rdb:           rdb,
+		local:         newLocalCache(),
+		writeQueue:    make(chan cacheWrite, cacheQueueSize),
+	}
+	go r.populateLoop()
+	go r.subscribeInvalidations()
+	return r
+}
+
+// subscribeInvalidations drops local-tier entries for codes mutated in
+// other processes. Reconnects on error; the local TTL backstops anything
+// missed while disconnected.
+func (r *cachedRepository) subscribeInvalidations() {
+	ctx := context.Background()
+	for {
+		sub := r.rdb.Subscribe(ctx, invalidationsChannel)
+		for {
+			msg, err := sub.ReceiveMessage(ctx)
+			if err != nil {
+				break
+			}
+			r.local.remove(msg.Payload)
+		}
+		sub.Close()
+		time.Sleep(time.Second)
+	}
+}
+
+// populateLoop drains the write queue, batching adjacent entries into one
+// pipelined write. Entries whose code mutated after the snapshot was
+// taken are discarded: writing them would resurrect a deleted link or
+// serve a stale destination for the full cache TTL.
+func (r *cachedRepository) populateLoop() {
+	for w := range r.writeQueue {
+		writes := []cacheWrite{w}
+		for len(writes) < 64 {
+			select {
+			case next := <-r.writeQueue:
+				writes = append(writes, next)
+				continue
+			default:
+			}
+			break
+		}
+		batch := make([]*models.URL, 0, len(writes))
+		for _, write := range writes {
+			if r.mutatedSince(write.u.Code, write.at) {
+				continue
+			}
+			batch = append(batch, write.u)
+		}
+		if len(batch) > 0 {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			r.WarmCache(ctx, batch)
+			cancel()
+		}
+		r.pruneTombstones()
+	}
+}
+
+// enqueueCache hands a freshly stored link to the population worker,
+// dropping on overflow: a dropped entry only costs one read-through later.
+func (r *cachedRepository) enqueueCache(u *models.URL) {
+	copied := *u
+	select {
+	case r.writeQueue <- cacheWrite{u: &copied, at: time.Now()}:
+	default:
+		cacheQueueDrops.Inc()
+	}
+}
+
+// markMutated tombstones codes so queued population entries snapshotted
+// before this mutation are discarded, and broadcasts the codes so other
+// processes drop their in-process copies.
+func (r *cachedRepository) markMutated(codes ...string) {
+	now := time.Now()
+	ctx := context.Background()
+	for _, code := range codes {
+		r.mutated.Store(code, now)
+		if err := r.rdb.Publish(ctx, invalidationsChannel, code).Err(); err != nil {
+			log.Printf("cache: broadcasting invalidation of %s: %v", code, err)
+		}
+	}
+}
+
+// mutatedSince reports whether code changed after the given snapshot.
+func (r *cachedRepository) mutatedSince(code string, at time.Time) bool {
+	v, ok := r.mutated.Load(code)
+	return ok && v.(time.Time).After(at)
+}
+
+// pruneTombstones drops tombstones past the horizon.
+func (r *cachedRepository) pruneTombstones() {
+	cutoff := time.Now().Add(-tombstoneHorizon)
+	r.mutated.Range(func(key, value interface{}) bool {
+		if value.(time.Time).Before(cutoff) {
+			r.mutated.Delete(key)
+		}
+		return true
+	})
+}
+
+// NewCacheClient builds the URL-cache client from a comma-separated
+// address list: one address yields a plain client; several yield a
+// consistent-hash ring with per-shard heartbeats, so keys spread evenly
+// and a dead shard only costs its own slice of the keyspace.
+func NewCacheClient(addrs string) redis.UniversalClient {
+	parts := strings.Split(addrs, ",")
+	if len(parts) == 1 {
+		return redis.NewClient(&redis.Options{Addr: strings.TrimSpace(parts[0])})
+	}
+	shards := make(map[string]string, len(parts))
+	for i, addr := range parts {
+		shards[fmt.Sprintf("shard%d", i)] = strings.TrimSpace(addr)
+	}
+	return redis.NewRing(&redis.RingOptions{
+		Addrs: shards,
+		// Unreachable shards drop out of the hash ring after failed
+		// heartbeats and rejoin when healthy; key distribution is
+		// rebalance-safe because ring placement depends only on the
+		// shard name.
+		HeartbeatFrequency: 500 * time.Millisecond,
+	})
+}
+
+func cacheKey(code string) string { return "url:" + code }
+
+// cacheURL writes u to Redis under its code with the standard TTL.
+func (r *cachedRepository) cacheURL(ctx context.Context, u *models.URL) {
+	b, err := json.Marshal(u)
+	if err != nil {
+		log.Printf("cache: marshal %s: %v", u.Code, err)
+		return
+	}
+	if err := r.rdb.Set(ctx, cacheKey(u.Code), b, cacheTTL).Err(); err != nil {
+		log.Printf("cache: set %s: %v", u.Code, err)
+	}
+}
+
+// WarmCache primes Redis and the local tier for a batch of links with one
+// pipelined round trip, for the bulk-create and import paths where
+// per-link writes would mean one round trip each.
+func (r *cachedRepository) WarmCache(ctx context.Context, urls []*models.URL) error {
+	if len(urls) == 0 {
+		return nil
+	}
+	pipe := r.rdb.Pipeline()
+	for _, u := range urls {
+		b, err := json.Marshal(u)
+		if err != nil {
+			log.Printf("cache: marshal %s: %v", u.Code, err)
+			continue
+		}
+		pipe.Set(ctx, cacheKey(u.Code), b, cacheTTL)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("cache: warm batch: %v", err)
+		return err
+	}
+	for _, u := range urls {
+		r.local.offer(u)
+	}
+	return nil
+}
+
+func (r *cachedRepository) StoreURL(ctx context.Context, u *models.URL) error {
+	if err := r.URLRepository.StoreURL(ctx, u); err != nil {
+		return err
+	}
+	// Off the critical path: creation latency tracks the Mongo insert
+	// while the worker fills the cache eagerly behind it.
+	r.enqueueCache(u)
+	return nil
+}
+
+func (r *cachedRepository) GetURLByCode(ctx context.Context, code string) (*models.URL, error) {
+	if u, ok := r.local.get(code); ok {
+		countCache(ctx, "hit")
+		return u, nil
+	}
+	b, err := r.rdb.Get(ctx, cacheKey(code)).Bytes()
+	if err == nil {
+		var u models.URL
+		if err := json.Unmarshal(b, &u); err == nil {
+			countCache(ctx, "hit")
+			r.local.offer(&u)
+			return &u, nil
+		}
+		log.Printf("cache: unmarshal %s: %v", code, err)
+	} else if !errors.Is(err, redis.Nil) {
+		log.Printf("cache: get %s: %v", code, err)
+	}
+	countCache(ctx, "miss")
+	u, err := r.URLRepository.GetURLByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	r.cacheURL(ctx, u)
+	r.local.offer(u)
+	return u, nil
+}
+
+func (r *cachedRepository) UpdateURL(ctx context.Context, u *models.URL) error {
+	if err := r.URLRepository.UpdateURL(ctx, u); err != nil {
+		return err
+	}
+	r.markMutated(u.Code)
+	r.cacheURL(ctx, u)
+	r.local.remove(u.Code)
+	return nil
+}
+
+func (r *cachedRepository) SetStatus(ctx context.Context, codes []string, status string) ([]*models.URL, error) {
+	updated, err := r.URLRepository.SetStatus(ctx, codes, status)
+	if err != nil {
+		return nil, err
+	}
+	r.markMutated(codes...)
+	// One pipelined refresh instead of a round trip per code.
+	r.WarmCache(ctx, updated)
+	r.local.remove(codes...)
+	return updated, nil
+}
+
+// invalidate drops the cache entries for codes after a bulk mutation.
+func (r *cachedRepository) invalidate(ctx context.Context, codes []string) {
+	if len(codes) == 0 {
+		return
+	}
+	r.markMutated(codes...)
+	keys := make([]string, len(codes))
+	for i, c := range codes {
+		keys[i] = cacheKey(c)
+	}
+	if err := r.rdb.Del(ctx, keys...).Err(); err != nil {
+		log.Printf("cache: bulk del: %v", err)
+	}
+	r.local.remove(codes...)
+}
+
+func (r *cachedRepository) BulkDeleteURLs(ctx context.Context, ownerID string, f models.LinkFilter) ([]string, error) {
+	codes, err := r.URLRepository.BulkDeleteURLs(ctx, ownerID, f)
+	if err == nil {
+		r.invalidate(ctx, codes)
+	}
+	return codes, err
+}
+
+func (r *cachedRepository) BulkRetagURLs(ctx context.Context, ownerID string, f models.LinkFilter, add, remove []string) ([]string, error) {
+	codes, err := r.URLRepository.BulkRetagURLs(ctx, ownerID, f, add, remove)
+	if err == nil {
+		r.invalidate(ctx, codes)
+	}
+	return codes, err
+}
+
+func (r *cachedRepository) BulkExtendExpiry(ctx context.Context, ownerID string, f models.LinkFilter, extendBy time.Duration) ([]string, error) {
+	codes, err := r.URLRepository.BulkExtendExpiry(ctx, ownerID, f, extendBy)
+	if err == nil {
+		r.invalidate(ctx, codes)
+	}
+	return codes, err
+}
+
+func (r *cachedRepository) DeleteURL(ctx context.Context, code string) error {
+	if err := r.URLRepository.DeleteURL(ctx, code); err != nil {
+		return err
+	}
+	r.markMutated(code)
+	if err := r.rdb.Del(ctx, cacheKey(code)).Err(); err != nil {
+		log.Printf("cache: del %s: %v", code, err)
+	}
+	r.local.remove(code)
+	return nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+// CampaignRepository stores campaigns.
+type CampaignRepository struct {
+	campaigns *mongo.Collection
+}
+
+// NewCampaignRepository returns a repository over db's campaigns
+// collection.
+func NewCampaignRepository(db *mongo.Database) *CampaignRepository {
+	return &CampaignRepository{campaigns: db.Collection("campaigns")}
+}
+
+// StoreCampaign inserts a new campaign, generating its ID.
+func (r *CampaignRepository) StoreCampaign(ctx context.Context, c *models.Campaign) error {
+	c.CreatedAt = time.Now().UTC()
+	if c.ID == "" {
+		c.ID = fmt.Sprintf("cmp_%d", time.Now().UnixNano())
+	}
+	if _, err := r.campaigns.InsertOne(ctx, c); err != nil {
+		return fmt.Errorf("inserting campaign: %w", err)
+	}
+	return nil
+}
+
+// GetCampaign returns the campaign by ID.
+func (r *CampaignRepository) GetCampaign(ctx context.Context, id string) (*models.Campaign, error) {
+	var c models.Campaign
+	err := r.campaigns.FindOne(ctx, bson.M{"_id": id}).Decode(&c)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("campaign %q: %w", id, common.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetching campaign: %w", err)
+	}
+	return &c, nil
+}
+
+// DeleteCampaign removes the campaign by ID.
+func (r *CampaignRepository) DeleteCampaign(ctx context.Context, id string) error {
+	res, err := r.campaigns.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return fmt.Errorf("deleting campaign: %w", err)
+	}
+	if res.DeletedCount == 0 {
+		return fmt.Errorf("campaign %q: %w", id, common.ErrNotFound)
+	}
+	return nil
+}
+
+// ListCampaignsByOrg returns the org's campaigns, newest first.
+func (r *CampaignRepository) ListCampaignsByOrg(ctx context.Context, orgID string) ([]*models.Campaign, error) {
+	cur, err := r.campaigns.Find(ctx, bson.M{"org_id": orgID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, fmt.Errorf("listing campaigns: %w", err)
+	}
+	defer cur.Close(ctx)
+	var out []*models.Campaign
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, fmt.Errorf("decoding campaigns: %w", err)
+	}
+	return out, nil
+}
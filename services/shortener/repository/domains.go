@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+// DomainRepository stores registered short-link and destination domains.
+type DomainRepository struct {
+	domains *mongo.Collection
+}
+
+// NewDomainRepository returns a repository over db's domains collection.
+func NewDomainRepository(db *mongo.Database) *DomainRepository {
+	return &DomainRepository{domains: db.Collection("domains")}
+}
+
+// StoreDomain inserts a new domain registration.
+func (r *DomainRepository) StoreDomain(ctx context.Context, d *models.Domain) error {
+	_, err := r.domains.InsertOne(ctx, d)
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("domain %q: %w", d.Domain, common.ErrConflict)
+	}
+	if err != nil {
+		return fmt.Errorf("inserting domain: %w", err)
+	}
+	return nil
+}
+
+// GetDomain returns the registration for name, or a wrapped
+// common.ErrNotFound.
+func (r *DomainRepository) GetDomain(ctx context.Context, name string) (*models.Domain, error) {
+	var d models.Domain
+	err := r.domains.FindOne(ctx, bson.M{"domain": name}).Decode(&d)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("domain %q: %w", name, common.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetching domain: %w", err)
+	}
+	return &d, nil
+}
+
+// MarkVerified records a successful ownership verification.
+func (r *DomainRepository) MarkVerified(ctx context.Context, name string) error {
+	now := time.Now().UTC()
+	res, err := r.domains.UpdateOne(ctx, bson.M{"domain": name},
+		bson.M{"$set": bson.M{"verified": true, "verified_at": now}})
+	if err != nil {
+		return fmt.Errorf("marking domain verified: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("domain %q: %w", name, common.ErrNotFound)
+	}
+	return nil
+}
+
+// IsVerified reports whether name is a verified domain.
+func (r *DomainRepository) IsVerified(ctx context.Context, name string) (bool, error) {
+	d, err := r.GetDomain(ctx, name)
+	if common.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return d.Verified, nil
+}
+
+// UpdateSettings replaces a domain's defaults and branding.
+func (r *DomainRepository) UpdateSettings(ctx context.Context, name string, settings models.DomainSettings) error {
+	res, err := r.domains.UpdateOne(ctx, bson.M{"domain": name},
+		bson.M{"$set": bson.M{"settings": settings}})
+	if err != nil {
+		return fmt.Errorf("updating domain settings: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("domain %q: %w", name, common.ErrNotFound)
+	}
+	return nil
+}
+
+// UpdateAppAssociations stores the raw app-association JSON documents
+// served at the domain's well-known paths.
+func (r *DomainRepository) UpdateAppAssociations(ctx context.Context, name, apple, android string) error {
+	res, err := r.domains.UpdateOne(ctx, bson.M{"domain": name},
+		bson.M{"$set": bson.M{
+			"apple_app_site_association": apple,
+			"android_asset_links":        android,
+		}})
+	if err != nil {
+		return fmt.Errorf("updating app associations: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("domain %q: %w", name, common.ErrNotFound)
+	}
+	return nil
+}
+
+// ListDomainsByStatus returns up to limit domains in the given lifecycle
+// status, oldest first.
+func (r *DomainRepository) ListDomainsByStatus(ctx context.Context, status string, limit int64) ([]*models.Domain, error) {
+	cur, err := r.domains.Find(ctx, bson.M{"status": status},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}).SetLimit(limit))
+	if err != nil {
+		return nil, fmt.Errorf("listing domains by status: %w", err)
+	}
+	defer cur.Close(ctx)
+	var out []*models.Domain
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, fmt.Errorf("decoding domains: %w", err)
+	}
+	return out, nil
+}
+
+// SetStatus transitions a domain's lifecycle status.
+func (r *DomainRepository) SetStatus(ctx context.Context, name, status string) error {
+	update := bson.M{"status": status}
+	if status == models.DomainStatusVerified || status == models.DomainStatusActive {
+		update["verified"] = true
+		update["verified_at"] = time.Now().UTC()
+	}
+	res, err := r.domains.UpdateOne(ctx, bson.M{"domain": name}, bson.M{"$set": update})
+	if err != nil {
+		return fmt.Errorf("setting domain status: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("domain %q: %w", name, common.ErrNotFound)
+	}
+	return nil
+}
+
+// ListDomainsByOrg returns the org's registered domains.
+func (r *DomainRepository) ListDomainsByOrg(ctx context.Context, orgID string) ([]*models.Domain, error) {
+	cur, err := r.domains.Find(ctx, bson.M{"org_id": orgID})
+	if err != nil {
+		return nil, fmt.Errorf("listing domains: %w", err)
+	}
+	defer cur.Close(ctx)
+	var out []*models.Domain
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, fmt.Errorf("decoding domains: %w", err)
+	}
+	return out, nil
+}
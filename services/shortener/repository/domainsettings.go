@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+// settingsCacheTTL bounds how stale per-domain settings may be on the
+// redirect hot path; one Mongo read per domain per interval.
+const settingsCacheTTL = time.Minute
+
+// DomainSettingsCache memoises per-domain settings lookups for the
+// redirect hot paths of both the management API and the standalone
+// redirect binary.
+type DomainSettingsCache struct {
+	domains *DomainRepository
+
+	mu      sync.Mutex
+	entries map[string]settingsEntry
+}
+
+type settingsEntry struct {
+	settings models.DomainSettings
+	fetched  time.Time
+}
+
+// NewDomainSettingsCache returns a cache over domains.
+func NewDomainSettingsCache(domains *DomainRepository) *DomainSettingsCache {
+	return &DomainSettingsCache{domains: domains, entries: map[string]settingsEntry{}}
+}
+
+// SettingsFor returns the domain's settings, memoised; unknown domains get
+// the zero value.
+func (c *DomainSettingsCache) SettingsFor(ctx context.Context, name string) models.DomainSettings {
+	c.mu.Lock()
+	entry, ok := c.entries[name]
+	c.mu.Unlock()
+	if ok && time.Since(entry.fetched) < settingsCacheTTL {
+		return entry.settings
+	}
+	var settings models.DomainSettings
+	if d, err := c.domains.GetDomain(ctx, name); err == nil {
+		settings = d.Settings
+	}
+	c.mu.Lock()
+	c.entries[name] = settingsEntry{settings: settings, fetched: time.Now()}
+	c.mu.Unlock()
+	return settings
+}
+
+// Invalidate drops the memoised entry after a settings update.
+func (c *DomainSettingsCache) Invalidate(name string) {
+	c.mu.Lock()
+	delete(c.entries, name)
+	c.mu.Unlock()
+}
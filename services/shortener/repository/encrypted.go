@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ashtonholgate/url-minifier/pkg/fieldcrypt"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+// encryptedRepository encrypts sensitive link fields before they reach the
+// layers below. Wrapped OUTSIDE the cache so both MongoDB and Redis only
+// ever hold ciphertext; the service layer sees plaintext.
+type encryptedRepository struct {
+	URLRepository
+	codec *fieldcrypt.Codec
+}
+
+// NewEncryptedRepository wraps next with field-level encryption of the
+// destination URL.
+func NewEncryptedRepository(next URLRepository, codec *fieldcrypt.Codec) URLRepository {
+	return &encryptedRepository{URLRepository: next, codec: codec}
+}
+
+func (r *encryptedRepository) seal(u *models.URL) error {
+	enc, err := r.codec.Encrypt(u.LongURL)
+	if err != nil {
+		return err
+	}
+	u.LongURL = enc
+	return nil
+}
+
+func (r *encryptedRepository) open(u *models.URL) error {
+	plain, err := r.codec.Decrypt(u.LongURL)
+	if err != nil {
+		return err
+	}
+	u.LongURL = plain
+	return nil
+}
+
+func (r *encryptedRepository) StoreURL(ctx context.Context, u *models.URL) error {
+	if err := r.seal(u); err != nil {
+		return err
+	}
+	err := r.URLRepository.StoreURL(ctx, u)
+	// Callers keep using u afterwards; hand them back the plaintext.
+	if openErr := r.open(u); err == nil {
+		err = openErr
+	}
+	return err
+}
+
+func (r *encryptedRepository) GetURLByCode(ctx context.Context, code string) (*models.URL, error) {
+	u, err := r.URLRepository.GetURLByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.open(u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (r *encryptedRepository) UpdateURL(ctx context.Context, u *models.URL) error {
+	if err := r.seal(u); err != nil {
+		return err
+	}
+	err := r.URLRepository.UpdateURL(ctx, u)
+	if openErr := r.open(u); err == nil {
+		err = openErr
+	}
+	return err
+}
+
+func (r *encryptedRepository) ListURLsByOwner(ctx context.Context, ownerID string, limit, offset int64, fields []string) ([]*models.URL, error) {
+	urls, err := r.URLRepository.ListURLsByOwner(ctx, ownerID, limit, offset, fields)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range urls {
+		if err := r.open(u); err != nil {
+			return nil, err
+		}
+	}
+	return urls, nil
+}
+
+func (r *encryptedRepository) FindURLs(ctx context.Context, ownerID string, f models.LinkFilter) ([]*models.URL, error) {
+	urls, err := r.URLRepository.FindURLs(ctx, ownerID, f)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range urls {
+		if err := r.open(u); err != nil {
+			return nil, err
+		}
+	}
+	return urls, nil
+}
+
+func (r *encryptedRepository) ListURLsUpdatedSince(ctx context.Context, ownerID string, since time.Time, limit int64) ([]*models.URL, error) {
+	urls, err := r.URLRepository.ListURLsUpdatedSince(ctx, ownerID, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	return urls, r.openAll(urls)
+}
+
+// openAll decrypts a result set in place.
+func (r *encryptedRepository) openAll(urls []*models.URL) error {
+	for _, u := range urls {
+		if err := r.open(u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *encryptedRepository) GetURLByExternalID(ctx context.Context, system, id string) (*models.URL, error) {
+	u, err := r.URLRepository.GetURLByExternalID(ctx, system, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.open(u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (r *encryptedRepository) ListURLsByStatus(ctx context.Context, orgID, status string, limit int64) ([]*models.URL, error) {
+	urls, err := r.URLRepository.ListURLsByStatus(ctx, orgID, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	return urls, r.openAll(urls)
+}
+
+func (r *encryptedRepository) SetStatus(ctx context.Context, codes []string, status string) ([]*models.URL, error) {
+	urls, err := r.URLRepository.SetStatus(ctx, codes, status)
+	if err != nil {
+		return nil, err
+	}
+	return urls, r.openAll(urls)
+}
+
+func (r *encryptedRepository) ListDueDrafts(ctx context.Context, now time.Time, limit int64) ([]*models.URL, error) {
+	urls, err := r.URLRepository.ListDueDrafts(ctx, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	return urls, r.openAll(urls)
+}
+
+func (r *encryptedRepository) ListURLsForHealthCheck(ctx context.Context, limit int64) ([]*models.URL, error) {
+	urls, err := r.URLRepository.ListURLsForHealthCheck(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	// The rot checker probes these destinations; handing it ciphertext
+	// would mark every link broken.
+	return urls, r.openAll(urls)
+}
+
+func (r *encryptedRepository) WarmCache(ctx context.Context, urls []*models.URL) error {
+	// Callers hold plaintext; the layers below must only ever see
+	// ciphertext, so seal copies rather than mutating the caller's.
+	sealed := make([]*models.URL, 0, len(urls))
+	for _, u := range urls {
+		copied := *u
+		if err := r.seal(&copied); err != nil {
+			return err
+		}
+		sealed = append(sealed, &copied)
+	}
+	return r.URLRepository.WarmCache(ctx, sealed)
+}
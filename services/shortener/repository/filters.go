@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+// filterQuery translates a LinkFilter into a Mongo filter scoped to the
+// owner. Shared by FindURLs and the bulk-operation paths.
+func filterQuery(ownerID string, f models.LinkFilter) bson.M {
+	q := bson.M{"owner_id": ownerID}
+	if len(f.Tags) > 0 {
+		q["tags"] = bson.M{"$all": f.Tags}
+	}
+	if f.Status != "" {
+		q["status"] = f.Status
+	}
+	clicks := bson.M{}
+	if f.MinClicks > 0 {
+		clicks["$gte"] = f.MinClicks
+	}
+	if f.MaxClicks > 0 {
+		clicks["$lte"] = f.MaxClicks
+	}
+	if len(clicks) > 0 {
+		q["clicks"] = clicks
+	}
+	created := bson.M{}
+	if f.CreatedAfter != nil {
+		created["$gte"] = *f.CreatedAfter
+	}
+	if f.CreatedBefore != nil {
+		created["$lte"] = *f.CreatedBefore
+	}
+	if len(created) > 0 {
+		q["created_at"] = created
+	}
+	if f.ExpiringBefore != nil {
+		q["expires_at"] = bson.M{"$ne": nil, "$lte": *f.ExpiringBefore}
+	}
+	if f.DestinationContains != "" {
+		q["long_url"] = bson.M{"$regex": regexp.QuoteMeta(f.DestinationContains)}
+	}
+	if f.DestinationBroken != nil {
+		if *f.DestinationBroken {
+			q["destination_broken"] = true
+		} else {
+			q["destination_broken"] = bson.M{"$ne": true}
+		}
+	}
+	return q
+}
+
+// FindURLs runs a LinkFilter over the owner's links, newest first.
+func (r *mongoRepository) FindURLs(ctx context.Context, ownerID string, f models.LinkFilter) ([]*models.URL, error) {
+	defer timeOp("find_urls")()
+	cur, err := r.urls.Find(ctx, filterQuery(ownerID, f),
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, fmt.Errorf("filtering urls: %w", err)
+	}
+	defer cur.Close(ctx)
+	var out []*models.URL
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, fmt.Errorf("decoding urls: %w", err)
+	}
+	return out, nil
+}
+
+// SavedFilterRepository stores users' named filters.
+type SavedFilterRepository struct {
+	filters *mongo.Collection
+}
+
+// NewSavedFilterRepository returns a repository over db's saved_filters
+// collection.
+func NewSavedFilterRepository(db *mongo.Database) *SavedFilterRepository {
+	return &SavedFilterRepository{filters: db.Collection("saved_filters")}
+}
+
+// StoreFilter inserts a saved filter; (owner, name) is the natural key.
+func (r *SavedFilterRepository) StoreFilter(ctx context.Context, f *models.SavedFilter) error {
+	existing := r.filters.FindOne(ctx, bson.M{"owner_id": f.OwnerID, "name": f.Name})
+	if existing.Err() == nil {
+		return fmt.Errorf("filter %q: %w", f.Name, common.ErrConflict)
+	}
+	f.CreatedAt = time.Now().UTC()
+	if _, err := r.filters.InsertOne(ctx, f); err != nil {
+		return fmt.Errorf("inserting filter: %w", err)
+	}
+	return nil
+}
+
+// GetFilter returns the owner's filter by name.
+func (r *SavedFilterRepository) GetFilter(ctx context.Context, ownerID, name string) (*models.SavedFilter, error) {
+	var f models.SavedFilter
+	err := r.filters.FindOne(ctx, bson.M{"owner_id": ownerID, "name": name}).Decode(&f)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("filter %q: %w", name, common.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetching filter: %w", err)
+	}
+	return &f, nil
+}
+
+// DeleteFilter removes the owner's filter by name.
+func (r *SavedFilterRepository) DeleteFilter(ctx context.Context, ownerID, name string) error {
+	res, err := r.filters.DeleteOne(ctx, bson.M{"owner_id": ownerID, "name": name})
+	if err != nil {
+		return fmt.Errorf("deleting filter: %w", err)
+	}
+	if res.DeletedCount == 0 {
+		return fmt.Errorf("filter %q: %w", name, common.ErrNotFound)
+	}
+	return nil
+}
+
+// ListFilters returns the owner's saved filters.
+func (r *SavedFilterRepository) ListFilters(ctx context.Context, ownerID string) ([]*models.SavedFilter, error) {
+	cur, err := r.filters.Find(ctx, bson.M{"owner_id": ownerID},
+		options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("listing filters: %w", err)
+	}
+	defer cur.Close(ctx)
+	var out []*models.SavedFilter
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, fmt.Errorf("decoding filters: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// expectedIndexes maps the queries the service actually issues to the
+// index (by leading key) that should support them. The advisor warns when
+// one is missing, instead of the on-call learning it from a slowdown.
+var expectedIndexes = map[string]string{
+	"code":         "GetURLByCode / CodesExist / prefix suggestions",
+	"owner_id":     "ListURLsByOwner / FindURLs / bulk operations",
+	"updated_at":   "delta sync (ListURLsUpdatedSince)",
+	"org_id":       "CountURLsByOrg (plan quota checks)",
+	"status":       "moderation queue (ListURLsByStatus)",
+	"workspace_id": "workspace listings",
+}
+
+// AdviseIndexes inspects the urls collection's indexes and their usage
+// stats, returning human-readable warnings for missing supporting indexes
+// and indexes that have never been used. Advisory only: it never mutates.
+func AdviseIndexes(ctx context.Context, db *mongo.Database) ([]string, error) {
+	urls := db.Collection("urls")
+	cur, err := urls.Indexes().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing indexes: %w", err)
+	}
+	defer cur.Close(ctx)
+	leading := map[string]bool{}
+	for cur.Next(ctx) {
+		var idx struct {
+			Key bson.D `bson:"key"`
+		}
+		if err := cur.Decode(&idx); err != nil {
+			return nil, fmt.Errorf("decoding index: %w", err)
+		}
+		if len(idx.Key) > 0 {
+			leading[idx.Key[0].Key] = true
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return nil, fmt.Errorf("iterating indexes: %w", err)
+	}
+
+	var warnings []string
+	for field, usedBy := range expectedIndexes {
+		if !leading[field] {
+			warnings = append(warnings, fmt.Sprintf(
+				"urls has no index leading on %q; %s will collection-scan", field, usedBy))
+		}
+	}
+
+	stats, err := urls.Aggregate(ctx, bson.A{bson.M{"$indexStats": bson.M{}}})
+	if err != nil {
+		// $indexStats needs privileges some deployments withhold; the
+		// missing-index advice above is still valid.
+		warnings = append(warnings, fmt.Sprintf("index usage stats unavailable: %v", err))
+		return warnings, nil
+	}
+	defer stats.Close(ctx)
+	for stats.Next(ctx) {
+		var stat struct {
+			Name     string `bson:"name"`
+			Accesses struct {
+				Ops int64 `bson:"ops"`
+			} `bson:"accesses"`
+		}
+		if err := stats.Decode(&stat); err != nil {
+			return nil, fmt.Errorf("decoding index stats: %w", err)
+		}
+		if stat.Accesses.Ops == 0 && stat.Name != "_id_" {
+			warnings = append(warnings, fmt.Sprintf(
+				"index %q has never been used since the last restart; consider dropping it", stat.Name))
+		}
+	}
+	if err := stats.Err(); err != nil {
+		return nil, fmt.Errorf("iterating index stats: %w", err)
+	}
+	return warnings, nil
+}
+
+// LogIndexAdvice runs the advisor and logs its findings; intended for
+// startup.
+func LogIndexAdvice(ctx context.Context, db *mongo.Database, logf func(format string, v ...interface{})) {
+	warnings, err := AdviseIndexes(ctx, db)
+	if err != nil {
+		logf("index advisor: %v", err)
+		return
+	}
+	if len(warnings) == 0 {
+		logf("index advisor: all expected indexes present and in use")
+		return
+	}
+	logf("index advisor: %d findings:\n  %s", len(warnings), strings.Join(warnings, "\n  "))
+}
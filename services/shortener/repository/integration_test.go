@@ -0,0 +1,288 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ashtonholgate/url-minifier/internal/testharness"
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+// Shared containers for the whole package, started once in TestMain. When
+// Docker is unavailable the integration tests skip rather than fail, so
+// the unit suites still run everywhere.
+var (
+	testMongo *mongo.Client
+	testRedis *redis.Client
+)
+
+func TestMain(m *testing.M) {
+	mongoDep, err := testharness.StartMongo()
+	if err != nil {
+		log.Printf("integration tests skipped: %v", err)
+		os.Exit(m.Run())
+	}
+	defer mongoDep.Close()
+	redisDep, err := testharness.StartRedis()
+	if err != nil {
+		log.Printf("integration tests skipped: %v", err)
+		os.Exit(m.Run())
+	}
+	defer redisDep.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	testMongo, err = mongo.Connect(ctx, options.Client().ApplyURI(mongoDep.URI))
+	if err != nil {
+		log.Fatalf("connecting to test mongo: %v", err)
+	}
+	testRedis = redis.NewClient(&redis.Options{Addr: redisDep.URI})
+
+	code := m.Run()
+	testMongo.Disconnect(context.Background())
+	testRedis.Close()
+	os.Exit(code)
+}
+
+// newTestRepo returns a cached repository over a fresh database per test.
+func newTestRepo(t *testing.T) URLRepository {
+	t.Helper()
+	if testMongo == nil {
+		t.Skip("docker unavailable; integration test skipped")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	db := testMongo.Database(fmt.Sprintf("shortener_test_%d", time.Now().UnixNano()))
+	t.Cleanup(func() { db.Drop(context.Background()) })
+	repo, err := NewMongoRepository(ctx, db)
+	if err != nil {
+		t.Fatalf("NewMongoRepository: %v", err)
+	}
+	return NewCachedRepository(repo, testRedis)
+}
+
+func testURL(code, ownerID string) *models.URL {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	return &models.URL{
+		Code:      code,
+		LongURL:   "https://example.com/" + code,
+		OwnerID:   ownerID,
+		OrgID:     "org1",
+		Status:    models.URLStatusActive,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func TestStoreAndGetRoundTrip(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	u := testURL("abc1234", "user1")
+	if err := repo.StoreURL(ctx, u); err != nil {
+		t.Fatalf("StoreURL: %v", err)
+	}
+	got, err := repo.GetURLByCode(ctx, "abc1234")
+	if err != nil {
+		t.Fatalf("GetURLByCode: %v", err)
+	}
+	if got.LongURL != u.LongURL || got.OwnerID != u.OwnerID {
+		t.Errorf("round trip mismatch: got %+v", got)
+	}
+	// Second read exercises the cache path and must agree.
+	cached, err := repo.GetURLByCode(ctx, "abc1234")
+	if err != nil {
+		t.Fatalf("cached GetURLByCode: %v", err)
+	}
+	if cached.LongURL != u.LongURL {
+		t.Errorf("cached read mismatch: got %q", cached.LongURL)
+	}
+}
+
+func TestStoreURLConflict(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	if err := repo.StoreURL(ctx, testURL("dup", "user1")); err != nil {
+		t.Fatalf("StoreURL: %v", err)
+	}
+	err := repo.StoreURL(ctx, testURL("dup", "user2"))
+	if !errors.Is(err, common.ErrConflict) {
+		t.Errorf("duplicate code: want ErrConflict, got %v", err)
+	}
+}
+
+func TestGetURLByCodeNotFound(t *testing.T) {
+	repo := newTestRepo(t)
+	_, err := repo.GetURLByCode(context.Background(), "missing")
+	if !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("want ErrNotFound, got %v", err)
+	}
+}
+
+func TestUpdateURLOptimisticConcurrency(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	u := testURL("occ1", "user1")
+	if err := repo.StoreURL(ctx, u); err != nil {
+		t.Fatalf("StoreURL: %v", err)
+	}
+	first, err := repo.GetURLByCode(ctx, "occ1")
+	if err != nil {
+		t.Fatalf("GetURLByCode: %v", err)
+	}
+	stale := *first
+
+	first.LongURL = "https://example.com/updated"
+	if err := repo.UpdateURL(ctx, first); err != nil {
+		t.Fatalf("UpdateURL: %v", err)
+	}
+	if first.Version != stale.Version+1 {
+		t.Errorf("version: want %d, got %d", stale.Version+1, first.Version)
+	}
+
+	// A writer holding the old version must lose the race.
+	stale.LongURL = "https://example.com/lost-race"
+	err = repo.UpdateURL(ctx, &stale)
+	if !errors.Is(err, common.ErrConflict) {
+		t.Errorf("stale update: want ErrConflict, got %v", err)
+	}
+}
+
+func TestDeleteURLInvalidatesCache(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	if err := repo.StoreURL(ctx, testURL("gone", "user1")); err != nil {
+		t.Fatalf("StoreURL: %v", err)
+	}
+	if _, err := repo.GetURLByCode(ctx, "gone"); err != nil {
+		t.Fatalf("GetURLByCode: %v", err)
+	}
+	if err := repo.DeleteURL(ctx, "gone"); err != nil {
+		t.Fatalf("DeleteURL: %v", err)
+	}
+	if _, err := repo.GetURLByCode(ctx, "gone"); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("after delete: want ErrNotFound, got %v", err)
+	}
+}
+
+func TestListURLsByOwnerPagination(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		u := testURL(fmt.Sprintf("page%d", i), "owner")
+		u.CreatedAt = u.CreatedAt.Add(time.Duration(i) * time.Second)
+		if err := repo.StoreURL(ctx, u); err != nil {
+			t.Fatalf("StoreURL: %v", err)
+		}
+	}
+	first, err := repo.ListURLsByOwner(ctx, "owner", 2, 0, nil)
+	if err != nil {
+		t.Fatalf("ListURLsByOwner: %v", err)
+	}
+	if len(first) != 2 {
+		t.Fatalf("first page: want 2, got %d", len(first))
+	}
+	// Newest first.
+	if first[0].Code != "page4" || first[1].Code != "page3" {
+		t.Errorf("ordering: got %s, %s", first[0].Code, first[1].Code)
+	}
+	rest, err := repo.ListURLsByOwner(ctx, "owner", 10, 2, nil)
+	if err != nil {
+		t.Fatalf("ListURLsByOwner offset: %v", err)
+	}
+	if len(rest) != 3 {
+		t.Errorf("second page: want 3, got %d", len(rest))
+	}
+}
+
+func TestFindURLsFilter(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	tagged := testURL("tagged", "owner")
+	tagged.Tags = []string{"campaign", "q3"}
+	plain := testURL("plain", "owner")
+	for _, u := range []*models.URL{tagged, plain} {
+		if err := repo.StoreURL(ctx, u); err != nil {
+			t.Fatalf("StoreURL: %v", err)
+		}
+	}
+	got, err := repo.FindURLs(ctx, "owner", models.LinkFilter{Tags: []string{"campaign"}})
+	if err != nil {
+		t.Fatalf("FindURLs: %v", err)
+	}
+	if len(got) != 1 || got[0].Code != "tagged" {
+		t.Errorf("tag filter: got %d results", len(got))
+	}
+}
+
+func TestBulkRetagAndDelete(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		u := testURL(fmt.Sprintf("bulk%d", i), "owner")
+		u.Tags = []string{"old"}
+		if err := repo.StoreURL(ctx, u); err != nil {
+			t.Fatalf("StoreURL: %v", err)
+		}
+	}
+	codes, err := repo.BulkRetagURLs(ctx, "owner",
+		models.LinkFilter{Tags: []string{"old"}}, []string{"new"}, []string{"old"})
+	if err != nil {
+		t.Fatalf("BulkRetagURLs: %v", err)
+	}
+	if len(codes) != 3 {
+		t.Fatalf("retag: want 3 codes, got %d", len(codes))
+	}
+	retagged, err := repo.FindURLs(ctx, "owner", models.LinkFilter{Tags: []string{"new"}})
+	if err != nil || len(retagged) != 3 {
+		t.Fatalf("after retag: got %d, err %v", len(retagged), err)
+	}
+
+	deleted, err := repo.BulkDeleteURLs(ctx, "owner", models.LinkFilter{Tags: []string{"new"}})
+	if err != nil || len(deleted) != 3 {
+		t.Fatalf("BulkDeleteURLs: got %d, err %v", len(deleted), err)
+	}
+	remaining, err := repo.ListURLsByOwner(ctx, "owner", 10, 0, nil)
+	if err != nil || len(remaining) != 0 {
+		t.Errorf("after bulk delete: %d remaining, err %v", len(remaining), err)
+	}
+}
+
+func TestSetStatusControlsResolution(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	if err := repo.StoreURL(ctx, testURL("modme", "owner")); err != nil {
+		t.Fatalf("StoreURL: %v", err)
+	}
+	updated, err := repo.SetStatus(ctx, []string{"modme"}, models.URLStatusRejected)
+	if err != nil {
+		t.Fatalf("SetStatus: %v", err)
+	}
+	if len(updated) != 1 || updated[0].Status != models.URLStatusRejected {
+		t.Fatalf("SetStatus result: %+v", updated)
+	}
+	got, err := repo.GetURLByCode(ctx, "modme")
+	if err != nil {
+		t.Fatalf("GetURLByCode: %v", err)
+	}
+	if got.Active() {
+		t.Error("rejected link still reports Active")
+	}
+}
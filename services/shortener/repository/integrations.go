@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/connectors"
+)
+
+// Integration is one configured outbound connector for an org.
+type Integration struct {
+	ID        string            `bson:"_id,omitempty" json:"id"`
+	OrgID     string            `bson:"org_id" json:"org_id"`
+	Type      string            `bson:"type" json:"type"`
+	Config    map[string]string `bson:"config" json:"config"`
+	CreatedAt time.Time         `bson:"created_at" json:"created_at"`
+}
+
+// IntegrationRepository stores per-org connector configuration.
+type IntegrationRepository struct {
+	integrations *mongo.Collection
+}
+
+// NewIntegrationRepository returns a repository over db's integrations
+// collection.
+func NewIntegrationRepository(db *mongo.Database) *IntegrationRepository {
+	return &IntegrationRepository{integrations: db.Collection("integrations")}
+}
+
+// StoreIntegration inserts a configured connector after validating its
+// type-specific required fields.
+func (r *IntegrationRepository) StoreIntegration(ctx context.Context, i *Integration) error {
+	if _, err := build(i); err != nil {
+		return err
+	}
+	i.CreatedAt = time.Now().UTC()
+	if i.ID == "" {
+		i.ID = fmt.Sprintf("int_%d", time.Now().UnixNano())
+	}
+	if _, err := r.integrations.InsertOne(ctx, i); err != nil {
+		return fmt.Errorf("inserting integration: %w", err)
+	}
+	return nil
+}
+
+// DeleteIntegration removes a connector configuration.
+func (r *IntegrationRepository) DeleteIntegration(ctx context.Context, orgID, id string) error {
+	res, err := r.integrations.DeleteOne(ctx, bson.M{"_id": id, "org_id": orgID})
+	if err != nil {
+		return fmt.Errorf("deleting integration: %w", err)
+	}
+	if res.DeletedCount == 0 {
+		return fmt.Errorf("integration %q: %w", id, common.ErrNotFound)
+	}
+	return nil
+}
+
+// ListIntegrations returns an org's configured connectors.
+func (r *IntegrationRepository) ListIntegrations(ctx context.Context, orgID string) ([]*Integration, error) {
+	cur, err := r.integrations.Find(ctx, bson.M{"org_id": orgID})
+	if err != nil {
+		return nil, fmt.Errorf("listing integrations: %w", err)
+	}
+	defer cur.Close(ctx)
+	var out []*Integration
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, fmt.Errorf("decoding integrations: %w", err)
+	}
+	return out, nil
+}
+
+// build materialises the connector for a stored configuration.
+func build(i *Integration) (connectors.Connector, error) {
+	switch i.Type {
+	case "slack":
+		if i.Config["webhook_url"] == "" {
+			return nil, fmt.Errorf("slack integration needs webhook_url: %w", common.ErrInvalidInput)
+		}
+		return connectors.SlackConnector{WebhookURL: i.Config["webhook_url"]}, nil
+	case "sheets":
+		if i.Config["spreadsheet_id"] == "" || i.Config["access_token"] == "" {
+			return nil, fmt.Errorf("sheets integration needs spreadsheet_id and access_token: %w", common.ErrInvalidInput)
+		}
+		rng := i.Config["range"]
+		if rng == "" {
+			rng = "Sheet1!A:C"
+		}
+		return connectors.SheetsConnector{
+			SpreadsheetID: i.Config["spreadsheet_id"],
+			Range:         rng,
+			AccessToken:   i.Config["access_token"],
+		}, nil
+	case "webhook":
+		if i.Config["url"] == "" {
+			return nil, fmt.Errorf("webhook integration needs url: %w", common.ErrInvalidInput)
+		}
+		return connectors.WebhookConnector{URL: i.Config["url"], Secret: i.Config["secret"]}, nil
+	default:
+		return nil, fmt.Errorf("unknown integration type %q: %w", i.Type, common.ErrInvalidInput)
+	}
+}
+
+// ConnectorsFor implements the dispatcher's resolver.
+func (r *IntegrationRepository) ConnectorsFor(ctx context.Context, orgID string) ([]connectors.Connector, error) {
+	stored, err := r.ListIntegrations(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]connectors.Connector, 0, len(stored))
+	for _, i := range stored {
+		conn, err := build(i)
+		if err != nil {
+			// A config that validated at write time but fails now is
+			// logged by the dispatcher; skip it.
+			continue
+		}
+		out = append(out, conn)
+	}
+	return out, nil
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+)
+
+// Invite is a pending membership offer, delivered by email as a token
+// link.
+type Invite struct {
+	Token       string     `bson:"token" json:"token"`
+	Email       string     `bson:"email" json:"email"`
+	OrgID       string     `bson:"org_id,omitempty" json:"org_id,omitempty"`
+	WorkspaceID string     `bson:"workspace_id,omitempty" json:"workspace_id,omitempty"`
+	Role        string     `bson:"role" json:"role"`
+	CreatedBy   string     `bson:"created_by" json:"created_by"`
+	CreatedAt   time.Time  `bson:"created_at" json:"created_at"`
+	ExpiresAt   time.Time  `bson:"expires_at" json:"expires_at"`
+	AcceptedAt  *time.Time `bson:"accepted_at,omitempty" json:"accepted_at,omitempty"`
+}
+
+// Pending reports whether the invite can still be accepted.
+func (i *Invite) Pending(now time.Time) bool {
+	return i.AcceptedAt == nil && now.Before(i.ExpiresAt)
+}
+
+// InviteRepository stores invites.
+type InviteRepository struct {
+	invites *mongo.Collection
+}
+
+// NewInviteRepository returns a repository over db's invites collection.
+func NewInviteRepository(db *mongo.Database) *InviteRepository {
+	return &InviteRepository{invites: db.Collection("invites")}
+}
+
+// StoreInvite inserts a new invite, minting its token.
+func (r *InviteRepository) StoreInvite(ctx context.Context, inv *Invite) error {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Errorf("generating invite token: %w", err)
+	}
+	inv.Token = hex.EncodeToString(buf)
+	inv.CreatedAt = time.Now().UTC()
+	if _, err := r.invites.InsertOne(ctx, inv); err != nil {
+		return fmt.Errorf("inserting invite: %w", err)
+	}
+	return nil
+}
+
+// GetInvite returns the invite by token.
+func (r *InviteRepository) GetInvite(ctx context.Context, token string) (*Invite, error) {
+	var inv Invite
+	err := r.invites.FindOne(ctx, bson.M{"token": token}).Decode(&inv)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("invite: %w", common.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetching invite: %w", err)
+	}
+	return &inv, nil
+}
+
+// MarkAccepted records acceptance exactly once.
+func (r *InviteRepository) MarkAccepted(ctx context.Context, token string) error {
+	now := time.Now().UTC()
+	res, err := r.invites.UpdateOne(ctx,
+		bson.M{"token": token, "accepted_at": nil},
+		bson.M{"$set": bson.M{"accepted_at": now}})
+	if err != nil {
+		return fmt.Errorf("accepting invite: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("invite already accepted: %w", common.ErrConflict)
+	}
+	return nil
+}
+
+// ListPendingInvites returns unaccepted, unexpired invites for an org or
+// workspace scope.
+func (r *InviteRepository) ListPendingInvites(ctx context.Context, orgID, workspaceID string) ([]*Invite, error) {
+	filter := bson.M{
+		"accepted_at": nil,
+		"expires_at":  bson.M{"$gt": time.Now().UTC()},
+	}
+	if workspaceID != "" {
+		filter["workspace_id"] = workspaceID
+	} else {
+		filter["org_id"] = orgID
+	}
+	cur, err := r.invites.Find(ctx, filter,
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, fmt.Errorf("listing invites: %w", err)
+	}
+	defer cur.Close(ctx)
+	var out []*Invite
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, fmt.Errorf("decoding invites: %w", err)
+	}
+	return out, nil
+}
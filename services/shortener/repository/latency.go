@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"log"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "urlminifier",
+		Name:      "repository_query_duration_seconds",
+		Help:      "Repository operation latency by operation.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"op"})
+	slowQueries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "urlminifier",
+		Name:      "repository_slow_queries_total",
+		Help:      "Repository operations exceeding their latency budget.",
+	}, []string{"op"})
+)
+
+// defaultBudget applies to operations without an explicit entry below.
+const defaultBudget = 100 * time.Millisecond
+
+// latencyBudgets are per-operation ceilings. Point reads sit on the
+// redirect hot path and get the tightest budget; scans and bulk work get
+// headroom. Exceeding a budget logs a structured slow-query warning and
+// increments the metric, so index regressions surface immediately.
+var latencyBudgets = map[string]time.Duration{
+	"get_url_by_code":    25 * time.Millisecond,
+	"store_url":          50 * time.Millisecond,
+	"update_url":         50 * time.Millisecond,
+	"increment_clicks":   25 * time.Millisecond,
+	"list_urls_by_owner": 100 * time.Millisecond,
+	"find_urls":          250 * time.Millisecond,
+	"bulk":               time.Second,
+}
+
+// timeOp starts timing a repository operation against the urls
+// collection; call the returned function when the operation completes
+// (usually via defer).
+func timeOp(op string) func() {
+	start := time.Now()
+	return func() {
+		elapsed := time.Since(start)
+		queryDuration.WithLabelValues(op).Observe(elapsed.Seconds())
+		budget, ok := latencyBudgets[op]
+		if !ok {
+			budget = defaultBudget
+		}
+		if elapsed > budget {
+			slowQueries.WithLabelValues(op).Inc()
+			log.Printf("slow-query op=%s collection=urls duration=%s budget=%s",
+				op, elapsed.Round(time.Millisecond), budget)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"container/list"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+var localCacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "urlminifier",
+	Name:      "local_cache_requests_total",
+	Help:      "In-process cache tier lookups by result (hit, miss, rejected_admission).",
+}, []string{"result"})
+
+// Local tier sizing: a few thousand genuinely hot links cover the head of
+// a zipfian click distribution.
+const (
+	localCacheCapacity = 8192
+	sketchCounters     = 1 << 16
+	sketchHashes       = 4
+	// sketchResetAfter halves all counters after this many increments,
+	// aging out yesterday's hot set (the TinyLFU reset).
+	sketchResetAfter = localCacheCapacity * 32
+	// localCacheTTL bounds how stale an in-process entry can get in
+	// OTHER processes: mutations invalidate locally and broadcast over
+	// pub/sub, and the TTL caps the damage when a broadcast is missed —
+	// without it a deleted or redirected hot link could be served from
+	// another instance's tier indefinitely.
+	localCacheTTL = 30 * time.Second
+)
+
+// freqSketch is a small count-min sketch of lookup frequencies.
+type freqSketch struct {
+	counters []uint8
+	adds     int
+}
+
+func newFreqSketch() *freqSketch {
+	return &freqSketch{counters: make([]uint8, sketchCounters)}
+}
+
+func sketchPositions(code string) [sketchHashes]uint32 {
+	h := fnv.New64a()
+	h.Write([]byte(code))
+	h1 := uint32(h.Sum64())
+	h2 := uint32(h.Sum64() >> 32)
+	var out [sketchHashes]uint32
+	for i := range out {
+		out[i] = (h1 + uint32(i)*h2) % sketchCounters
+	}
+	return out
+}
+
+func (s *freqSketch) increment(code string) {
+	for _, p := range sketchPositions(code) {
+		if s.counters[p] < 255 {
+			s.counters[p]++
+		}
+	}
+	s.adds++
+	if s.adds >= sketchResetAfter {
+		for i := range s.counters {
+			s.counters[i] /= 2
+		}
+		s.adds = 0
+	}
+}
+
+func (s *freqSketch) estimate(code string) uint8 {
+	min := uint8(255)
+	for _, p := range sketchPositions(code) {
+		if s.counters[p] < min {
+			min = s.counters[p]
+		}
+	}
+	return min
+}
+
+// localCache is the in-process tier with TinyLFU-style admission: a
+// one-off long-tail lookup cannot evict a genuinely hot link because the
+// victim's estimated frequency wins.
+type localCache struct {
+	mu      sync.Mutex
+	sketch  *freqSketch
+	entries map[string]*list.Element
+	order   *list.List // front = most recent
+}
+
+type localEntry struct {
+	code     string
+	url      models.URL
+	storedAt time.Time
+}
+
+func newLocalCache() *localCache {
+	return &localCache{
+		sketch:  newFreqSketch(),
+		entries: make(map[string]*list.Element, localCacheCapacity),
+		order:   list.New(),
+	}
+}
+
+// get returns a copy of the cached link and records the access in the
+// frequency sketch either way.
+func (c *localCache) get(code string) (*models.URL, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sketch.increment(code)
+	elem, ok := c.entries[code]
+	if !ok {
+		localCacheRequests.WithLabelValues("miss").Inc()
+		return nil, false
+	}
+	entry := elem.Value.(*localEntry)
+	if time.Since(entry.storedAt) > localCacheTTL {
+		c.order.Remove(elem)
+		delete(c.entries, code)
+		localCacheRequests.WithLabelValues("miss").Inc()
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	localCacheRequests.WithLabelValues("hit").Inc()
+	u := entry.url
+	return &u, true
+}
+
+// offer proposes a link for admission. When full, the candidate must beat
+// the LRU victim's estimated frequency to enter.
+func (c *localCache) offer(u *models.URL) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[u.Code]; ok {
+		entry := elem.Value.(*localEntry)
+		entry.url = *u
+		entry.storedAt = time.Now()
+		c.order.MoveToFront(elem)
+		return
+	}
+	if c.order.Len() >= localCacheCapacity {
+		victim := c.order.Back()
+		victimCode := victim.Value.(*localEntry).code
+		if c.sketch.estimate(u.Code) <= c.sketch.estimate(victimCode) {
+			localCacheRequests.WithLabelValues("rejected_admission").Inc()
+			return
+		}
+		c.order.Remove(victim)
+		delete(c.entries, victimCode)
+	}
+	c.entries[u.Code] = c.order.PushFront(&localEntry{code: u.Code, url: *u, storedAt: time.Now()})
+}
+
+// remove invalidates one code.
+func (c *localCache) remove(codes ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, code := range codes {
+		if elem, ok := c.entries[code]; ok {
+			c.order.Remove(elem)
+			delete(c.entries, code)
+		}
+	}
+}
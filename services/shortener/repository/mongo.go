@@ -0,0 +1,347 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+// mongoRepository stores URLs in the urls collection of the shortener
+// database.
+type mongoRepository struct {
+	urls *mongo.Collection
+}
+
+// NewMongoRepository returns a URLRepository backed by the given database and
+// ensures the indexes the service queries rely on.
+func NewMongoRepository(ctx context.Context, db *mongo.Database) (URLRepository, error) {
+	if err := EnsureURLIndexes(ctx, db); err != nil {
+		return nil, err
+	}
+	return &mongoRepository{urls: db.Collection("urls")}, nil
+}
+
+// EnsureURLIndexes creates every index the urls collection needs — the set
+// the index advisor checks for. Shared by the repository constructor and
+// admin bootstrap so a provisioned deployment never starts out
+// collection-scanning. CreateOne is a no-op for an existing identical
+// index.
+func EnsureURLIndexes(ctx context.Context, db *mongo.Database) error {
+	urls := db.Collection("urls")
+	indexes := []mongo.IndexModel{
+		// Resolution and alias checks.
+		{
+			Keys:    bson.D{{Key: "code", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		// ListURLsByOwner / FindURLs / bulk operations, sorted newest
+		// first.
+		{Keys: bson.D{{Key: "owner_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		// Delta sync walks updated_at ascending.
+		{Keys: bson.D{{Key: "updated_at", Value: 1}}},
+		// Plan quota counts and storage accounting.
+		{Keys: bson.D{{Key: "org_id", Value: 1}}},
+		// Moderation queue and draft publishing, oldest first.
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}}},
+		// Workspace listings.
+		{Keys: bson.D{{Key: "workspace_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		// Wildcard index over external_ids supports lookup by any source
+		// system without per-system indexes.
+		{Keys: bson.D{{Key: "external_ids.$**", Value: 1}}},
+	}
+	for _, model := range indexes {
+		if _, err := urls.Indexes().CreateOne(ctx, model); err != nil {
+			return fmt.Errorf("creating urls index %v: %w", model.Keys, err)
+		}
+	}
+	return nil
+}
+
+// NewReadOnlyMongoRepository returns a URLRepository over the same
+// collection without ensuring indexes, for read-only consumers (the
+// redirect service) that must not need write privileges at startup.
+func NewReadOnlyMongoRepository(db *mongo.Database) URLRepository {
+	return &mongoRepository{urls: db.Collection("urls")}
+}
+
+func (r *mongoRepository) StoreURL(ctx context.Context, u *models.URL) error {
+	defer timeOp("store_url")()
+	_, err := r.urls.InsertOne(ctx, u)
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("code %q: %w", u.Code, common.ErrConflict)
+	}
+	if err != nil {
+		return fmt.Errorf("inserting url: %w", err)
+	}
+	return nil
+}
+
+func (r *mongoRepository) GetURLByCode(ctx context.Context, code string) (*models.URL, error) {
+	defer timeOp("get_url_by_code")()
+	var u models.URL
+	err := r.urls.FindOne(ctx, bson.M{"code": code}).Decode(&u)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("code %q: %w", code, common.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetching url: %w", err)
+	}
+	return &u, nil
+}
+
+func (r *mongoRepository) GetURLByExternalID(ctx context.Context, system, id string) (*models.URL, error) {
+	defer timeOp("get_url_by_external_id")()
+	var u models.URL
+	err := r.urls.FindOne(ctx, bson.M{"external_ids." + system: id}).Decode(&u)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("external id %s/%s: %w", system, id, common.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetching url by external id: %w", err)
+	}
+	return &u, nil
+}
+
+func (r *mongoRepository) UpdateURL(ctx context.Context, u *models.URL) error {
+	defer timeOp("update_url")()
+	u.UpdatedAt = time.Now().UTC()
+	// Optimistic concurrency: the replace only matches the version the
+	// caller read, and the stored document moves to the next version.
+	readVersion := u.Version
+	u.Version++
+	res, err := r.urls.ReplaceOne(ctx, bson.M{"code": u.Code, "version": readVersion}, u)
+	if err != nil {
+		u.Version = readVersion
+		return fmt.Errorf("updating url: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		u.Version = readVersion
+		// Distinguish a lost race from a missing document.
+		if _, getErr := r.GetURLByCode(ctx, u.Code); getErr != nil {
+			return getErr
+		}
+		return fmt.Errorf("code %q was modified concurrently: %w", u.Code, common.ErrConflict)
+	}
+	return nil
+}
+
+func (r *mongoRepository) DeleteURL(ctx context.Context, code string) error {
+	defer timeOp("delete_url")()
+	res, err := r.urls.DeleteOne(ctx, bson.M{"code": code})
+	if err != nil {
+		return fmt.Errorf("deleting url: %w", err)
+	}
+	if res.DeletedCount == 0 {
+		return fmt.Errorf("code %q: %w", code, common.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *mongoRepository) ListURLsByOwner(ctx context.Context, ownerID string, limit, offset int64, fields []string) ([]*models.URL, error) {
+	defer timeOp("list_urls_by_owner")()
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+	if offset > 0 {
+		opts.SetSkip(offset)
+	}
+	if len(fields) > 0 {
+		projection := bson.M{"code": 1}
+		for _, f := range fields {
+			projection[f] = 1
+		}
+		opts.SetProjection(projection)
+	}
+	cur, err := r.urls.Find(ctx, bson.M{"owner_id": ownerID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("listing urls: %w", err)
+	}
+	defer cur.Close(ctx)
+	var out []*models.URL
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, fmt.Errorf("decoding urls: %w", err)
+	}
+	return out, nil
+}
+
+func (r *mongoRepository) ListURLsUpdatedSince(ctx context.Context, ownerID string, since time.Time, limit int64) ([]*models.URL, error) {
+	defer timeOp("list_urls_updated_since")()
+	cur, err := r.urls.Find(ctx,
+		bson.M{"owner_id": ownerID, "updated_at": bson.M{"$gt": since}},
+		options.Find().SetSort(bson.D{{Key: "updated_at", Value: 1}}).SetLimit(limit))
+	if err != nil {
+		return nil, fmt.Errorf("listing updated urls: %w", err)
+	}
+	defer cur.Close(ctx)
+	var out []*models.URL
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, fmt.Errorf("decoding urls: %w", err)
+	}
+	return out, nil
+}
+
+func (r *mongoRepository) CountURLsByOrg(ctx context.Context, orgID string) (int64, error) {
+	defer timeOp("count_urls_by_org")()
+	n, err := r.urls.CountDocuments(ctx, bson.M{"org_id": orgID})
+	if err != nil {
+		return 0, fmt.Errorf("counting urls: %w", err)
+	}
+	return n, nil
+}
+
+func (r *mongoRepository) CodesExist(ctx context.Context, codes []string) (map[string]bool, error) {
+	defer timeOp("codes_exist")()
+	out := make(map[string]bool, len(codes))
+	for _, c := range codes {
+		out[c] = false
+	}
+	cur, err := r.urls.Find(ctx, bson.M{"code": bson.M{"$in": codes}},
+		options.Find().SetProjection(bson.M{"code": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("checking codes: %w", err)
+	}
+	defer cur.Close(ctx)
+	for cur.Next(ctx) {
+		var doc struct {
+			Code string `bson:"code"`
+		}
+		if err := cur.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("decoding code: %w", err)
+		}
+		out[doc.Code] = true
+	}
+	if err := cur.Err(); err != nil {
+		return nil, fmt.Errorf("iterating codes: %w", err)
+	}
+	return out, nil
+}
+
+func (r *mongoRepository) ListCodesByPrefix(ctx context.Context, prefix string, limit int64) ([]string, error) {
+	defer timeOp("list_codes_by_prefix")()
+	// Anchored regex on the unique code index stays an index range scan.
+	filter := bson.M{"code": bson.M{"$regex": "^" + regexp.QuoteMeta(prefix)}}
+	cur, err := r.urls.Find(ctx, filter,
+		options.Find().SetProjection(bson.M{"code": 1}).SetLimit(limit))
+	if err != nil {
+		return nil, fmt.Errorf("listing codes by prefix: %w", err)
+	}
+	defer cur.Close(ctx)
+	var out []string
+	for cur.Next(ctx) {
+		var doc struct {
+			Code string `bson:"code"`
+		}
+		if err := cur.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("decoding code: %w", err)
+		}
+		out = append(out, doc.Code)
+	}
+	if err := cur.Err(); err != nil {
+		return nil, fmt.Errorf("iterating codes: %w", err)
+	}
+	return out, nil
+}
+
+func (r *mongoRepository) ListURLsForHealthCheck(ctx context.Context, limit int64) ([]*models.URL, error) {
+	cur, err := r.urls.Find(ctx, bson.M{},
+		options.Find().
+			SetSort(bson.D{{Key: "last_checked_at", Value: 1}}).
+			SetLimit(limit).
+			SetProjection(bson.M{"code": 1, "long_url": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("listing urls for health check: %w", err)
+	}
+	defer cur.Close(ctx)
+	var out []*models.URL
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, fmt.Errorf("decoding urls: %w", err)
+	}
+	return out, nil
+}
+
+func (r *mongoRepository) SetDestinationHealth(ctx context.Context, code string, broken bool) error {
+	_, err := r.urls.UpdateOne(ctx, bson.M{"code": code}, bson.M{"$set": bson.M{
+		"destination_broken": broken,
+		"last_checked_at":    time.Now().UTC(),
+	}})
+	if err != nil {
+		return fmt.Errorf("recording destination health: %w", err)
+	}
+	return nil
+}
+
+func (r *mongoRepository) ListURLsByStatus(ctx context.Context, orgID, status string, limit int64) ([]*models.URL, error) {
+	filter := bson.M{"status": status}
+	if orgID != "" {
+		filter["org_id"] = orgID
+	}
+	cur, err := r.urls.Find(ctx, filter,
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}).SetLimit(limit))
+	if err != nil {
+		return nil, fmt.Errorf("listing urls by status: %w", err)
+	}
+	defer cur.Close(ctx)
+	var out []*models.URL
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, fmt.Errorf("decoding urls: %w", err)
+	}
+	return out, nil
+}
+
+func (r *mongoRepository) ListDueDrafts(ctx context.Context, now time.Time, limit int64) ([]*models.URL, error) {
+	cur, err := r.urls.Find(ctx, bson.M{
+		"status":     models.URLStatusDraft,
+		"publish_at": bson.M{"$lte": now},
+	}, options.Find().SetSort(bson.D{{Key: "publish_at", Value: 1}}).SetLimit(limit))
+	if err != nil {
+		return nil, fmt.Errorf("listing due drafts: %w", err)
+	}
+	defer cur.Close(ctx)
+	var out []*models.URL
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, fmt.Errorf("decoding drafts: %w", err)
+	}
+	return out, nil
+}
+
+func (r *mongoRepository) SetStatus(ctx context.Context, codes []string, status string) ([]*models.URL, error) {
+	_, err := r.urls.UpdateMany(ctx, bson.M{"code": bson.M{"$in": codes}},
+		bson.M{"$set": bson.M{"status": status, "updated_at": time.Now().UTC()}})
+	if err != nil {
+		return nil, fmt.Errorf("setting status: %w", err)
+	}
+	cur, err := r.urls.Find(ctx, bson.M{"code": bson.M{"$in": codes}})
+	if err != nil {
+		return nil, fmt.Errorf("fetching updated urls: %w", err)
+	}
+	defer cur.Close(ctx)
+	var out []*models.URL
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, fmt.Errorf("decoding urls: %w", err)
+	}
+	return out, nil
+}
+
+// WarmCache is a no-op at the storage layer; the cache decorator
+// implements it.
+func (r *mongoRepository) WarmCache(context.Context, []*models.URL) error { return nil }
+
+func (r *mongoRepository) IncrementClicks(ctx context.Context, code string) error {
+	defer timeOp("increment_clicks")()
+	_, err := r.urls.UpdateOne(ctx, bson.M{"code": code},
+		bson.M{"$inc": bson.M{"clicks": 1}})
+	if err != nil {
+		return fmt.Errorf("incrementing clicks: %w", err)
+	}
+	return nil
+}
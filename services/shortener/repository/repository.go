@@ -0,0 +1,75 @@
+// Package repository persists shortened URLs. The URLRepository interface is
+// implemented by the MongoDB store and decorated by the Redis read-through
+// cache; the service layer only ever sees the interface.
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+// URLRepository is the storage contract for shortened URLs.
+type URLRepository interface {
+	// StoreURL inserts a new URL record. It returns common.ErrConflict
+	// (wrapped) when the short code is already taken.
+	StoreURL(ctx context.Context, u *models.URL) error
+	// GetURLByCode returns the URL for a short code, or a wrapped
+	// common.ErrNotFound.
+	GetURLByCode(ctx context.Context, code string) (*models.URL, error)
+	// GetURLByExternalID returns the link annotated with the given
+	// source-system identifier.
+	GetURLByExternalID(ctx context.Context, system, id string) (*models.URL, error)
+	// UpdateURL replaces the stored record for u.Code.
+	UpdateURL(ctx context.Context, u *models.URL) error
+	// DeleteURL removes the record for code.
+	DeleteURL(ctx context.Context, code string) error
+	// ListURLsByOwner returns a page of the owner's URLs, newest first.
+	// limit <= 0 means no limit. fields, when non-empty, is applied as a
+	// Mongo projection so sparse-fieldset requests never fetch full
+	// documents; "code" is always included.
+	ListURLsByOwner(ctx context.Context, ownerID string, limit, offset int64, fields []string) ([]*models.URL, error)
+	// CountURLsByOrg returns how many links the org currently holds.
+	CountURLsByOrg(ctx context.Context, orgID string) (int64, error)
+	// CodesExist reports, for each code, whether a link already claims
+	// it. Issued as a single batched query.
+	CodesExist(ctx context.Context, codes []string) (map[string]bool, error)
+	// ListCodesByPrefix returns up to limit existing codes sharing the
+	// prefix; used by the typo-suggestion path.
+	ListCodesByPrefix(ctx context.Context, prefix string, limit int64) ([]string, error)
+	// ListURLsUpdatedSince returns the owner's links touched after the
+	// given instant, oldest change first, for delta sync.
+	ListURLsUpdatedSince(ctx context.Context, ownerID string, since time.Time, limit int64) ([]*models.URL, error)
+	// FindURLs runs a LinkFilter over the owner's links, newest first.
+	FindURLs(ctx context.Context, ownerID string, f models.LinkFilter) ([]*models.URL, error)
+	// ListURLsForHealthCheck returns the links least recently probed by
+	// the rot checker.
+	ListURLsForHealthCheck(ctx context.Context, limit int64) ([]*models.URL, error)
+	// SetDestinationHealth records a rot-check verdict for code.
+	SetDestinationHealth(ctx context.Context, code string, broken bool) error
+	// BulkDeleteURLs removes every link of the owner matching the filter,
+	// returning the deleted codes.
+	BulkDeleteURLs(ctx context.Context, ownerID string, f models.LinkFilter) ([]string, error)
+	// BulkRetagURLs adds and removes tags on every match, returning the
+	// affected codes.
+	BulkRetagURLs(ctx context.Context, ownerID string, f models.LinkFilter, add, remove []string) ([]string, error)
+	// BulkExtendExpiry pushes back the expiry of every match that has
+	// one, returning the affected codes.
+	BulkExtendExpiry(ctx context.Context, ownerID string, f models.LinkFilter, extendBy time.Duration) ([]string, error)
+	// IncrementClicks atomically bumps the click counter for code.
+	IncrementClicks(ctx context.Context, code string) error
+	// WarmCache primes caches for a batch of links in one round trip;
+	// the bulk-create and import paths use it instead of per-link
+	// writes. A no-op on cacheless implementations.
+	WarmCache(ctx context.Context, urls []*models.URL) error
+	// ListURLsByStatus returns up to limit links in the given moderation
+	// status, oldest first so the queue drains fairly. A non-empty orgID
+	// scopes the listing to that org's links.
+	ListURLsByStatus(ctx context.Context, orgID, status string, limit int64) ([]*models.URL, error)
+	// SetStatus updates the moderation status of each code, returning the
+	// affected links.
+	SetStatus(ctx context.Context, codes []string, status string) ([]*models.URL, error)
+	// ListDueDrafts returns drafts whose publish time has passed.
+	ListDueDrafts(ctx context.Context, now time.Time, limit int64) ([]*models.URL, error)
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+)
+
+// Reservation holds an alias for an org: temporarily while a user
+// completes a signup form, or permanently for brand terms. ExpiresAt nil
+// means permanent.
+type Reservation struct {
+	Alias     string     `bson:"alias" json:"alias"`
+	OrgID     string     `bson:"org_id,omitempty" json:"org_id,omitempty"`
+	HeldBy    string     `bson:"held_by" json:"held_by"`
+	CreatedAt time.Time  `bson:"created_at" json:"created_at"`
+	ExpiresAt *time.Time `bson:"expires_at,omitempty" json:"expires_at,omitempty"`
+}
+
+// Active reports whether the reservation still holds.
+func (r *Reservation) Active(now time.Time) bool {
+	return r.ExpiresAt == nil || now.Before(*r.ExpiresAt)
+}
+
+// ReservationRepository stores alias reservations.
+type ReservationRepository struct {
+	reservations *mongo.Collection
+}
+
+// NewReservationRepository returns a repository over db's
+// alias_reservations collection.
+func NewReservationRepository(db *mongo.Database) *ReservationRepository {
+	return &ReservationRepository{reservations: db.Collection("alias_reservations")}
+}
+
+// GetActive returns the live reservation for alias, or a wrapped
+// common.ErrNotFound when none holds. Expired reservations are pruned as
+// a side effect.
+func (r *ReservationRepository) GetActive(ctx context.Context, alias string) (*Reservation, error) {
+	var res Reservation
+	err := r.reservations.FindOne(ctx, bson.M{"alias": alias}).Decode(&res)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("reservation for %q: %w", alias, common.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetching reservation: %w", err)
+	}
+	if !res.Active(time.Now().UTC()) {
+		r.reservations.DeleteOne(ctx, bson.M{"alias": alias})
+		return nil, fmt.Errorf("reservation for %q: %w", alias, common.ErrNotFound)
+	}
+	return &res, nil
+}
+
+// Reserve places a hold on alias. An existing live reservation by another
+// holder is a conflict; re-reserving your own hold refreshes it.
+func (r *ReservationRepository) Reserve(ctx context.Context, res *Reservation) error {
+	existing, err := r.GetActive(ctx, res.Alias)
+	if err == nil && existing.HeldBy != res.HeldBy {
+		return fmt.Errorf("alias %q already reserved: %w", res.Alias, common.ErrConflict)
+	}
+	if err != nil && !common.IsNotFound(err) {
+		return err
+	}
+	res.CreatedAt = time.Now().UTC()
+	_, err = r.reservations.ReplaceOne(ctx, bson.M{"alias": res.Alias}, res,
+		options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("storing reservation: %w", err)
+	}
+	return nil
+}
+
+// Release drops the reservation for alias if held by holder.
+func (r *ReservationRepository) Release(ctx context.Context, alias, holder string) error {
+	res, err := r.reservations.DeleteOne(ctx, bson.M{"alias": alias, "held_by": holder})
+	if err != nil {
+		return fmt.Errorf("releasing reservation: %w", err)
+	}
+	if res.DeletedCount == 0 {
+		return fmt.Errorf("reservation for %q: %w", alias, common.ErrNotFound)
+	}
+	return nil
+}
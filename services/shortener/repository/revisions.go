@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+// Revision is a snapshot of a link as it was before a change.
+type Revision struct {
+	ID        string     `bson:"_id,omitempty" json:"id"`
+	Code      string     `bson:"code" json:"code"`
+	Version   int64      `bson:"version" json:"version"`
+	Snapshot  models.URL `bson:"snapshot" json:"snapshot"`
+	ChangedBy string     `bson:"changed_by" json:"changed_by"`
+	// Change is "update" or "delete".
+	Change    string    `bson:"change" json:"change"`
+	ChangedAt time.Time `bson:"changed_at" json:"changed_at"`
+}
+
+// RevisionRepository stores per-link change history.
+type RevisionRepository struct {
+	revisions *mongo.Collection
+}
+
+// NewRevisionRepository returns a repository over db's url_revisions
+// collection.
+func NewRevisionRepository(db *mongo.Database) *RevisionRepository {
+	return &RevisionRepository{revisions: db.Collection("url_revisions")}
+}
+
+// StoreRevision records the pre-change snapshot of a link.
+func (r *RevisionRepository) StoreRevision(ctx context.Context, snapshot *models.URL, changedBy, change string) error {
+	rev := Revision{
+		Code:      snapshot.Code,
+		Version:   snapshot.Version,
+		Snapshot:  *snapshot,
+		ChangedBy: changedBy,
+		Change:    change,
+		ChangedAt: time.Now().UTC(),
+	}
+	if _, err := r.revisions.InsertOne(ctx, rev); err != nil {
+		return fmt.Errorf("inserting revision: %w", err)
+	}
+	return nil
+}
+
+// ListDeletionsSince returns delete tombstones for the owner's links after
+// the given instant, oldest first; the revision log doubles as the
+// tombstone store for delta sync.
+func (r *RevisionRepository) ListDeletionsSince(ctx context.Context, ownerID string, since time.Time, limit int64) ([]Revision, error) {
+	cur, err := r.revisions.Find(ctx, bson.M{
+		"change":            "delete",
+		"snapshot.owner_id": ownerID,
+		"changed_at":        bson.M{"$gt": since},
+	}, options.Find().SetSort(bson.D{{Key: "changed_at", Value: 1}}).SetLimit(limit))
+	if err != nil {
+		return nil, fmt.Errorf("listing deletions: %w", err)
+	}
+	defer cur.Close(ctx)
+	var out []Revision
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, fmt.Errorf("decoding deletions: %w", err)
+	}
+	return out, nil
+}
+
+// ListRevisions returns a link's history, newest first.
+func (r *RevisionRepository) ListRevisions(ctx context.Context, code string) ([]Revision, error) {
+	cur, err := r.revisions.Find(ctx, bson.M{"code": code},
+		options.Find().SetSort(bson.D{{Key: "changed_at", Value: -1}}))
+	if err != nil {
+		return nil, fmt.Errorf("listing revisions: %w", err)
+	}
+	defer cur.Close(ctx)
+	var out []Revision
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, fmt.Errorf("decoding revisions: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+var shadowComparisons = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "urlminifier",
+	Name:      "shadow_repository_comparisons_total",
+	Help:      "Shadow-read comparisons against the candidate backend by result (match, mismatch, candidate_error).",
+}, []string{"result"})
+
+// shadowRepository dark-launches a candidate backend: every write goes to
+// both the primary and the candidate, and reads are shadowed against the
+// candidate with mismatches logged. The caller only ever sees the
+// primary's results and errors, so a broken candidate cannot affect
+// production behaviour.
+type shadowRepository struct {
+	URLRepository
+	candidate URLRepository
+}
+
+// NewShadowRepository wraps primary with dual writes and shadow reads
+// against candidate.
+func NewShadowRepository(primary, candidate URLRepository) URLRepository {
+	return &shadowRepository{URLRepository: primary, candidate: candidate}
+}
+
+// mirror runs a candidate operation in the background with its own
+// timeout, logging failures.
+func (r *shadowRepository) mirror(op string, fn func(ctx context.Context) error) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := fn(ctx); err != nil {
+			log.Printf("shadow: candidate %s: %v", op, err)
+		}
+	}()
+}
+
+func (r *shadowRepository) StoreURL(ctx context.Context, u *models.URL) error {
+	if err := r.URLRepository.StoreURL(ctx, u); err != nil {
+		return err
+	}
+	copied := *u
+	r.mirror("store", func(ctx context.Context) error {
+		return r.candidate.StoreURL(ctx, &copied)
+	})
+	return nil
+}
+
+func (r *shadowRepository) UpdateURL(ctx context.Context, u *models.URL) error {
+	// Snapshot before the primary write: UpdateURL bumps u.Version, and
+	// the candidate — which saw the same history of mirrored writes —
+	// still stores the pre-update version, so its OCC filter must match
+	// against that, not the already-bumped one.
+	copied := *u
+	if err := r.URLRepository.UpdateURL(ctx, u); err != nil {
+		return err
+	}
+	r.mirror("update", func(ctx context.Context) error {
+		return r.candidate.UpdateURL(ctx, &copied)
+	})
+	return nil
+}
+
+func (r *shadowRepository) DeleteURL(ctx context.Context, code string) error {
+	if err := r.URLRepository.DeleteURL(ctx, code); err != nil {
+		return err
+	}
+	r.mirror("delete", func(ctx context.Context) error {
+		err := r.candidate.DeleteURL(ctx, code)
+		if common.IsNotFound(err) {
+			// The candidate may not have the document yet; not a failure.
+			return nil
+		}
+		return err
+	})
+	return nil
+}
+
+func (r *shadowRepository) GetURLByCode(ctx context.Context, code string) (*models.URL, error) {
+	u, err := r.URLRepository.GetURLByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	expect := *u
+	r.mirror("get", func(ctx context.Context) error {
+		got, err := r.candidate.GetURLByCode(ctx, code)
+		if err != nil {
+			shadowComparisons.WithLabelValues("candidate_error").Inc()
+			return err
+		}
+		if got.Code != expect.Code || got.LongURL != expect.LongURL || got.OwnerID != expect.OwnerID {
+			shadowComparisons.WithLabelValues("mismatch").Inc()
+			log.Printf("shadow: mismatch on %s: candidate long_url=%q owner=%q, primary long_url=%q owner=%q",
+				code, got.LongURL, got.OwnerID, expect.LongURL, expect.OwnerID)
+			return nil
+		}
+		shadowComparisons.WithLabelValues("match").Inc()
+		return nil
+	})
+	return u, nil
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+// WorkspaceRepository stores workspaces and their memberships.
+type WorkspaceRepository struct {
+	workspaces *mongo.Collection
+	urls       *mongo.Collection
+}
+
+// NewWorkspaceRepository returns a repository over db's workspaces and
+// urls collections.
+func NewWorkspaceRepository(db *mongo.Database) *WorkspaceRepository {
+	return &WorkspaceRepository{
+		workspaces: db.Collection("workspaces"),
+		urls:       db.Collection("urls"),
+	}
+}
+
+// StoreWorkspace inserts a new workspace, generating its ID.
+func (r *WorkspaceRepository) StoreWorkspace(ctx context.Context, ws *models.Workspace) error {
+	ws.CreatedAt = time.Now().UTC()
+	if ws.ID == "" {
+		ws.ID = fmt.Sprintf("ws_%d", time.Now().UnixNano())
+	}
+	if _, err := r.workspaces.InsertOne(ctx, ws); err != nil {
+		return fmt.Errorf("inserting workspace: %w", err)
+	}
+	return nil
+}
+
+// GetWorkspace returns the workspace by ID.
+func (r *WorkspaceRepository) GetWorkspace(ctx context.Context, id string) (*models.Workspace, error) {
+	var ws models.Workspace
+	err := r.workspaces.FindOne(ctx, bson.M{"_id": id}).Decode(&ws)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("workspace %q: %w", id, common.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetching workspace: %w", err)
+	}
+	return &ws, nil
+}
+
+// ListWorkspacesForUser returns every workspace the user belongs to.
+func (r *WorkspaceRepository) ListWorkspacesForUser(ctx context.Context, userID string) ([]*models.Workspace, error) {
+	cur, err := r.workspaces.Find(ctx, bson.M{"members.user_id": userID})
+	if err != nil {
+		return nil, fmt.Errorf("listing workspaces: %w", err)
+	}
+	defer cur.Close(ctx)
+	var out []*models.Workspace
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, fmt.Errorf("decoding workspaces: %w", err)
+	}
+	return out, nil
+}
+
+// SetMember adds or updates one membership.
+func (r *WorkspaceRepository) SetMember(ctx context.Context, id string, m models.WorkspaceMember) error {
+	// Remove any existing membership first so role changes don't
+	// duplicate the member.
+	_, err := r.workspaces.UpdateOne(ctx, bson.M{"_id": id},
+		bson.M{"$pull": bson.M{"members": bson.M{"user_id": m.UserID}}})
+	if err != nil {
+		return fmt.Errorf("clearing membership: %w", err)
+	}
+	res, err := r.workspaces.UpdateOne(ctx, bson.M{"_id": id},
+		bson.M{"$push": bson.M{"members": m}})
+	if err != nil {
+		return fmt.Errorf("adding membership: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("workspace %q: %w", id, common.ErrNotFound)
+	}
+	return nil
+}
+
+// RemoveMember deletes one membership.
+func (r *WorkspaceRepository) RemoveMember(ctx context.Context, id, userID string) error {
+	res, err := r.workspaces.UpdateOne(ctx, bson.M{"_id": id},
+		bson.M{"$pull": bson.M{"members": bson.M{"user_id": userID}}})
+	if err != nil {
+		return fmt.Errorf("removing membership: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("workspace %q: %w", id, common.ErrNotFound)
+	}
+	return nil
+}
+
+// ListURLsByWorkspace returns a page of the workspace's links, newest
+// first.
+func (r *WorkspaceRepository) ListURLsByWorkspace(ctx context.Context, id string, limit, offset int64) ([]*models.URL, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+	if offset > 0 {
+		opts.SetSkip(offset)
+	}
+	cur, err := r.urls.Find(ctx, bson.M{"workspace_id": id}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("listing workspace urls: %w", err)
+	}
+	defer cur.Close(ctx)
+	var out []*models.URL
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, fmt.Errorf("decoding urls: %w", err)
+	}
+	return out, nil
+}
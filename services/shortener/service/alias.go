@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ashtonholgate/url-minifier/pkg/auth"
+)
+
+// Reasons an alias can be unavailable.
+const (
+	AliasReasonTaken    = "taken"
+	AliasReasonReserved = "reserved"
+)
+
+// AliasAvailability is the response of the availability endpoint.
+type AliasAvailability struct {
+	Alias     string `json:"alias"`
+	Available bool   `json:"available"`
+	// Reason is set when Available is false: "taken" or "reserved".
+	Reason string `json:"reason,omitempty"`
+	// Suggestions lists nearby free aliases when the requested one is
+	// unavailable.
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// maxAliasSuggestions bounds the suggestion list.
+const maxAliasSuggestions = 5
+
+// CheckAlias reports whether alias is free and, when it is not, suggests
+// nearby free alternatives. All candidates are checked in one batched
+// repository query.
+func (s *Service) CheckAlias(ctx context.Context, pr auth.Principal, alias string) (*AliasAvailability, error) {
+	if err := auth.Authorize(pr, auth.PermURLRead, auth.Resource{}); err != nil {
+		return nil, err
+	}
+	alias = s.canonicalCode(alias)
+	candidates := []string{alias}
+	for _, variant := range aliasVariants(alias) {
+		candidates = append(candidates, variant)
+	}
+	exists, err := s.repo.CodesExist(ctx, candidates)
+	if err != nil {
+		return nil, err
+	}
+	res := &AliasAvailability{Alias: alias, Available: !exists[alias]}
+	if res.Available {
+		if reserved, err := s.aliasReservedForOther(ctx, pr, alias); err != nil {
+			return nil, err
+		} else if reserved {
+			res.Available = false
+			res.Reason = AliasReasonReserved
+		}
+		return res, nil
+	}
+	res.Reason = AliasReasonTaken
+	for _, variant := range candidates[1:] {
+		if !exists[variant] {
+			res.Suggestions = append(res.Suggestions, variant)
+			if len(res.Suggestions) == maxAliasSuggestions {
+				break
+			}
+		}
+	}
+	return res, nil
+}
+
+// aliasVariants derives candidate alternatives for a taken alias: numeric
+// suffixes and common separators.
+func aliasVariants(alias string) []string {
+	variants := make([]string, 0, 10)
+	for i := 1; i <= 5; i++ {
+		variants = append(variants, fmt.Sprintf("%s%d", alias, i))
+	}
+	variants = append(variants,
+		alias+"-1",
+		alias+"-link",
+		"the-"+alias,
+		alias+"-official",
+	)
+	return variants
+}
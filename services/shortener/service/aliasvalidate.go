@@ -0,0 +1,77 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+)
+
+// asciiAliasPattern is the strict default alphabet for custom aliases.
+var asciiAliasPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// EnableUnicodeAliases relaxes alias validation to accept Unicode and emoji
+// aliases (NFC-normalised, confusable-checked). Off by default because the
+// wider alphabet is a phishing surface.
+func (s *Service) EnableUnicodeAliases() { s.unicodeAliases = true }
+
+// ValidateCustomAlias checks alias against the configured alphabet and
+// returns the canonical form to store. ASCII aliases pass through; Unicode
+// aliases are NFC-normalised so visually identical inputs map to one code
+// point sequence, and mixed-script lookalikes are rejected.
+func (s *Service) ValidateCustomAlias(alias string) (string, error) {
+	if asciiAliasPattern.MatchString(alias) {
+		return alias, nil
+	}
+	if !s.unicodeAliases {
+		return "", fmt.Errorf("alias must match %s: %w", asciiAliasPattern, common.ErrInvalidInput)
+	}
+	normalised := norm.NFC.String(alias)
+	runes := []rune(normalised)
+	if len(runes) == 0 || len(runes) > 64 {
+		return "", fmt.Errorf("alias must be 1-64 characters: %w", common.ErrInvalidInput)
+	}
+	for _, r := range runes {
+		if unicode.IsSpace(r) || unicode.IsControl(r) || r == '/' || r == '?' || r == '#' || r == '%' {
+			return "", fmt.Errorf("alias contains forbidden character %q: %w", r, common.ErrInvalidInput)
+		}
+	}
+	if mixedConfusableScripts(runes) {
+		return "", fmt.Errorf("alias mixes confusable scripts: %w", common.ErrInvalidInput)
+	}
+	return normalised, nil
+}
+
+// confusableScripts are alphabets with visually interchangeable letters; an
+// alias drawing letters from more than one of them is a homograph risk
+// (e.g. Latin "a" next to Cyrillic "а").
+var confusableScripts = []*unicode.RangeTable{
+	unicode.Latin,
+	unicode.Cyrillic,
+	unicode.Greek,
+}
+
+// mixedConfusableScripts reports whether the runes draw letters from more
+// than one confusable script. Digits, emoji and other symbols are ignored —
+// they are not confusable across these alphabets.
+func mixedConfusableScripts(runes []rune) bool {
+	seen := -1
+	for _, r := range runes {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		for i, script := range confusableScripts {
+			if unicode.Is(script, r) {
+				if seen >= 0 && seen != i {
+					return true
+				}
+				seen = i
+				break
+			}
+		}
+	}
+	return false
+}
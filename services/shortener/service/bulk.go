@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ashtonholgate/url-minifier/pkg/auth"
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+// Bulk operation actions.
+const (
+	BulkActionDelete       = "delete"
+	BulkActionRetag        = "retag"
+	BulkActionExtendExpiry = "extend_expiry"
+)
+
+// BulkRequest describes one bulk operation over the caller's links.
+type BulkRequest struct {
+	Action string            `json:"action"`
+	Filter models.LinkFilter `json:"filter"`
+	// Retag parameters.
+	AddTags    []string `json:"add_tags,omitempty"`
+	RemoveTags []string `json:"remove_tags,omitempty"`
+	// ExtendExpiry parameter.
+	ExtendByHours int `json:"extend_by_hours,omitempty"`
+}
+
+// BulkResult reports which links a bulk operation touched.
+type BulkResult struct {
+	Action   string   `json:"action"`
+	Affected int      `json:"affected"`
+	Codes    []string `json:"codes"`
+}
+
+// BulkOperation applies req to every link of the principal matching the
+// filter. Operations are scoped to the caller's own links; deleting
+// requires the delete permission, the rest the update permission.
+func (s *Service) BulkOperation(ctx context.Context, pr auth.Principal, req BulkRequest) (*BulkResult, error) {
+	perm := auth.PermURLUpdate
+	if req.Action == BulkActionDelete {
+		perm = auth.PermURLDelete
+	}
+	if err := auth.Authorize(pr, perm, auth.Resource{OwnerID: pr.UserID}); err != nil {
+		return nil, err
+	}
+	var (
+		codes []string
+		err   error
+	)
+	switch req.Action {
+	case BulkActionDelete:
+		codes, err = s.repo.BulkDeleteURLs(ctx, pr.UserID, req.Filter)
+	case BulkActionRetag:
+		if len(req.AddTags) == 0 && len(req.RemoveTags) == 0 {
+			return nil, fmt.Errorf("retag needs add_tags or remove_tags: %w", common.ErrInvalidInput)
+		}
+		codes, err = s.repo.BulkRetagURLs(ctx, pr.UserID, req.Filter, req.AddTags, req.RemoveTags)
+	case BulkActionExtendExpiry:
+		if req.ExtendByHours <= 0 {
+			return nil, fmt.Errorf("extend_by_hours must be positive: %w", common.ErrInvalidInput)
+		}
+		codes, err = s.repo.BulkExtendExpiry(ctx, pr.UserID, req.Filter,
+			time.Duration(req.ExtendByHours)*time.Hour)
+	default:
+		return nil, fmt.Errorf("unknown bulk action %q: %w", req.Action, common.ErrInvalidInput)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.purgeCodes(codes...)
+	return &BulkResult{Action: req.Action, Affected: len(codes), Codes: codes}, nil
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/ashtonholgate/url-minifier/pkg/auth"
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+	"github.com/ashtonholgate/url-minifier/services/shortener/repository"
+)
+
+// SetBundleRepository attaches bundle storage to the service. Bundles are
+// optional; services that do not serve landing pages skip this.
+func (s *Service) SetBundleRepository(bundles *repository.BundleRepository) {
+	s.bundles = bundles
+}
+
+// BundleRequest carries caller-supplied bundle fields for create and
+// update. Items replace the stored slice wholesale, which is also how
+// reordering works.
+type BundleRequest struct {
+	Title       string              `json:"title"`
+	Description string              `json:"description,omitempty"`
+	Alias       string              `json:"alias,omitempty"`
+	Items       []models.BundleItem `json:"items"`
+}
+
+func (req *BundleRequest) validate() error {
+	if req.Title == "" {
+		return fmt.Errorf("title is required: %w", common.ErrInvalidInput)
+	}
+	if len(req.Items) == 0 {
+		return fmt.Errorf("a bundle needs at least one item: %w", common.ErrInvalidInput)
+	}
+	for i, item := range req.Items {
+		parsed, err := url.Parse(item.LongURL)
+		if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			return fmt.Errorf("item %d: long_url must be an absolute http(s) URL: %w", i, common.ErrInvalidInput)
+		}
+	}
+	return nil
+}
+
+// CreateBundle creates a bundle under a custom alias or a generated code.
+// The code must be free in both the urls and bundles keyspaces.
+func (s *Service) CreateBundle(ctx context.Context, pr auth.Principal, req BundleRequest) (*models.Bundle, error) {
+	if err := auth.Authorize(pr, auth.PermURLCreate, auth.Resource{}); err != nil {
+		return nil, err
+	}
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+	for i := range req.Items {
+		req.Items[i].Clicks = 0
+	}
+	now := time.Now().UTC()
+	b := &models.Bundle{
+		Title:       req.Title,
+		Description: req.Description,
+		OwnerID:     pr.UserID,
+		OrgID:       pr.OrgID,
+		Items:       req.Items,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	claim := func(code string) error {
+		if _, err := s.repo.GetURLByCode(ctx, code); err == nil {
+			return fmt.Errorf("code %q: %w", code, common.ErrConflict)
+		} else if !common.IsNotFound(err) {
+			return err
+		}
+		b.Code = code
+		return s.bundles.StoreBundle(ctx, b)
+	}
+	if req.Alias != "" {
+		if err := claim(req.Alias); err != nil {
+			return nil, err
+		}
+		return b, nil
+	}
+	for attempt := 0; attempt < maxCreateAttempts; attempt++ {
+		code, err := s.gen.Generate(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("generating code: %w", err)
+		}
+		err = claim(code)
+		if err == nil {
+			return b, nil
+		}
+		if !errors.Is(err, common.ErrConflict) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("exhausted %d attempts to find a free code: %w", maxCreateAttempts, common.ErrConflict)
+}
+
+// GetBundle returns the bundle for code if the principal may read it.
+func (s *Service) GetBundle(ctx context.Context, pr auth.Principal, code string) (*models.Bundle, error) {
+	b, err := s.bundles.GetBundleByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(pr, auth.PermURLRead, auth.Resource{OwnerID: b.OwnerID, OrgID: b.OrgID}); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// UpdateBundle replaces the bundle's content with req.
+func (s *Service) UpdateBundle(ctx context.Context, pr auth.Principal, code string, req BundleRequest) (*models.Bundle, error) {
+	b, err := s.bundles.GetBundleByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(pr, auth.PermURLUpdate, auth.Resource{OwnerID: b.OwnerID, OrgID: b.OrgID}); err != nil {
+		return nil, err
+	}
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+	// Carry click counts over for items whose destination is unchanged so
+	// a reorder doesn't zero the stats.
+	counts := make(map[string]int64, len(b.Items))
+	for _, item := range b.Items {
+		counts[item.LongURL] = item.Clicks
+	}
+	for i := range req.Items {
+		req.Items[i].Clicks = counts[req.Items[i].LongURL]
+	}
+	b.Title = req.Title
+	b.Description = req.Description
+	b.Items = req.Items
+	if err := s.bundles.UpdateBundle(ctx, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// DeleteBundle removes the bundle for code.
+func (s *Service) DeleteBundle(ctx context.Context, pr auth.Principal, code string) error {
+	b, err := s.bundles.GetBundleByCode(ctx, code)
+	if err != nil {
+		return err
+	}
+	if err := auth.Authorize(pr, auth.PermURLDelete, auth.Resource{OwnerID: b.OwnerID, OrgID: b.OrgID}); err != nil {
+		return err
+	}
+	return s.bundles.DeleteBundle(ctx, code)
+}
+
+// ListBundles returns the principal's own bundles.
+func (s *Service) ListBundles(ctx context.Context, pr auth.Principal) ([]*models.Bundle, error) {
+	if err := auth.Authorize(pr, auth.PermURLRead, auth.Resource{OwnerID: pr.UserID}); err != nil {
+		return nil, err
+	}
+	return s.bundles.ListBundlesByOwner(ctx, pr.UserID)
+}
+
+// ResolveBundle returns the bundle for public landing-page rendering.
+func (s *Service) ResolveBundle(ctx context.Context, code string) (*models.Bundle, error) {
+	return s.bundles.GetBundleByCode(ctx, code)
+}
+
+// ResolveBundleItem returns the destination of one bundle item and records
+// the click.
+func (s *Service) ResolveBundleItem(ctx context.Context, code string, index int) (string, error) {
+	b, err := s.bundles.GetBundleByCode(ctx, code)
+	if err != nil {
+		return "", err
+	}
+	if index < 0 || index >= len(b.Items) {
+		return "", fmt.Errorf("bundle %q has no item %d: %w", code, index, common.ErrNotFound)
+	}
+	if err := s.bundles.IncrementItemClicks(ctx, code, index); err != nil {
+		// Losing a click is preferable to failing the redirect.
+		return b.Items[index].LongURL, nil
+	}
+	return b.Items[index].LongURL, nil
+}
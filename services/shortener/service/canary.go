@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/ashtonholgate/url-minifier/pkg/feature"
+)
+
+// FlagCodegenCanary is the Unleash flag controlling what share of
+// creations use the canary code-generation strategy.
+const FlagCodegenCanary = "codegen-canary"
+
+var (
+	codegenLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "urlminifier",
+		Name:      "codegen_duration_seconds",
+		Help:      "Code generation latency by strategy.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"strategy"})
+	codegenCollisions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "urlminifier",
+		Name:      "codegen_collisions_total",
+		Help:      "Code collisions on insert by generating strategy.",
+	}, []string{"strategy"})
+)
+
+// canaryGenerator routes each generation to the stable or canary strategy
+// according to the feature flag, observing latency per strategy and
+// remembering which strategy issued recent codes so insert collisions can
+// be attributed.
+type canaryGenerator struct {
+	stable, canary CodeGenerator
+	flags          feature.Flags
+
+	mu     sync.Mutex
+	issued map[string]string // code -> strategy, bounded
+}
+
+// issuedCap bounds the attribution map; collisions are attributed within
+// the creation retry loop, so entries only need to survive moments.
+const issuedCap = 4096
+
+// NewCanaryGenerator wraps stable and canary behind the rollout flag.
+func NewCanaryGenerator(stable, canary CodeGenerator, flags feature.Flags) CodeGenerator {
+	return &canaryGenerator{
+		stable: stable, canary: canary, flags: flags,
+		issued: make(map[string]string, issuedCap),
+	}
+}
+
+func (g *canaryGenerator) Generate(ctx context.Context) (string, error) {
+	strategy, gen := "stable", g.stable
+	if g.flags.IsEnabled(FlagCodegenCanary) {
+		strategy, gen = "canary", g.canary
+	}
+	start := time.Now()
+	code, err := gen.Generate(ctx)
+	codegenLatency.WithLabelValues(strategy).Observe(time.Since(start).Seconds())
+	if err != nil {
+		return "", err
+	}
+	g.mu.Lock()
+	if len(g.issued) >= issuedCap {
+		// Wholesale reset beats per-entry eviction at this size.
+		g.issued = make(map[string]string, issuedCap)
+	}
+	g.issued[code] = strategy
+	g.mu.Unlock()
+	return code, nil
+}
+
+// ObserveCollision attributes an insert collision to the strategy that
+// generated the code. Called by CreateURL's retry loop.
+func (g *canaryGenerator) ObserveCollision(code string) {
+	g.mu.Lock()
+	strategy, ok := g.issued[code]
+	g.mu.Unlock()
+	if !ok {
+		strategy = "unknown"
+	}
+	codegenCollisions.WithLabelValues(strategy).Inc()
+}
+
+// collisionObserver is implemented by generators that want collision
+// feedback.
+type collisionObserver interface {
+	ObserveCollision(code string)
+}
@@ -0,0 +1,20 @@
+package service
+
+import "strings"
+
+// EnableCaseInsensitiveCodes canonicalises short codes to lower case at
+// creation and folds incoming codes on every lookup, so codes dictated over
+// the phone or printed in ambiguous fonts resolve regardless of typed case.
+// Must be enabled from day one of a deployment: links created before the
+// flag keep their mixed-case codes and stop resolving case-folded.
+func (s *Service) EnableCaseInsensitiveCodes() { s.caseInsensitive = true }
+
+// canonicalCode folds code according to the deployment's case mode. Every
+// service method canonicalises caller-supplied codes through this before
+// touching the repository.
+func (s *Service) canonicalCode(code string) string {
+	if s.caseInsensitive {
+		return strings.ToLower(code)
+	}
+	return code
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+	"github.com/ashtonholgate/url-minifier/pkg/notify"
+)
+
+// CertProvisioner is the hook invoked when a domain passes DNS
+// verification; implementations request a certificate (ACME, cloud LB)
+// and return once it is ready to serve.
+type CertProvisioner interface {
+	Provision(ctx context.Context, domain string) error
+}
+
+// NoopCertProvisioner is used where TLS is terminated elsewhere (e.g. a
+// wildcard-capable load balancer).
+type NoopCertProvisioner struct{}
+
+// Provision implements CertProvisioner.
+func (NoopCertProvisioner) Provision(context.Context, string) error { return nil }
+
+// pollerBatch bounds one polling sweep per status.
+const pollerBatch = 50
+
+// StartDNSPoller automates domain verification: it re-checks pending
+// domains' DNS every interval, transitions them pending → verified →
+// active (the latter after certificate provisioning), and notifies the
+// owning org's admin channel on each transition.
+func (v *DomainVerifier) StartDNSPoller(ctx context.Context, interval time.Duration, certs CertProvisioner, notifier notify.Notifier) {
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	if certs == nil {
+		certs = NoopCertProvisioner{}
+	}
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				v.pollPending(ctx, notifier)
+				v.provisionVerified(ctx, certs, notifier)
+			}
+		}
+	}()
+}
+
+func (v *DomainVerifier) pollPending(ctx context.Context, notifier notify.Notifier) {
+	pending, err := v.domains.ListDomainsByStatus(ctx, models.DomainStatusPending, pollerBatch)
+	if err != nil {
+		log.Printf("dns poller: listing pending domains: %v", err)
+		return
+	}
+	for _, d := range pending {
+		if !v.checkTXT(ctx, d) && !v.checkWellKnown(ctx, d) {
+			continue
+		}
+		if err := v.domains.SetStatus(ctx, d.Domain, models.DomainStatusVerified); err != nil {
+			log.Printf("dns poller: verifying %s: %v", d.Domain, err)
+			continue
+		}
+		notifier.Notify(ctx, d.OrgID, "domain verified",
+			fmt.Sprintf("%s passed DNS verification; certificate provisioning starts next", d.Domain))
+	}
+}
+
+func (v *DomainVerifier) provisionVerified(ctx context.Context, certs CertProvisioner, notifier notify.Notifier) {
+	verified, err := v.domains.ListDomainsByStatus(ctx, models.DomainStatusVerified, pollerBatch)
+	if err != nil {
+		log.Printf("dns poller: listing verified domains: %v", err)
+		return
+	}
+	for _, d := range verified {
+		if err := certs.Provision(ctx, d.Domain); err != nil {
+			log.Printf("dns poller: provisioning certificate for %s: %v", d.Domain, err)
+			continue
+		}
+		if err := v.domains.SetStatus(ctx, d.Domain, models.DomainStatusActive); err != nil {
+			log.Printf("dns poller: activating %s: %v", d.Domain, err)
+			continue
+		}
+		notifier.Notify(ctx, d.OrgID, "domain active",
+			fmt.Sprintf("%s is verified and serving with a certificate", d.Domain))
+	}
+}
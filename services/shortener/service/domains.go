@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ashtonholgate/url-minifier/pkg/auth"
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+	"github.com/ashtonholgate/url-minifier/pkg/plans"
+	"github.com/ashtonholgate/url-minifier/services/shortener/repository"
+)
+
+// verifyTXTPrefix namespaces our challenge in the domain's TXT records.
+const verifyTXTPrefix = "url-minifier-verify="
+
+// wellKnownVerifyPath is where file-based verification looks for the token.
+const wellKnownVerifyPath = "/.well-known/url-minifier-verify.txt"
+
+// DomainVerifier runs the destination-domain ownership workflow. Verified
+// domains earn the "verified" badge on every link pointing at them.
+type DomainVerifier struct {
+	domains  *repository.DomainRepository
+	ent      plans.Entitlements
+	resolver *net.Resolver
+	client   *http.Client
+	svc      *Service
+}
+
+// BindService gives the verifier access to cross-cutting service checks
+// (currently homograph protection on registration).
+func (v *DomainVerifier) BindService(svc *Service) { v.svc = svc }
+
+// NewDomainVerifier returns a verifier using the system resolver and a
+// short-timeout HTTP client.
+func NewDomainVerifier(domains *repository.DomainRepository, ent plans.Entitlements) *DomainVerifier {
+	return &DomainVerifier{
+		domains:  domains,
+		ent:      ent,
+		resolver: net.DefaultResolver,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// RegisterDomain starts verification for a destination domain, returning the
+// registration with the challenge token the owner must publish.
+func (v *DomainVerifier) RegisterDomain(ctx context.Context, pr auth.Principal, name string) (*models.Domain, error) {
+	if err := auth.Authorize(pr, auth.PermAdmin, auth.Resource{OrgID: pr.OrgID}); err != nil {
+		return nil, err
+	}
+	if err := v.ent.Check(ctx, pr.OrgID, plans.FeatureCustomDomains); err != nil {
+		return nil, err
+	}
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || strings.ContainsAny(name, "/: ") {
+		return nil, fmt.Errorf("malformed domain %q: %w", name, common.ErrInvalidInput)
+	}
+	// The registrable label is what shows in the address bar; protect it
+	// against brand-term homographs.
+	if v.svc != nil {
+		if err := v.svc.checkHomograph(ctx, pr, strings.SplitN(name, ".", 2)[0]); err != nil {
+			return nil, err
+		}
+	}
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("generating verify token: %w", err)
+	}
+	d := &models.Domain{
+		Domain:      name,
+		OrgID:       pr.OrgID,
+		VerifyToken: hex.EncodeToString(buf),
+		Status:      models.DomainStatusPending,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := v.domains.StoreDomain(ctx, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// VerifyDomain checks the DNS TXT record and, failing that, the well-known
+// file for the domain's challenge token, marking the domain verified on
+// success.
+func (v *DomainVerifier) VerifyDomain(ctx context.Context, pr auth.Principal, name string) (*models.Domain, error) {
+	d, err := v.domains.GetDomain(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(pr, auth.PermAdmin, auth.Resource{OrgID: d.OrgID}); err != nil {
+		return nil, err
+	}
+	if d.Verified {
+		return d, nil
+	}
+	if !v.checkTXT(ctx, d) && !v.checkWellKnown(ctx, d) {
+		return nil, fmt.Errorf("challenge token not found for %s: %w", name, common.ErrInvalidInput)
+	}
+	if err := v.domains.MarkVerified(ctx, name); err != nil {
+		return nil, err
+	}
+	d.Verified = true
+	return d, nil
+}
+
+func (v *DomainVerifier) checkTXT(ctx context.Context, d *models.Domain) bool {
+	records, err := v.resolver.LookupTXT(ctx, d.Domain)
+	if err != nil {
+		return false
+	}
+	for _, rec := range records {
+		if strings.TrimPrefix(rec, verifyTXTPrefix) == d.VerifyToken && rec != d.VerifyToken {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *DomainVerifier) checkWellKnown(ctx context.Context, d *models.Domain) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		"https://"+d.Domain+wellKnownVerifyPath, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(body)) == d.VerifyToken
+}
+
+// checkReservedPrefix rejects aliases that collide with the domain's
+// reserved path prefixes.
+func (s *Service) checkReservedPrefix(ctx context.Context, domain, alias string) error {
+	if domain == "" || s.domains == nil {
+		return nil
+	}
+	d, err := s.domains.GetDomain(ctx, domain)
+	if err != nil {
+		if common.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	for _, prefix := range d.Settings.ReservedPrefixes {
+		if alias == prefix || strings.HasPrefix(alias, prefix+"/") {
+			return fmt.Errorf("alias %q collides with reserved prefix %q on %s: %w",
+				alias, prefix, domain, common.ErrConflict)
+		}
+	}
+	return nil
+}
+
+// destinationVerified reports whether longURL points at a verified domain.
+func destinationVerified(ctx context.Context, domains *repository.DomainRepository, longURL string) bool {
+	parsed, err := url.Parse(longURL)
+	if err != nil {
+		return false
+	}
+	ok, err := domains.IsVerified(ctx, strings.ToLower(parsed.Hostname()))
+	return err == nil && ok
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+func validateExperiment(exp *models.ExperimentOptions) error {
+	if exp.Name == "" {
+		return fmt.Errorf("experiment name is required: %w", common.ErrInvalidInput)
+	}
+	if len(exp.Variants) < 2 {
+		return fmt.Errorf("experiment needs at least two variants: %w", common.ErrInvalidInput)
+	}
+	total := 0
+	for i, v := range exp.Variants {
+		if v.Name == "" {
+			return fmt.Errorf("variant %d needs a name: %w", i, common.ErrInvalidInput)
+		}
+		parsed, err := url.Parse(v.URL)
+		if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			return fmt.Errorf("variant %q must be an absolute http(s) URL: %w", v.Name, common.ErrInvalidInput)
+		}
+		if v.Weight <= 0 {
+			return fmt.Errorf("variant %q needs a positive weight: %w", v.Name, common.ErrInvalidInput)
+		}
+		total += v.Weight
+	}
+	if total == 0 {
+		return fmt.Errorf("experiment weights sum to zero: %w", common.ErrInvalidInput)
+	}
+	return nil
+}
+
+// AssignVariant deterministically picks the variant for a visitor
+// fingerprint: the same visitor always lands in the same arm, with arms
+// filled proportionally to their weights. Returns the variant name and
+// destination.
+func AssignVariant(exp *models.ExperimentOptions, code, fingerprint string) (string, string) {
+	total := 0
+	for _, v := range exp.Variants {
+		total += v.Weight
+	}
+	sum := sha256.Sum256([]byte(exp.Name + "\x00" + code + "\x00" + fingerprint))
+	bucket := int(binary.BigEndian.Uint32(sum[:4]) % uint32(total))
+	for _, v := range exp.Variants {
+		if bucket < v.Weight {
+			return v.Name, v.URL
+		}
+		bucket -= v.Weight
+	}
+	last := exp.Variants[len(exp.Variants)-1]
+	return last.Name, last.URL
+}
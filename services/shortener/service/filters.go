@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ashtonholgate/url-minifier/pkg/auth"
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+	"github.com/ashtonholgate/url-minifier/services/shortener/repository"
+)
+
+// SetSavedFilterRepository attaches saved-filter storage to the service.
+func (s *Service) SetSavedFilterRepository(filters *repository.SavedFilterRepository) {
+	s.filters = filters
+}
+
+// FindURLs runs an ad-hoc filter over the principal's own links.
+func (s *Service) FindURLs(ctx context.Context, pr auth.Principal, f models.LinkFilter) ([]*models.URL, error) {
+	if err := auth.Authorize(pr, auth.PermURLRead, auth.Resource{OwnerID: pr.UserID}); err != nil {
+		return nil, err
+	}
+	return s.repo.FindURLs(ctx, pr.UserID, f)
+}
+
+// SaveFilter stores a named filter for the principal.
+func (s *Service) SaveFilter(ctx context.Context, pr auth.Principal, name string, f models.LinkFilter) (*models.SavedFilter, error) {
+	if err := auth.Authorize(pr, auth.PermURLRead, auth.Resource{OwnerID: pr.UserID}); err != nil {
+		return nil, err
+	}
+	if name == "" {
+		return nil, fmt.Errorf("filter name is required: %w", common.ErrInvalidInput)
+	}
+	saved := &models.SavedFilter{OwnerID: pr.UserID, Name: name, Filter: f}
+	if err := s.filters.StoreFilter(ctx, saved); err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+// ListFilters returns the principal's saved filters.
+func (s *Service) ListFilters(ctx context.Context, pr auth.Principal) ([]*models.SavedFilter, error) {
+	if err := auth.Authorize(pr, auth.PermURLRead, auth.Resource{OwnerID: pr.UserID}); err != nil {
+		return nil, err
+	}
+	return s.filters.ListFilters(ctx, pr.UserID)
+}
+
+// DeleteFilter removes one of the principal's saved filters.
+func (s *Service) DeleteFilter(ctx context.Context, pr auth.Principal, name string) error {
+	if err := auth.Authorize(pr, auth.PermURLRead, auth.Resource{OwnerID: pr.UserID}); err != nil {
+		return err
+	}
+	return s.filters.DeleteFilter(ctx, pr.UserID, name)
+}
+
+// RunFilter executes one of the principal's saved filters by name.
+func (s *Service) RunFilter(ctx context.Context, pr auth.Principal, name string) ([]*models.URL, error) {
+	if err := auth.Authorize(pr, auth.PermURLRead, auth.Resource{OwnerID: pr.UserID}); err != nil {
+		return nil, err
+	}
+	saved, err := s.filters.GetFilter(ctx, pr.UserID, name)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.FindURLs(ctx, pr.UserID, saved.Filter)
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+)
+
+// CodeGenerator produces candidate short codes. Implementations must be safe
+// for concurrent use; the service retries on collision, so generators need
+// not guarantee uniqueness.
+type CodeGenerator interface {
+	Generate(ctx context.Context) (string, error)
+}
+
+const base62Alphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// defaultCodeLength yields 62^7 ≈ 3.5e12 codes, plenty of headroom before
+// collisions become frequent.
+const defaultCodeLength = 7
+
+// randomGenerator draws uniformly random base62 codes.
+type randomGenerator struct {
+	length int
+}
+
+// NewRandomGenerator returns the default CodeGenerator: uniformly random
+// base62 codes of the given length (defaultCodeLength when length <= 0).
+func NewRandomGenerator(length int) CodeGenerator {
+	if length <= 0 {
+		length = defaultCodeLength
+	}
+	return &randomGenerator{length: length}
+}
+
+func (g *randomGenerator) Generate(_ context.Context) (string, error) {
+	buf := make([]byte, g.length)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("reading random bytes: %w", err)
+	}
+	for i, b := range buf {
+		buf[i] = base62Alphabet[int(b)%len(base62Alphabet)]
+	}
+	return string(buf), nil
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ashtonholgate/url-minifier/pkg/auth"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+// LinkHealth is the aggregated dashboard payload for proactive cleanup:
+// each section carries a count and the first page of offenders.
+type LinkHealth struct {
+	Broken       healthSection `json:"broken"`
+	Flagged      healthSection `json:"flagged"`
+	ExpiringSoon healthSection `json:"expiring_soon"`
+	Paused       healthSection `json:"paused"`
+}
+
+type healthSection struct {
+	Count int           `json:"count"`
+	Links []*models.URL `json:"links"`
+}
+
+// healthPageSize bounds each section's link list.
+const healthPageSize = 25
+
+// expiringSoonWindow is how far ahead "expiring soon" looks.
+const expiringSoonWindow = 7 * 24 * time.Hour
+
+// LinkHealthReport aggregates the principal's broken-destination, flagged,
+// soon-to-expire and paused (pending moderation) links in one response.
+func (s *Service) LinkHealthReport(ctx context.Context, pr auth.Principal) (*LinkHealth, error) {
+	if err := auth.Authorize(pr, auth.PermURLRead, auth.Resource{OwnerID: pr.UserID}); err != nil {
+		return nil, err
+	}
+	section := func(f models.LinkFilter) (healthSection, error) {
+		urls, err := s.repo.FindURLs(ctx, pr.UserID, f)
+		if err != nil {
+			return healthSection{}, err
+		}
+		sec := healthSection{Count: len(urls), Links: urls}
+		if len(sec.Links) > healthPageSize {
+			sec.Links = sec.Links[:healthPageSize]
+		}
+		return sec, nil
+	}
+	report := &LinkHealth{}
+	broken := true
+	var err error
+	if report.Broken, err = section(models.LinkFilter{DestinationBroken: &broken}); err != nil {
+		return nil, err
+	}
+	if report.Flagged, err = section(models.LinkFilter{Status: models.URLStatusRejected}); err != nil {
+		return nil, err
+	}
+	soon := time.Now().UTC().Add(expiringSoonWindow)
+	if report.ExpiringSoon, err = section(models.LinkFilter{ExpiringBefore: &soon}); err != nil {
+		return nil, err
+	}
+	if report.Paused, err = section(models.LinkFilter{Status: models.URLStatusPending}); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// StartRotChecker probes a batch of the least recently checked links every
+// interval, marking destinations that no longer respond so the health
+// report can surface them.
+func (s *Service) StartRotChecker(ctx context.Context, interval time.Duration, batch int64) {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	// Destinations are user-controlled; probe them through the guarded
+	// client so the rot checker can't be pointed at internal services.
+	client := ssrfSafeFollowClient
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.checkDestinations(ctx, client, batch)
+			}
+		}
+	}()
+}
+
+func (s *Service) checkDestinations(ctx context.Context, client *http.Client, batch int64) {
+	urls, err := s.repo.ListURLsForHealthCheck(ctx, batch)
+	if err != nil {
+		return
+	}
+	for _, u := range urls {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, u.LongURL, nil)
+		if err != nil {
+			continue
+		}
+		resp, err := client.Do(req)
+		broken := err != nil
+		if resp != nil {
+			broken = resp.StatusCode >= 400
+			resp.Body.Close()
+		}
+		s.repo.SetDestinationHealth(ctx, u.Code, broken)
+	}
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/ashtonholgate/url-minifier/pkg/auth"
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/services/shortener/repository"
+)
+
+// confusableMap folds the lookalikes that matter for short aliases and
+// domains onto their Latin skeleton, in the spirit of the UTS #39
+// confusable skeleton (the full data table is overkill for our alphabet).
+var confusableMap = map[rune]rune{
+	// Cyrillic lookalikes.
+	'а': 'a', 'е': 'e', 'о': 'o', 'р': 'p', 'с': 'c', 'х': 'x', 'у': 'y',
+	'і': 'i', 'ѕ': 's', 'ԁ': 'd', 'ɡ': 'g',
+	// Greek lookalikes.
+	'α': 'a', 'ο': 'o', 'ν': 'v', 'ρ': 'p', 'τ': 't', 'υ': 'u',
+	// Digit and symbol lookalikes.
+	'0': 'o', '1': 'l', '3': 'e', '5': 's', '7': 't', '$': 's', '@': 'a',
+	'|': 'l',
+}
+
+// Skeleton reduces s to its confusable skeleton: lowercase, diacritics
+// stripped via NFD, lookalike characters folded to their Latin base.
+// Strings with equal skeletons are considered visually confusable.
+func Skeleton(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(s)) {
+		if unicode.Is(unicode.Mn, r) {
+			continue // combining marks are invisible at glance distance
+		}
+		if folded, ok := confusableMap[r]; ok {
+			r = folded
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// SetBrandTermRepository attaches protected-term storage.
+func (s *Service) SetBrandTermRepository(terms *repository.BrandTermRepository) {
+	s.brandTerms = terms
+}
+
+// RegisterBrandTerm protects a term for the principal's org.
+func (s *Service) RegisterBrandTerm(ctx context.Context, pr auth.Principal, term string) (*repository.BrandTerm, error) {
+	if err := auth.Authorize(pr, auth.PermAdmin, auth.Resource{OrgID: pr.OrgID}); err != nil {
+		return nil, err
+	}
+	term = strings.TrimSpace(term)
+	if term == "" {
+		return nil, fmt.Errorf("term is required: %w", common.ErrInvalidInput)
+	}
+	t := &repository.BrandTerm{Term: term, Skeleton: Skeleton(term), OrgID: pr.OrgID}
+	if err := s.brandTerms.StoreBrandTerm(ctx, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// checkHomograph rejects a candidate alias or domain label whose skeleton
+// collides with a brand term protected by a different org. The owning org
+// itself may use its terms freely.
+func (s *Service) checkHomograph(ctx context.Context, pr auth.Principal, candidate string) error {
+	if s.brandTerms == nil {
+		return nil
+	}
+	matches, err := s.brandTerms.FindBySkeleton(ctx, Skeleton(candidate))
+	if err != nil {
+		return err
+	}
+	for _, t := range matches {
+		if t.OrgID != pr.OrgID {
+			return fmt.Errorf("%q is confusable with a protected brand term: %w",
+				candidate, common.ErrConflict)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ashtonholgate/url-minifier/pkg/auth"
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+	"github.com/ashtonholgate/url-minifier/services/shortener/repository"
+)
+
+// inviteTTL is how long an invite stays acceptable.
+const inviteTTL = 7 * 24 * time.Hour
+
+// SetInviteRepository attaches invite storage to the service.
+func (s *Service) SetInviteRepository(invites *repository.InviteRepository) {
+	s.invites = invites
+}
+
+// CreateInvite offers membership to an email address, scoped to a
+// workspace (any workspace owner) or the whole org (org admins). The token
+// goes out through the notifier; the API response carries it too for
+// resending.
+func (s *Service) CreateInvite(ctx context.Context, pr auth.Principal, email, workspaceID, role string) (*repository.Invite, error) {
+	if email == "" {
+		return nil, fmt.Errorf("email is required: %w", common.ErrInvalidInput)
+	}
+	if !auth.Role(role).Valid() {
+		return nil, fmt.Errorf("unknown role %q: %w", role, common.ErrInvalidInput)
+	}
+	if workspaceID != "" {
+		if _, err := s.workspaceAccess(ctx, pr, workspaceID, auth.PermURLDelete); err != nil {
+			return nil, err
+		}
+	} else if err := auth.Authorize(pr, auth.PermAdmin, auth.Resource{OrgID: pr.OrgID}); err != nil {
+		return nil, err
+	}
+	inv := &repository.Invite{
+		Email:       email,
+		OrgID:       pr.OrgID,
+		WorkspaceID: workspaceID,
+		Role:        role,
+		CreatedBy:   pr.UserID,
+		ExpiresAt:   time.Now().UTC().Add(inviteTTL),
+	}
+	if err := s.invites.StoreInvite(ctx, inv); err != nil {
+		return nil, err
+	}
+	s.notifier.Notify(ctx, email, "you're invited",
+		fmt.Sprintf("%s invited you as %s; accept with token %s", pr.UserID, role, inv.Token))
+	return inv, nil
+}
+
+// ListPendingInvites returns the open invites the principal may see.
+func (s *Service) ListPendingInvites(ctx context.Context, pr auth.Principal, workspaceID string) ([]*repository.Invite, error) {
+	if workspaceID != "" {
+		if _, err := s.workspaceAccess(ctx, pr, workspaceID, auth.PermURLRead); err != nil {
+			return nil, err
+		}
+	} else if err := auth.Authorize(pr, auth.PermAdmin, auth.Resource{OrgID: pr.OrgID}); err != nil {
+		return nil, err
+	}
+	return s.invites.ListPendingInvites(ctx, pr.OrgID, workspaceID)
+}
+
+// AcceptInvite redeems a token for the calling principal: workspace
+// invites add the membership, org invites are recorded for the auth
+// service to pick up the role. Acceptance is exactly-once.
+func (s *Service) AcceptInvite(ctx context.Context, pr auth.Principal, token string) (*repository.Invite, error) {
+	inv, err := s.invites.GetInvite(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if !inv.Pending(time.Now().UTC()) {
+		return nil, fmt.Errorf("invite expired or already used: %w", common.ErrConflict)
+	}
+	if err := s.invites.MarkAccepted(ctx, token); err != nil {
+		return nil, err
+	}
+	if inv.WorkspaceID != "" {
+		err := s.workspaces.SetMember(ctx, inv.WorkspaceID,
+			models.WorkspaceMember{UserID: pr.UserID, Role: inv.Role})
+		if err != nil {
+			return nil, err
+		}
+	}
+	s.notifier.Notify(ctx, inv.CreatedBy, "invite accepted",
+		fmt.Sprintf("%s accepted your invite for %s", pr.UserID, inv.Email))
+	return inv, nil
+}
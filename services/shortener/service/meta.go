@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+// metaFetchLimit bounds how much of a destination page is read; the head
+// section with the meta tags sits well inside it.
+const metaFetchLimit = 256 << 10
+
+// Naive tag extraction is all this needs: we only read og:/twitter: meta
+// tags and <title> from pages we are about to link to anyway.
+var (
+	metaTagPattern = regexp.MustCompile(`(?is)<meta[^>]+(?:property|name)=["'](?:og|twitter):([a-z:_]+)["'][^>]+content=["']([^"']*)["']`)
+	titlePattern   = regexp.MustCompile(`(?is)<title[^>]*>([^<]+)</title>`)
+)
+
+// fetchMetaAsync retrieves the destination's Open Graph metadata in the
+// background and stores it on the link for unfurl bots. Best-effort: a
+// destination without metadata just gets no preview.
+func (s *Service) fetchMetaAsync(u *models.URL) {
+	code, longURL := u.Code, u.LongURL
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		meta := fetchMeta(ctx, longURL)
+		if meta == nil {
+			return
+		}
+		stored, err := s.repo.GetURLByCode(ctx, code)
+		if err != nil {
+			return
+		}
+		stored.Meta = meta
+		if err := s.repo.UpdateURL(ctx, stored); err != nil {
+			log.Printf("meta: storing metadata for %s: %v", code, err)
+		}
+	}()
+}
+
+func fetchMeta(ctx context.Context, longURL string) *models.LinkMeta {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, longURL, nil)
+	if err != nil {
+		return nil
+	}
+	req.Header.Set("User-Agent", "url-minifier-preview/1.0")
+	// Destinations are attacker-chosen; the guarded client keeps this
+	// from reading internal pages into stored preview metadata.
+	resp, err := ssrfSafeFollowClient.Do(req)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK ||
+		!strings.Contains(resp.Header.Get("Content-Type"), "text/html") {
+		return nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, metaFetchLimit))
+	if err != nil {
+		return nil
+	}
+	meta := &models.LinkMeta{}
+	for _, m := range metaTagPattern.FindAllSubmatch(body, -1) {
+		key, value := strings.ToLower(string(m[1])), strings.TrimSpace(string(m[2]))
+		switch key {
+		case "title":
+			meta.Title = value
+		case "description":
+			meta.Description = value
+		case "image", "image:src":
+			meta.Image = value
+		}
+	}
+	if meta.Title == "" {
+		if m := titlePattern.FindSubmatch(body); m != nil {
+			meta.Title = strings.TrimSpace(string(m[1]))
+		}
+	}
+	if meta.Title == "" && meta.Description == "" && meta.Image == "" {
+		return nil
+	}
+	return meta
+}
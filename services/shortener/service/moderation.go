@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ashtonholgate/url-minifier/pkg/auth"
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+	"github.com/ashtonholgate/url-minifier/pkg/notify"
+	"github.com/ashtonholgate/url-minifier/services/shortener/activity"
+)
+
+// EnableModeration switches the deployment into manual-approval mode: links
+// created by untrusted users land in pending status and only redirect once
+// a moderator approves them.
+func (s *Service) EnableModeration() { s.moderation = true }
+
+// SetNotifier replaces the notifier used for moderation outcomes.
+func (s *Service) SetNotifier(n notify.Notifier) { s.notifier = n }
+
+// trusted reports whether the principal's links skip the moderation queue.
+func trusted(pr auth.Principal) bool {
+	switch pr.Role {
+	case auth.RoleOwner, auth.RoleOrgAdmin, auth.RoleSuperadmin:
+		return true
+	}
+	return false
+}
+
+// moderationQueuePageSize bounds one page of the pending queue.
+const moderationQueuePageSize = 100
+
+// PendingURLs returns the oldest pending links for moderator review,
+// scoped to the moderator's own org; only superadmins see every org's
+// queue.
+func (s *Service) PendingURLs(ctx context.Context, pr auth.Principal) ([]*models.URL, error) {
+	if err := auth.Authorize(pr, auth.PermAdmin, auth.Resource{OrgID: pr.OrgID}); err != nil {
+		return nil, err
+	}
+	orgScope := pr.OrgID
+	if pr.Role == auth.RoleSuperadmin {
+		orgScope = ""
+	}
+	return s.repo.ListURLsByStatus(ctx, orgScope, models.URLStatusPending, moderationQueuePageSize)
+}
+
+// DecideURLs bulk-approves or bulk-rejects pending links and notifies each
+// creator of the outcome. Every named link must be within the moderator's
+// tenancy.
+func (s *Service) DecideURLs(ctx context.Context, pr auth.Principal, codes []string, approve bool, reason string) ([]*models.URL, error) {
+	if err := auth.Authorize(pr, auth.PermAdmin, auth.Resource{OrgID: pr.OrgID}); err != nil {
+		return nil, err
+	}
+	if len(codes) == 0 {
+		return nil, fmt.Errorf("no codes given: %w", common.ErrInvalidInput)
+	}
+	for _, code := range codes {
+		u, err := s.repo.GetURLByCode(ctx, code)
+		if err != nil {
+			return nil, err
+		}
+		if err := auth.Authorize(pr, auth.PermAdmin, auth.Resource{OwnerID: u.OwnerID, OrgID: u.OrgID}); err != nil {
+			return nil, err
+		}
+	}
+	status := models.URLStatusRejected
+	if approve {
+		status = models.URLStatusActive
+	}
+	updated, err := s.repo.SetStatus(ctx, codes, status)
+	if err != nil {
+		return nil, err
+	}
+	s.purgeCodes(codes...)
+	for _, u := range updated {
+		msg := fmt.Sprintf("your link %s was %s", u.Code, status)
+		if reason != "" {
+			msg += ": " + reason
+		}
+		s.notifier.Notify(ctx, u.OwnerID, "link moderation", msg)
+		if !approve {
+			s.recordActivity(ctx, u.OwnerID, activity.Event{
+				Type: activity.EventFlagged, Code: u.Code, Message: reason,
+			})
+		}
+	}
+	return updated, nil
+}
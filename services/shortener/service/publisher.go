@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+	"github.com/ashtonholgate/url-minifier/services/shortener/activity"
+)
+
+// publisherBatch bounds one publishing sweep.
+const publisherBatch = 100
+
+// StartPublisher activates due drafts every interval: status flips to
+// active (invalidating the cache through SetStatus) and the creator is
+// notified, which is also where the publish webhook fires once the
+// outbound connector framework delivers notifications externally.
+func (s *Service) StartPublisher(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.publishDueDrafts(ctx)
+			}
+		}
+	}()
+}
+
+func (s *Service) publishDueDrafts(ctx context.Context) {
+	drafts, err := s.repo.ListDueDrafts(ctx, time.Now().UTC(), publisherBatch)
+	if err != nil {
+		log.Printf("publisher: listing due drafts: %v", err)
+		return
+	}
+	if len(drafts) == 0 {
+		return
+	}
+	codes := make([]string, len(drafts))
+	for i, u := range drafts {
+		codes[i] = u.Code
+	}
+	published, err := s.repo.SetStatus(ctx, codes, models.URLStatusActive)
+	if err != nil {
+		log.Printf("publisher: activating drafts: %v", err)
+		return
+	}
+	for _, u := range published {
+		s.notifier.Notify(ctx, u.OwnerID, "link published",
+			fmt.Sprintf("your scheduled link %s is now live", u.Code))
+		s.recordActivity(ctx, u.OwnerID, activity.Event{
+			Type: activity.EventCreated, Code: u.Code, Message: "published from draft",
+		})
+		s.dispatchEvent(u.OrgID, "link.published", map[string]interface{}{"code": u.Code, "long_url": u.LongURL})
+	}
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"github.com/ashtonholgate/url-minifier/pkg/cdn"
+)
+
+// SetCDNPurger attaches edge purging for mutated links; baseURL is the
+// public serving origin the CDN caches under.
+func (s *Service) SetCDNPurger(p cdn.Purger, baseURL string) {
+	s.purger = p
+	s.purgeBase = baseURL
+}
+
+// purgeURLsFor lists every edge-cached path for a code: the redirect
+// itself and the public resolve API.
+func (s *Service) purgeURLsFor(codes ...string) []string {
+	if s.purger == nil || s.purgeBase == "" {
+		return nil
+	}
+	urls := make([]string, 0, len(codes)*2)
+	for _, code := range codes {
+		urls = append(urls,
+			s.purgeBase+"/"+code,
+			s.purgeBase+"/api/resolve/"+code,
+		)
+	}
+	return urls
+}
+
+// purgeCodes invalidates the edge for mutated codes, asynchronously.
+func (s *Service) purgeCodes(codes ...string) {
+	cdn.AsyncPurge(s.purger, s.purgeURLsFor(codes...))
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ashtonholgate/url-minifier/pkg/auth"
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/services/shortener/repository"
+)
+
+// maxReservationHold bounds temporary holds; permanent brand reservations
+// have no expiry and require admin rights.
+const maxReservationHold = 60 * time.Minute
+
+// SetReservationRepository attaches alias reservation storage.
+func (s *Service) SetReservationRepository(reservations *repository.ReservationRepository) {
+	s.reservations = reservations
+}
+
+// ReserveAlias holds an alias for the principal: for minutes (capped at an
+// hour) while a form is completed, or permanently (minutes <= 0) for
+// brand terms, which takes org-admin rights.
+func (s *Service) ReserveAlias(ctx context.Context, pr auth.Principal, alias string, minutes int) (*repository.Reservation, error) {
+	canonical, err := s.ValidateCustomAlias(alias)
+	if err != nil {
+		return nil, err
+	}
+	canonical = s.canonicalCode(canonical)
+	if available, err := s.aliasAvailable(ctx, canonical); err != nil {
+		return nil, err
+	} else if !available {
+		return nil, fmt.Errorf("alias %q already taken: %w", canonical, common.ErrConflict)
+	}
+	res := &repository.Reservation{Alias: canonical, OrgID: pr.OrgID, HeldBy: pr.UserID}
+	if minutes > 0 {
+		if err := auth.Authorize(pr, auth.PermURLCreate, auth.Resource{}); err != nil {
+			return nil, err
+		}
+		hold := time.Duration(minutes) * time.Minute
+		if hold > maxReservationHold {
+			hold = maxReservationHold
+		}
+		expires := time.Now().UTC().Add(hold)
+		res.ExpiresAt = &expires
+	} else if err := auth.Authorize(pr, auth.PermAdmin, auth.Resource{OrgID: pr.OrgID}); err != nil {
+		return nil, err
+	}
+	if err := s.reservations.Reserve(ctx, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ReleaseAlias drops the principal's hold on alias.
+func (s *Service) ReleaseAlias(ctx context.Context, pr auth.Principal, alias string) error {
+	return s.reservations.Release(ctx, s.canonicalCode(alias), pr.UserID)
+}
+
+// aliasReservedForOther reports whether alias is reserved by someone other
+// than the principal (their own holds and their org's brand reserves don't
+// block them).
+func (s *Service) aliasReservedForOther(ctx context.Context, pr auth.Principal, alias string) (bool, error) {
+	if s.reservations == nil {
+		return false, nil
+	}
+	res, err := s.reservations.GetActive(ctx, alias)
+	if common.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if res.HeldBy == pr.UserID {
+		return false, nil
+	}
+	if res.OrgID != "" && res.OrgID == pr.OrgID && res.ExpiresAt == nil {
+		// Permanent org brand reserves are claimable by anyone in the org.
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+// SetRotationRedis attaches the Redis client backing atomic rotation
+// pointers. Without it, rotating links always serve their first
+// destination.
+func (s *Service) SetRotationRedis(rdb redis.UniversalClient) { s.rotation = rdb }
+
+func validateRotation(rot *models.RotationOptions) error {
+	if rot.Mode != models.RotationRoundRobin && rot.Mode != models.RotationSequential {
+		return fmt.Errorf("rotation mode must be %q or %q: %w",
+			models.RotationRoundRobin, models.RotationSequential, common.ErrInvalidInput)
+	}
+	if len(rot.Destinations) < 2 {
+		return fmt.Errorf("rotation needs at least two destinations: %w", common.ErrInvalidInput)
+	}
+	for i, d := range rot.Destinations {
+		parsed, err := url.Parse(d.URL)
+		if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			return fmt.Errorf("rotation destination %d must be an absolute http(s) URL: %w", i, common.ErrInvalidInput)
+		}
+	}
+	return nil
+}
+
+// rotateDestination picks this click's destination through the shared
+// rotation logic.
+func (s *Service) rotateDestination(ctx context.Context, u *models.URL) string {
+	return RotateDestination(ctx, s.rotation, u)
+}
+
+// RotateDestination picks a rotating link's destination by atomically
+// advancing the link's pointer in Redis; exported because the standalone
+// redirect binary serves the same links and must rotate identically.
+// Redis failures fall back to the first destination rather than failing
+// the redirect.
+func RotateDestination(ctx context.Context, rdb redis.UniversalClient, u *models.URL) string {
+	rot := u.Rotation
+	if rot == nil || len(rot.Destinations) == 0 {
+		return u.LongURL
+	}
+	if rdb == nil {
+		return rot.Destinations[0].URL
+	}
+	n, err := rdb.Incr(ctx, "rot:"+u.Code).Result()
+	if err != nil {
+		log.Printf("rotation: advancing pointer for %s: %v", u.Code, err)
+		return rot.Destinations[0].URL
+	}
+	seq := n - 1 // zero-based click sequence number
+	switch rot.Mode {
+	case models.RotationRoundRobin:
+		return rot.Destinations[seq%int64(len(rot.Destinations))].URL
+	default: // sequential
+		for _, d := range rot.Destinations {
+			if d.Cap <= 0 || seq < d.Cap {
+				return d.URL
+			}
+			seq -= d.Cap
+		}
+		// Every cap exhausted: overflow to the link's base destination.
+		return u.LongURL
+	}
+}
@@ -0,0 +1,615 @@
+// Package service implements the URL shortening business logic. Every method
+// takes the calling principal and performs its own authorization via
+// pkg/auth, so handlers stay thin and no path can bypass the checks.
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/ashtonholgate/url-minifier/pkg/auth"
+	"github.com/ashtonholgate/url-minifier/pkg/cdn"
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/connectors"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+	"github.com/ashtonholgate/url-minifier/pkg/notify"
+	"github.com/ashtonholgate/url-minifier/pkg/plans"
+	"github.com/ashtonholgate/url-minifier/services/shortener/activity"
+	"github.com/ashtonholgate/url-minifier/services/shortener/policy"
+	"github.com/ashtonholgate/url-minifier/services/shortener/repository"
+)
+
+// maxCreateAttempts bounds collision retries when generating a short code.
+const maxCreateAttempts = 5
+
+// Service wires the repository and code generator together behind the
+// authorization and entitlement layers.
+type Service struct {
+	repo     repository.URLRepository
+	gen      CodeGenerator
+	ent      plans.Entitlements
+	domains  *repository.DomainRepository
+	policies *policy.Store
+	// moderation, when set, routes untrusted users' links through the
+	// pending queue; see moderation.go.
+	moderation bool
+	notifier   notify.Notifier
+	bundles    *repository.BundleRepository
+	// unicodeAliases relaxes custom alias validation; see aliasvalidate.go.
+	unicodeAliases bool
+	// caseInsensitive folds codes to lower case; see casefold.go.
+	caseInsensitive bool
+	// typoSuggest enables "did you mean" on 404; see suggest.go.
+	typoSuggest bool
+	// feed, when set, receives link lifecycle events for the dashboard.
+	feed *activity.Feed
+	// filters, when set, backs saved filters; see filters.go.
+	filters *repository.SavedFilterRepository
+	// revisions, when set, records pre-change snapshots of links.
+	revisions *repository.RevisionRepository
+	// campaigns, when set, backs campaign grouping; see campaigns.go.
+	campaigns *repository.CampaignRepository
+	// workspaces, when set, backs shared ownership; see workspaces.go.
+	workspaces *repository.WorkspaceRepository
+	// invites, when set, backs the invitation flow; see invites.go.
+	invites *repository.InviteRepository
+	// reservations, when set, backs alias holds; see reservations.go.
+	reservations *repository.ReservationRepository
+	// brandTerms, when set, backs homograph protection; see homograph.go.
+	brandTerms *repository.BrandTermRepository
+	// dispatcher, when set, fans link events out to the org's configured
+	// connectors (Slack, Sheets, webhooks).
+	dispatcher *connectors.Dispatcher
+	// rotation backs multi-destination pointer advancement; see
+	// rotation.go.
+	rotation redis.UniversalClient
+	// storage, when set, backs per-org storage accounting; see
+	// storage.go.
+	storage *storageAccounting
+	// purger invalidates edge caches on mutation; see purge.go.
+	purger    cdn.Purger
+	purgeBase string
+	// generations dispatches resolution by code format version; held
+	// explicitly because the generator chain may wrap the scheme in
+	// canary or other decorators.
+	generations *GenerationScheme
+}
+
+// SetGenerationScheme registers the scheme the resolver dispatches code
+// formats against, independent of whatever decorates the generator.
+func (s *Service) SetGenerationScheme(scheme *GenerationScheme) { s.generations = scheme }
+
+// SetConnectorDispatcher attaches outbound integration delivery.
+func (s *Service) SetConnectorDispatcher(d *connectors.Dispatcher) { s.dispatcher = d }
+
+// dispatchEvent forwards a link event to the org's connectors.
+func (s *Service) dispatchEvent(orgID, eventType string, payload map[string]interface{}) {
+	if s.dispatcher != nil {
+		s.dispatcher.Dispatch(connectors.Event{Type: eventType, OrgID: orgID, Payload: payload})
+	}
+}
+
+// SetRevisionRepository attaches change-history storage.
+func (s *Service) SetRevisionRepository(revisions *repository.RevisionRepository) {
+	s.revisions = revisions
+}
+
+// recordRevision snapshots u before a change. Best-effort: history must not
+// block the change itself.
+func (s *Service) recordRevision(ctx context.Context, u *models.URL, changedBy, change string) {
+	if s.revisions == nil {
+		return
+	}
+	if err := s.revisions.StoreRevision(ctx, u, changedBy, change); err != nil {
+		log.Printf("service: recording revision of %s: %v", u.Code, err)
+	}
+}
+
+// SetActivityFeed attaches the per-user activity feed.
+func (s *Service) SetActivityFeed(feed *activity.Feed) { s.feed = feed }
+
+// recordActivity appends an event to the owner's feed when one is attached.
+func (s *Service) recordActivity(ctx context.Context, userID string, e activity.Event) {
+	if s.feed != nil {
+		s.feed.Record(ctx, userID, e)
+	}
+}
+
+// New returns a Service using the given repository, generator, entitlement
+// checker, domain registry and destination policy store.
+func New(repo repository.URLRepository, gen CodeGenerator, ent plans.Entitlements, domains *repository.DomainRepository, policies *policy.Store) *Service {
+	return &Service{
+		repo: repo, gen: gen, ent: ent, domains: domains, policies: policies,
+		notifier: notify.LogNotifier{},
+	}
+}
+
+// CreateURLRequest carries the caller-supplied fields for a new link.
+type CreateURLRequest struct {
+	LongURL   string                  `json:"long_url"`
+	Alias     string                  `json:"alias,omitempty"`
+	Tags      []string                `json:"tags,omitempty"`
+	ExpiresAt *time.Time              `json:"expires_at,omitempty"`
+	Redirect  *models.RedirectOptions `json:"redirect,omitempty"`
+	// DeepLink enables app-scheme handling; long_url stays the HTTP
+	// fallback for platforms without the app.
+	DeepLink *models.DeepLinkOptions `json:"deep_link,omitempty"`
+	// Rotation distributes clicks across several destinations.
+	Rotation *models.RotationOptions `json:"rotation,omitempty"`
+	// Experiment runs A/B destinations on the link (plan-gated).
+	Experiment *models.ExperimentOptions `json:"experiment,omitempty"`
+	// LanguageTargets maps language tags to localised destinations.
+	LanguageTargets map[string]string `json:"language_targets,omitempty"`
+	// CampaignID attaches the link to a campaign in the caller's org.
+	CampaignID string `json:"campaign_id,omitempty"`
+	// WorkspaceID shares the link with a workspace the caller belongs to.
+	WorkspaceID string `json:"workspace_id,omitempty"`
+	// Domain selects the short domain the link is served under.
+	Domain string `json:"domain,omitempty"`
+	// PublishAt creates the link as a draft that activates automatically
+	// at the given time.
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+}
+
+// validate checks the request independently of storage state.
+func (req *CreateURLRequest) validate() error {
+	parsed, err := url.Parse(req.LongURL)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return fmt.Errorf("long_url must be an absolute http(s) URL: %w", common.ErrInvalidInput)
+	}
+	if req.DeepLink != nil {
+		if err := validateDeepLink(req.DeepLink); err != nil {
+			return err
+		}
+	}
+	for tag, dest := range req.LanguageTargets {
+		parsed, err := url.Parse(dest)
+		if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			return fmt.Errorf("language target %q must be an absolute http(s) URL: %w", tag, common.ErrInvalidInput)
+		}
+	}
+	if req.Redirect != nil {
+		if err := validateCustomHeaders(req.Redirect.CustomHeaders); err != nil {
+			return err
+		}
+	}
+	if req.Rotation != nil {
+		if err := validateRotation(req.Rotation); err != nil {
+			return err
+		}
+	}
+	if req.Experiment != nil {
+		if err := validateExperiment(req.Experiment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// allowedRedirectHeaders is the closed set owners may attach to redirect
+// responses; anything capable of changing security posture (CSP, CORS,
+// Set-Cookie) is deliberately absent.
+var allowedRedirectHeaders = map[string]bool{
+	"X-Campaign":      true,
+	"X-Robots-Tag":    true,
+	"Referrer-Policy": true,
+	"Cache-Control":   true,
+	"Expires":         true,
+}
+
+func validateCustomHeaders(headers map[string]string) error {
+	for name, value := range headers {
+		if !allowedRedirectHeaders[http.CanonicalHeaderKey(name)] {
+			return fmt.Errorf("header %q is not on the redirect allowlist: %w", name, common.ErrInvalidInput)
+		}
+		if len(value) > 512 || strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("header %q has a malformed value: %w", name, common.ErrInvalidInput)
+		}
+	}
+	return nil
+}
+
+// validateDeepLink checks every configured app URI uses a custom scheme;
+// http(s) there would loop straight back through the browser.
+func validateDeepLink(d *models.DeepLinkOptions) error {
+	if d.AppURI == "" && d.IOSAppURI == "" && d.AndroidAppURI == "" {
+		return fmt.Errorf("deep_link needs at least one app URI: %w", common.ErrInvalidInput)
+	}
+	for _, raw := range []string{d.AppURI, d.IOSAppURI, d.AndroidAppURI} {
+		if raw == "" {
+			continue
+		}
+		parsed, err := url.Parse(raw)
+		if err != nil || parsed.Scheme == "" || parsed.Scheme == "http" || parsed.Scheme == "https" {
+			return fmt.Errorf("app URI %q must use a custom scheme: %w", raw, common.ErrInvalidInput)
+		}
+	}
+	return nil
+}
+
+// CreateURL shortens req.LongURL for the principal, honouring a custom alias
+// when supplied and otherwise generating a fresh code.
+func (s *Service) CreateURL(ctx context.Context, pr auth.Principal, req CreateURLRequest) (*models.URL, error) {
+	if err := auth.Authorize(pr, auth.PermURLCreate, auth.Resource{}); err != nil {
+		return nil, err
+	}
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+	if err := s.policies.Evaluate(ctx, pr.OrgID, req.LongURL); err != nil {
+		return nil, err
+	}
+	if err := s.checkCampaign(ctx, pr, req.CampaignID); err != nil {
+		return nil, err
+	}
+	if err := s.checkWorkspace(ctx, pr, req.WorkspaceID); err != nil {
+		return nil, err
+	}
+	// Fine-grained token scopes: a key pinned to certain domains or
+	// campaigns may only mint links there.
+	if !pr.DomainAllowed(req.Domain) {
+		return nil, fmt.Errorf("%w: token not scoped for domain %q", auth.ErrForbidden, req.Domain)
+	}
+	if req.CampaignID != "" && !pr.CampaignAllowed(req.CampaignID) {
+		return nil, fmt.Errorf("%w: token not scoped for campaign %q", auth.ErrForbidden, req.CampaignID)
+	}
+	if req.Alias != "" {
+		if err := s.ent.Check(ctx, pr.OrgID, plans.FeatureCustomAlias); err != nil {
+			return nil, err
+		}
+	}
+	plan, err := s.ent.PlanFor(ctx, pr.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	count, err := s.repo.CountURLsByOrg(ctx, pr.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= plan.MaxLinks {
+		return nil, fmt.Errorf("link quota %d reached on plan %s: %w",
+			plan.MaxLinks, plan.Name, plans.ErrUpgradeRequired)
+	}
+	if err := s.checkStorageQuota(ctx, pr.OrgID, plan); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	u := &models.URL{
+		LongURL:     req.LongURL,
+		OwnerID:     pr.UserID,
+		OrgID:       pr.OrgID,
+		Tags:        req.Tags,
+		CampaignID:  req.CampaignID,
+		WorkspaceID: req.WorkspaceID,
+		Domain:      req.Domain,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		ExpiresAt:   req.ExpiresAt,
+		Verified:    destinationVerified(ctx, s.domains, req.LongURL),
+		Status:      models.URLStatusActive,
+	}
+	if req.Redirect != nil {
+		u.Redirect = *req.Redirect
+	}
+	u.DeepLink = req.DeepLink
+	u.Rotation = req.Rotation
+	if req.Experiment != nil {
+		if err := s.ent.Check(ctx, pr.OrgID, plans.FeatureABTesting); err != nil {
+			return nil, err
+		}
+		u.Experiment = req.Experiment
+	}
+	if len(req.LanguageTargets) > 0 {
+		u.LanguageTargets = make(map[string]string, len(req.LanguageTargets))
+		for tag, dest := range req.LanguageTargets {
+			u.LanguageTargets[strings.ToLower(tag)] = dest
+		}
+	}
+	if s.moderation && !trusted(pr) {
+		u.Status = models.URLStatusPending
+	}
+	if req.PublishAt != nil {
+		if !req.PublishAt.After(now) {
+			return nil, fmt.Errorf("publish_at must be in the future: %w", common.ErrInvalidInput)
+		}
+		u.Status = models.URLStatusDraft
+		u.PublishAt = req.PublishAt
+	}
+	if req.Alias != "" {
+		canonical, err := s.ValidateCustomAlias(req.Alias)
+		if err != nil {
+			return nil, err
+		}
+		u.Code = s.canonicalCode(canonical)
+		if err := s.checkHomograph(ctx, pr, u.Code); err != nil {
+			return nil, err
+		}
+		if err := s.checkReservedPrefix(ctx, req.Domain, u.Code); err != nil {
+			return nil, err
+		}
+		if reserved, err := s.aliasReservedForOther(ctx, pr, u.Code); err != nil {
+			return nil, err
+		} else if reserved {
+			return nil, fmt.Errorf("alias %q is reserved: %w", u.Code, common.ErrConflict)
+		}
+		if err := s.repo.StoreURL(ctx, u); err != nil {
+			return nil, err
+		}
+		s.recordActivity(ctx, u.OwnerID, activity.Event{Type: activity.EventCreated, Code: u.Code})
+		s.fetchMetaAsync(u)
+		s.dispatchEvent(u.OrgID, "link.created", map[string]interface{}{"code": u.Code, "long_url": u.LongURL})
+		return u, nil
+	}
+	for attempt := 0; attempt < maxCreateAttempts; attempt++ {
+		code, err := s.gen.Generate(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("generating code: %w", err)
+		}
+		u.Code = s.canonicalCode(code)
+		err = s.repo.StoreURL(ctx, u)
+		if err == nil {
+			s.recordActivity(ctx, u.OwnerID, activity.Event{Type: activity.EventCreated, Code: u.Code})
+			s.fetchMetaAsync(u)
+			s.dispatchEvent(u.OrgID, "link.created", map[string]interface{}{"code": u.Code, "long_url": u.LongURL})
+			return u, nil
+		}
+		if !errors.Is(err, common.ErrConflict) {
+			return nil, err
+		}
+		if observer, ok := s.gen.(collisionObserver); ok {
+			observer.ObserveCollision(u.Code)
+		}
+	}
+	return nil, fmt.Errorf("exhausted %d attempts to find a free code: %w", maxCreateAttempts, common.ErrConflict)
+}
+
+// GetURL returns the link for code if the principal may read it.
+func (s *Service) GetURL(ctx context.Context, pr auth.Principal, code string) (*models.URL, error) {
+	u, err := s.repo.GetURLByCode(ctx, s.canonicalCode(code))
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(pr, auth.PermURLRead, auth.Resource{OwnerID: u.OwnerID, OrgID: u.OrgID}); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// UpdateURLRequest carries the mutable fields of a link. Nil pointers leave
+// the stored value untouched.
+type UpdateURLRequest struct {
+	LongURL   *string                 `json:"long_url,omitempty"`
+	Tags      *[]string               `json:"tags,omitempty"`
+	ExpiresAt *time.Time              `json:"expires_at,omitempty"`
+	Redirect  *models.RedirectOptions `json:"redirect,omitempty"`
+	// IfVersion, when set, makes the update conditional: it fails with a
+	// conflict unless the stored link is still at this version.
+	IfVersion *int64 `json:"if_version,omitempty"`
+	// ExternalIDs merges source-system identifiers into the link; a key
+	// with an empty value removes that annotation.
+	ExternalIDs map[string]string `json:"external_ids,omitempty"`
+}
+
+// UpdateURL applies req to the link for code.
+func (s *Service) UpdateURL(ctx context.Context, pr auth.Principal, code string, req UpdateURLRequest) (*models.URL, error) {
+	u, err := s.repo.GetURLByCode(ctx, s.canonicalCode(code))
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(pr, auth.PermURLUpdate, auth.Resource{OwnerID: u.OwnerID, OrgID: u.OrgID}); err != nil {
+		return nil, err
+	}
+	if !pr.DomainAllowed(u.Domain) || !pr.CampaignAllowed(u.CampaignID) {
+		return nil, fmt.Errorf("%w: token not scoped for this link", auth.ErrForbidden)
+	}
+	if req.IfVersion != nil && *req.IfVersion != u.Version {
+		return nil, fmt.Errorf("link is at version %d, not %d: %w",
+			u.Version, *req.IfVersion, common.ErrConflict)
+	}
+	before := *u
+	if req.LongURL != nil {
+		check := CreateURLRequest{LongURL: *req.LongURL}
+		if err := check.validate(); err != nil {
+			return nil, err
+		}
+		if err := s.policies.Evaluate(ctx, pr.OrgID, *req.LongURL); err != nil {
+			return nil, err
+		}
+		u.LongURL = *req.LongURL
+		u.Verified = destinationVerified(ctx, s.domains, u.LongURL)
+	}
+	if req.Tags != nil {
+		u.Tags = *req.Tags
+	}
+	if req.ExpiresAt != nil {
+		u.ExpiresAt = req.ExpiresAt
+	}
+	if req.Redirect != nil {
+		if err := validateCustomHeaders(req.Redirect.CustomHeaders); err != nil {
+			return nil, err
+		}
+		u.Redirect = *req.Redirect
+	}
+	if len(req.ExternalIDs) > 0 {
+		if u.ExternalIDs == nil {
+			u.ExternalIDs = make(map[string]string, len(req.ExternalIDs))
+		}
+		for system, id := range req.ExternalIDs {
+			if id == "" {
+				delete(u.ExternalIDs, system)
+			} else {
+				u.ExternalIDs[system] = id
+			}
+		}
+	}
+	if err := s.repo.UpdateURL(ctx, u); err != nil {
+		return nil, err
+	}
+	s.recordRevision(ctx, &before, pr.UserID, "update")
+	s.recordActivity(ctx, u.OwnerID, activity.Event{Type: activity.EventEdited, Code: u.Code})
+	s.dispatchEvent(u.OrgID, "link.updated", map[string]interface{}{"code": u.Code, "long_url": u.LongURL})
+	s.purgeCodes(u.Code)
+	return u, nil
+}
+
+// DeleteURL removes the link for code.
+func (s *Service) DeleteURL(ctx context.Context, pr auth.Principal, code string) error {
+	u, err := s.repo.GetURLByCode(ctx, s.canonicalCode(code))
+	if err != nil {
+		return err
+	}
+	if err := auth.Authorize(pr, auth.PermURLDelete, auth.Resource{OwnerID: u.OwnerID, OrgID: u.OrgID}); err != nil {
+		return err
+	}
+	if !pr.DomainAllowed(u.Domain) || !pr.CampaignAllowed(u.CampaignID) {
+		return fmt.Errorf("%w: token not scoped for this link", auth.ErrForbidden)
+	}
+	if err := s.repo.DeleteURL(ctx, u.Code); err != nil {
+		return err
+	}
+	s.recordRevision(ctx, u, pr.UserID, "delete")
+	s.recordActivity(ctx, u.OwnerID, activity.Event{Type: activity.EventDeleted, Code: u.Code})
+	s.dispatchEvent(u.OrgID, "link.deleted", map[string]interface{}{"code": u.Code})
+	s.purgeCodes(u.Code)
+	return nil
+}
+
+// urlFields is the whitelist for sparse-fieldset requests, keyed by the
+// public json name (which matches the bson name throughout models.URL).
+var urlFields = map[string]bool{
+	"code": true, "long_url": true, "owner_id": true, "org_id": true,
+	"campaign_id": true, "tags": true, "created_at": true, "updated_at": true,
+	"expires_at": true, "clicks": true, "verified": true, "status": true,
+	"version": true,
+}
+
+// ListURLs returns a page of the principal's own links. fields, when
+// non-empty, restricts both the Mongo projection and the response to the
+// named fields.
+func (s *Service) ListURLs(ctx context.Context, pr auth.Principal, limit, offset int64, fields []string) ([]*models.URL, error) {
+	if err := auth.Authorize(pr, auth.PermURLRead, auth.Resource{OwnerID: pr.UserID}); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	for _, f := range fields {
+		if !urlFields[f] {
+			return nil, fmt.Errorf("unknown field %q: %w", f, common.ErrInvalidInput)
+		}
+	}
+	return s.repo.ListURLsByOwner(ctx, pr.UserID, limit, offset, fields)
+}
+
+// Resolve returns the destination for code for redirecting, with no
+// authorization: redirects are public. Expired links resolve to ErrNotFound.
+func (s *Service) Resolve(ctx context.Context, code string) (*models.URL, error) {
+	// Dispatch on the code's generation first: unknown formats can never
+	// resolve, so they skip storage entirely.
+	if s.generations != nil && !s.generations.Known(code) {
+		return nil, fmt.Errorf("code %q has unknown generation: %w", code, common.ErrNotFound)
+	}
+	code = s.canonicalCode(code)
+	u, err := s.repo.GetURLByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if u.Expired(time.Now().UTC()) {
+		return nil, fmt.Errorf("code %q expired: %w", code, common.ErrNotFound)
+	}
+	if !u.Active() {
+		// Pending and rejected links are indistinguishable from missing
+		// ones to visitors.
+		return nil, fmt.Errorf("code %q not active: %w", code, common.ErrNotFound)
+	}
+	if u.Rotation != nil {
+		rotated := *u
+		rotated.LongURL = s.rotateDestination(ctx, u)
+		u = &rotated
+	}
+	if err := s.repo.IncrementClicks(ctx, code); err != nil {
+		// Losing a click is preferable to failing the redirect.
+		return u, nil
+	}
+	if milestone(u.Clicks + 1) {
+		s.recordActivity(ctx, u.OwnerID, activity.Event{
+			Type:    activity.EventMilestone,
+			Code:    u.Code,
+			Message: fmt.Sprintf("%d clicks", u.Clicks+1),
+		})
+	}
+	return u, nil
+}
+
+// Revisions returns the change history for a link the principal may read.
+func (s *Service) Revisions(ctx context.Context, pr auth.Principal, code string) ([]repository.Revision, error) {
+	u, err := s.repo.GetURLByCode(ctx, s.canonicalCode(code))
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(pr, auth.PermURLRead, auth.Resource{OwnerID: u.OwnerID, OrgID: u.OrgID}); err != nil {
+		return nil, err
+	}
+	if s.revisions == nil {
+		return nil, nil
+	}
+	return s.revisions.ListRevisions(ctx, u.Code)
+}
+
+// ActivityFeed returns the principal's recent link events, newest first.
+func (s *Service) ActivityFeed(ctx context.Context, pr auth.Principal, limit int64) ([]activity.Event, error) {
+	if err := auth.Authorize(pr, auth.PermURLRead, auth.Resource{OwnerID: pr.UserID}); err != nil {
+		return nil, err
+	}
+	if s.feed == nil {
+		return nil, nil
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	return s.feed.Recent(ctx, pr.UserID, limit)
+}
+
+// GetURLByExternalID looks a link up by its source-system annotation,
+// subject to the caller's read permission on the found link.
+func (s *Service) GetURLByExternalID(ctx context.Context, pr auth.Principal, system, id string) (*models.URL, error) {
+	u, err := s.repo.GetURLByExternalID(ctx, system, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(pr, auth.PermURLRead, auth.Resource{OwnerID: u.OwnerID, OrgID: u.OrgID}); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// Peek returns an active link without counting a click, for companion
+// endpoints (the impression pixel) that must not inflate click totals.
+func (s *Service) Peek(ctx context.Context, code string) (*models.URL, error) {
+	u, err := s.repo.GetURLByCode(ctx, s.canonicalCode(code))
+	if err != nil {
+		return nil, err
+	}
+	if u.Expired(time.Now().UTC()) || !u.Active() {
+		return nil, fmt.Errorf("code %q not active: %w", code, common.ErrNotFound)
+	}
+	return u, nil
+}
+
+// milestone reports whether n is a click count worth celebrating in the
+// activity feed.
+func milestone(n int64) bool {
+	switch n {
+	case 100, 1_000, 10_000, 100_000, 1_000_000:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+func TestEditDistanceAtMostOne(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"abc1234", "abc1234", true}, // equal
+		{"abc1234", "abc1235", true}, // substitution
+		{"abc1234", "abc123", true},  // deletion
+		{"abc123", "abc1234", true},  // insertion
+		{"abc1234", "abc12", false},  // two deletions
+		{"abc1234", "abd1235", false},
+		{"", "a", true},
+		{"", "ab", false},
+	}
+	for _, tt := range tests {
+		if got := editDistanceAtMostOne(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistanceAtMostOne(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSkeletonFoldsConfusables(t *testing.T) {
+	// Cyrillic "раураl" folds onto the Latin skeleton of "paypal"-ish
+	// lookalikes; the point is that visually identical strings collide.
+	if Skeleton("gооgle") != Skeleton("google") { // Cyrillic о
+		t.Error("Cyrillic o not folded")
+	}
+	if Skeleton("PayPa1") != Skeleton("paypal") {
+		t.Error("digit 1 / case not folded")
+	}
+	if Skeleton("café") != Skeleton("cafe") {
+		t.Error("diacritics not stripped")
+	}
+	if Skeleton("github") == Skeleton("gitlab") {
+		t.Error("distinct terms collided")
+	}
+}
+
+func TestValidateCustomAlias(t *testing.T) {
+	strict := &Service{}
+	if _, err := strict.ValidateCustomAlias("my-link_1"); err != nil {
+		t.Errorf("ascii alias rejected: %v", err)
+	}
+	if _, err := strict.ValidateCustomAlias("emoji🎉"); err == nil {
+		t.Error("unicode alias accepted without the flag")
+	}
+
+	relaxed := &Service{}
+	relaxed.EnableUnicodeAliases()
+	if _, err := relaxed.ValidateCustomAlias("emoji🎉"); err != nil {
+		t.Errorf("unicode alias rejected with flag on: %v", err)
+	}
+	// Latin g + Cyrillic о is a classic homograph.
+	if _, err := relaxed.ValidateCustomAlias("gооgle"); err == nil {
+		t.Error("mixed-script alias accepted")
+	}
+	if _, err := relaxed.ValidateCustomAlias("has space"); err == nil {
+		t.Error("alias with space accepted")
+	}
+}
+
+func TestCanonicalCode(t *testing.T) {
+	s := &Service{}
+	if s.canonicalCode("MiXeD") != "MiXeD" {
+		t.Error("case folding applied without the flag")
+	}
+	s.EnableCaseInsensitiveCodes()
+	if s.canonicalCode("MiXeD") != "mixed" {
+		t.Error("case folding not applied with the flag")
+	}
+}
+
+func TestParseGeneration(t *testing.T) {
+	tests := []struct {
+		code string
+		gen  int
+		rest string
+	}{
+		{"abc1234", 1, "abc1234"},
+		{"g2_xyz", 2, "xyz"},
+		{"g10_k", 10, "k"},
+		{"g_notagen", 1, "g_notagen"},
+	}
+	for _, tt := range tests {
+		gen, rest := ParseGeneration(tt.code)
+		if gen != tt.gen || rest != tt.rest {
+			t.Errorf("ParseGeneration(%q) = (%d, %q), want (%d, %q)",
+				tt.code, gen, rest, tt.gen, tt.rest)
+		}
+	}
+}
+
+func TestGenerationScheme(t *testing.T) {
+	scheme := NewGenerationScheme()
+	code, err := scheme.Generate(nil)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if strings.HasPrefix(code, "g") && strings.Contains(code, "_") {
+		t.Errorf("generation 1 must be unprefixed: %q", code)
+	}
+	if !scheme.Known(code) {
+		t.Errorf("generation 1 code unknown: %q", code)
+	}
+	if scheme.Known("g9_abc") {
+		t.Error("unregistered generation reported known")
+	}
+	scheme.Register(2, NewRandomGenerator(6))
+	if err := scheme.SetActive(2); err != nil {
+		t.Fatalf("SetActive: %v", err)
+	}
+	code, err = scheme.Generate(nil)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if !strings.HasPrefix(code, "g2_") {
+		t.Errorf("generation 2 code unprefixed: %q", code)
+	}
+	if err := scheme.SetActive(9); err == nil {
+		t.Error("activating an unregistered generation succeeded")
+	}
+}
+
+func TestAssignVariant(t *testing.T) {
+	exp := &models.ExperimentOptions{
+		Name: "landing-test",
+		Variants: []models.ExperimentVariant{
+			{Name: "control", URL: "https://example.com/a", Weight: 1},
+			{Name: "treatment", URL: "https://example.com/b", Weight: 1},
+		},
+	}
+	name, dest := AssignVariant(exp, "code1", "visitor-1")
+	// Sticky: the same visitor always gets the same arm.
+	for i := 0; i < 10; i++ {
+		again, againDest := AssignVariant(exp, "code1", "visitor-1")
+		if again != name || againDest != dest {
+			t.Fatal("assignment not sticky")
+		}
+	}
+	// Both arms are reachable across visitors.
+	seen := map[string]bool{}
+	for i := 0; i < 200; i++ {
+		arm, _ := AssignVariant(exp, "code1", strings.Repeat("v", i+1))
+		seen[arm] = true
+	}
+	if !seen["control"] || !seen["treatment"] {
+		t.Errorf("arms reached: %v", seen)
+	}
+}
+
+func TestValidateCustomHeaders(t *testing.T) {
+	if err := validateCustomHeaders(map[string]string{"X-Campaign": "summer"}); err != nil {
+		t.Errorf("allowlisted header rejected: %v", err)
+	}
+	if err := validateCustomHeaders(map[string]string{"Set-Cookie": "x=1"}); err == nil {
+		t.Error("Set-Cookie accepted")
+	}
+	if err := validateCustomHeaders(map[string]string{"X-Campaign": "bad\r\nInjected: 1"}); err == nil {
+		t.Error("CRLF value accepted")
+	}
+}
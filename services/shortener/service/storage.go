@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/ashtonholgate/url-minifier/pkg/auth"
+	"github.com/ashtonholgate/url-minifier/pkg/plans"
+)
+
+// StorageUsage is one org's storage accounting against its plan.
+type StorageUsage struct {
+	OrgID          string `json:"org_id"`
+	Links          int64  `json:"links"`
+	MaxLinks       int64  `json:"max_links"`
+	AnalyticsBytes int64  `json:"analytics_bytes"`
+	MaxAnalytics   int64  `json:"max_analytics_bytes"`
+}
+
+// usageCacheTTL bounds how often the analytics byte aggregation — a full
+// per-org scan — runs for enforcement.
+const usageCacheTTL = 10 * time.Minute
+
+// storageAccounting caches per-org analytics sizes.
+type storageAccounting struct {
+	clicks *mongo.Collection
+
+	mu      sync.Mutex
+	cached  map[string]int64
+	fetched map[string]time.Time
+}
+
+// SetAnalyticsAccounting points storage accounting at the analytics
+// clicks collection.
+func (s *Service) SetAnalyticsAccounting(analyticsDB *mongo.Database) {
+	s.storage = &storageAccounting{
+		clicks:  analyticsDB.Collection("clicks"),
+		cached:  map[string]int64{},
+		fetched: map[string]time.Time{},
+	}
+}
+
+// analyticsBytes returns the org's raw analytics footprint, cached.
+func (a *storageAccounting) analyticsBytes(ctx context.Context, orgID string) (int64, error) {
+	a.mu.Lock()
+	if t, ok := a.fetched[orgID]; ok && time.Since(t) < usageCacheTTL {
+		bytes := a.cached[orgID]
+		a.mu.Unlock()
+		return bytes, nil
+	}
+	a.mu.Unlock()
+
+	cur, err := a.clicks.Aggregate(ctx, bson.A{
+		bson.M{"$match": bson.M{"org_id": orgID}},
+		bson.M{"$group": bson.M{"_id": nil, "bytes": bson.M{"$sum": bson.M{"$bsonSize": "$$ROOT"}}}},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("sizing analytics storage: %w", err)
+	}
+	defer cur.Close(ctx)
+	var bytes int64
+	if cur.Next(ctx) {
+		var row struct {
+			Bytes int64 `bson:"bytes"`
+		}
+		if err := cur.Decode(&row); err != nil {
+			return 0, fmt.Errorf("decoding storage size: %w", err)
+		}
+		bytes = row.Bytes
+	}
+	a.mu.Lock()
+	a.cached[orgID] = bytes
+	a.fetched[orgID] = time.Now()
+	a.mu.Unlock()
+	return bytes, nil
+}
+
+// StorageUsageReport returns the org's storage accounting for the admin
+// summary APIs.
+func (s *Service) StorageUsageReport(ctx context.Context, pr auth.Principal) (*StorageUsage, error) {
+	if err := auth.Authorize(pr, auth.PermAdmin, auth.Resource{OrgID: pr.OrgID}); err != nil {
+		return nil, err
+	}
+	plan, err := s.ent.PlanFor(ctx, pr.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	links, err := s.repo.CountURLsByOrg(ctx, pr.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	usage := &StorageUsage{
+		OrgID:        pr.OrgID,
+		Links:        links,
+		MaxLinks:     plan.MaxLinks,
+		MaxAnalytics: plan.MaxAnalyticsBytes,
+	}
+	if s.storage != nil {
+		if usage.AnalyticsBytes, err = s.storage.analyticsBytes(ctx, pr.OrgID); err != nil {
+			return nil, err
+		}
+	}
+	return usage, nil
+}
+
+// checkStorageQuota enforces the analytics byte cap on the create path,
+// using the cached accounting so the hot path never waits on a scan.
+func (s *Service) checkStorageQuota(ctx context.Context, orgID string, plan plans.Plan) error {
+	if s.storage == nil || plan.MaxAnalyticsBytes <= 0 {
+		return nil
+	}
+	bytes, err := s.storage.analyticsBytes(ctx, orgID)
+	if err != nil {
+		// Accounting trouble must not block creation.
+		log.Printf("storage: sizing org %s: %v", orgID, err)
+		return nil
+	}
+	if bytes >= plan.MaxAnalyticsBytes {
+		return fmt.Errorf("analytics storage quota %d bytes reached on plan %s: %w",
+			plan.MaxAnalyticsBytes, plan.Name, plans.ErrUpgradeRequired)
+	}
+	return nil
+}
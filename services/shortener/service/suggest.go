@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"log"
+)
+
+// EnableTypoSuggestions turns on "did you mean" suggestions for unresolved
+// codes. Off by default: suggestions reveal which nearby codes exist, which
+// public deployments may not want.
+func (s *Service) EnableTypoSuggestions() { s.typoSuggest = true }
+
+// maxTypoSuggestions bounds the "did you mean" list.
+const maxTypoSuggestions = 3
+
+// SuggestSimilarCodes returns existing codes within edit distance one of
+// the missed code. Candidates come from a prefix scan on the code index, so
+// the common case — a typo after the first couple of characters — is one
+// cheap range query. Errors degrade to no suggestions.
+func (s *Service) SuggestSimilarCodes(ctx context.Context, code string) []string {
+	if !s.typoSuggest || len(code) < 3 {
+		return nil
+	}
+	code = s.canonicalCode(code)
+	candidates, err := s.repo.ListCodesByPrefix(ctx, code[:2], 500)
+	if err != nil {
+		log.Printf("suggest: listing candidates: %v", err)
+		return nil
+	}
+	var out []string
+	for _, candidate := range candidates {
+		if candidate != code && editDistanceAtMostOne(code, candidate) {
+			out = append(out, candidate)
+			if len(out) == maxTypoSuggestions {
+				break
+			}
+		}
+	}
+	return out
+}
+
+// editDistanceAtMostOne reports whether a and b differ by at most one
+// substitution, insertion or deletion. Specialised to avoid a full DP table
+// on the hot 404 path.
+func editDistanceAtMostOne(a, b string) bool {
+	la, lb := len(a), len(b)
+	if la > lb {
+		a, b, la, lb = b, a, lb, la
+	}
+	if lb-la > 1 {
+		return false
+	}
+	i, j, diffs := 0, 0, 0
+	for i < la && j < lb {
+		if a[i] == b[j] {
+			i++
+			j++
+			continue
+		}
+		diffs++
+		if diffs > 1 {
+			return false
+		}
+		if la == lb {
+			i++ // substitution
+		}
+		j++ // insertion into a / skip in b
+	}
+	return diffs+(lb-j)+(la-i) <= 1
+}
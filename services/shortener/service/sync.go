@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ashtonholgate/url-minifier/pkg/auth"
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+)
+
+// syncPageSize bounds one delta-sync response.
+const syncPageSize = 200
+
+// Tombstone marks a link deleted since the client's cursor.
+type Tombstone struct {
+	Code      string    `json:"code"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// SyncResult is one page of changes since a cursor. Cursor is the new
+// watermark to store client-side; More signals the client should call
+// again immediately.
+type SyncResult struct {
+	Upserted []*models.URL `json:"upserted"`
+	Deleted  []Tombstone   `json:"deleted"`
+	Cursor   time.Time     `json:"cursor"`
+	More     bool          `json:"more"`
+}
+
+// Sync returns the principal's link changes since the cursor, driven by
+// updated_at for creates/updates and the revision log's delete tombstones,
+// so dashboard clients keep local caches consistent without full
+// re-downloads.
+func (s *Service) Sync(ctx context.Context, pr auth.Principal, cursor time.Time) (*SyncResult, error) {
+	if err := auth.Authorize(pr, auth.PermURLRead, auth.Resource{OwnerID: pr.UserID}); err != nil {
+		return nil, err
+	}
+	if s.revisions == nil {
+		return nil, fmt.Errorf("delta sync requires the revision log: %w", common.ErrInvalidInput)
+	}
+	upserted, err := s.repo.ListURLsUpdatedSince(ctx, pr.UserID, cursor, syncPageSize)
+	if err != nil {
+		return nil, err
+	}
+	deletions, err := s.revisions.ListDeletionsSince(ctx, pr.UserID, cursor, syncPageSize)
+	if err != nil {
+		return nil, err
+	}
+	res := &SyncResult{Upserted: upserted, Cursor: cursor}
+	for _, u := range upserted {
+		if u.UpdatedAt.After(res.Cursor) {
+			res.Cursor = u.UpdatedAt
+		}
+	}
+	for _, rev := range deletions {
+		res.Deleted = append(res.Deleted, Tombstone{Code: rev.Code, DeletedAt: rev.ChangedAt})
+		if rev.ChangedAt.After(res.Cursor) {
+			res.Cursor = rev.ChangedAt
+		}
+	}
+	res.More = len(upserted) == syncPageSize || len(deletions) == syncPageSize
+	return res, nil
+}
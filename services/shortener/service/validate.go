@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ashtonholgate/url-minifier/pkg/auth"
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/plans"
+)
+
+// ValidationResult reports what CreateURL would have done without
+// persisting anything. Problems is empty when Valid is true.
+type ValidationResult struct {
+	Valid    bool     `json:"valid"`
+	Problems []string `json:"problems,omitempty"`
+	// AliasAvailable is set only when the request named a custom alias.
+	AliasAvailable *bool `json:"alias_available,omitempty"`
+}
+
+// ValidateURLRequest runs the full CreateURL validation path — input checks,
+// entitlements and alias availability — without writing, so UIs can show
+// live feedback as the user types.
+func (s *Service) ValidateURLRequest(ctx context.Context, pr auth.Principal, req CreateURLRequest) (*ValidationResult, error) {
+	if err := auth.Authorize(pr, auth.PermURLCreate, auth.Resource{}); err != nil {
+		return nil, err
+	}
+	res := &ValidationResult{Valid: true}
+	fail := func(msg string) {
+		res.Valid = false
+		res.Problems = append(res.Problems, msg)
+	}
+	if err := req.validate(); err != nil {
+		fail(err.Error())
+	} else if err := s.policies.Evaluate(ctx, pr.OrgID, req.LongURL); err != nil {
+		fail(err.Error())
+	}
+	if req.Alias != "" {
+		if err := s.ent.Check(ctx, pr.OrgID, plans.FeatureCustomAlias); err != nil {
+			fail(err.Error())
+		}
+		alias := req.Alias
+		if canonical, err := s.ValidateCustomAlias(req.Alias); err != nil {
+			fail(err.Error())
+		} else {
+			alias = canonical
+		}
+		available, err := s.aliasAvailable(ctx, s.canonicalCode(alias))
+		if err != nil {
+			return nil, err
+		}
+		res.AliasAvailable = &available
+		if !available {
+			fail("alias already taken")
+		}
+	}
+	return res, nil
+}
+
+// aliasAvailable reports whether the code is free to claim.
+func (s *Service) aliasAvailable(ctx context.Context, code string) (bool, error) {
+	_, err := s.repo.GetURLByCode(ctx, code)
+	if common.IsNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return false, nil
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ashtonholgate/url-minifier/pkg/auth"
+	"github.com/ashtonholgate/url-minifier/pkg/common"
+	"github.com/ashtonholgate/url-minifier/pkg/models"
+	"github.com/ashtonholgate/url-minifier/services/shortener/repository"
+)
+
+// SetWorkspaceRepository attaches workspace storage to the service.
+func (s *Service) SetWorkspaceRepository(workspaces *repository.WorkspaceRepository) {
+	s.workspaces = workspaces
+}
+
+// CreateWorkspace creates a workspace with the principal as its first
+// owner-role member.
+func (s *Service) CreateWorkspace(ctx context.Context, pr auth.Principal, name, defaultDomain string) (*models.Workspace, error) {
+	if err := auth.Authorize(pr, auth.PermURLCreate, auth.Resource{}); err != nil {
+		return nil, err
+	}
+	if name == "" {
+		return nil, fmt.Errorf("workspace name is required: %w", common.ErrInvalidInput)
+	}
+	ws := &models.Workspace{
+		Name:          name,
+		OrgID:         pr.OrgID,
+		DefaultDomain: defaultDomain,
+		Members:       []models.WorkspaceMember{{UserID: pr.UserID, Role: string(auth.RoleOwner)}},
+	}
+	if err := s.workspaces.StoreWorkspace(ctx, ws); err != nil {
+		return nil, err
+	}
+	return ws, nil
+}
+
+// ListWorkspaces returns the principal's workspaces.
+func (s *Service) ListWorkspaces(ctx context.Context, pr auth.Principal) ([]*models.Workspace, error) {
+	return s.workspaces.ListWorkspacesForUser(ctx, pr.UserID)
+}
+
+// workspaceAccess loads the workspace and authorizes the principal for the
+// permission via their membership role. Org-admins and superadmins pass
+// without membership.
+func (s *Service) workspaceAccess(ctx context.Context, pr auth.Principal, id string, perm auth.Permission) (*models.Workspace, error) {
+	ws, err := s.workspaces.GetWorkspace(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	memberRole := auth.Role(ws.MemberRole(pr.UserID))
+	if memberRole.Valid() && memberRole.Can(perm) {
+		return ws, nil
+	}
+	if err := auth.Authorize(pr, auth.PermAdmin, auth.Resource{OrgID: ws.OrgID}); err != nil {
+		return nil, fmt.Errorf("workspace %s: %w", id, auth.ErrForbidden)
+	}
+	return ws, nil
+}
+
+// SetWorkspaceMember adds or updates a membership; requires delete-level
+// (owner) access to the workspace.
+func (s *Service) SetWorkspaceMember(ctx context.Context, pr auth.Principal, id string, m models.WorkspaceMember) error {
+	if !auth.Role(m.Role).Valid() {
+		return fmt.Errorf("unknown role %q: %w", m.Role, common.ErrInvalidInput)
+	}
+	if _, err := s.workspaceAccess(ctx, pr, id, auth.PermURLDelete); err != nil {
+		return err
+	}
+	return s.workspaces.SetMember(ctx, id, m)
+}
+
+// RemoveWorkspaceMember drops a membership; members may remove themselves,
+// otherwise owner access is required.
+func (s *Service) RemoveWorkspaceMember(ctx context.Context, pr auth.Principal, id, userID string) error {
+	if pr.UserID != userID {
+		if _, err := s.workspaceAccess(ctx, pr, id, auth.PermURLDelete); err != nil {
+			return err
+		}
+	}
+	return s.workspaces.RemoveMember(ctx, id, userID)
+}
+
+// ListWorkspaceURLs returns a page of a workspace's shared links for any
+// member.
+func (s *Service) ListWorkspaceURLs(ctx context.Context, pr auth.Principal, id string, limit, offset int64) ([]*models.URL, error) {
+	if _, err := s.workspaceAccess(ctx, pr, id, auth.PermURLRead); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return s.workspaces.ListURLsByWorkspace(ctx, id, limit, offset)
+}
+
+// checkWorkspace verifies the principal may create links in the workspace.
+func (s *Service) checkWorkspace(ctx context.Context, pr auth.Principal, id string) error {
+	if id == "" || s.workspaces == nil {
+		return nil
+	}
+	_, err := s.workspaceAccess(ctx, pr, id, auth.PermURLCreate)
+	return err
+}